@@ -17,6 +17,7 @@ import (
 	"github.com/maplelabs/opensearch-scaling-manager/logger"
 	osutils "github.com/maplelabs/opensearch-scaling-manager/opensearchUtils"
 	"strconv"
+	"strings"
 )
 
 var log logger.LOG
@@ -57,6 +58,24 @@ type Node struct {
 	NumShards int
 	// Number of shards per GB
 	ShardsPerGB float64
+	// SearchLatency indicates the average time in milliseconds taken by a search query on the node since the last poll.
+	SearchLatency float32
+	// IndexingLatency indicates the average time in milliseconds taken to index a document on the node since the last poll.
+	IndexingLatency float32
+	// IndexingRate indicates the number of documents indexed per second on the node since the last poll.
+	IndexingRate float32
+	// SearchQueue indicates the number of tasks in the queue of the search thread pool.
+	SearchQueue int
+	// WriteQueue indicates the number of tasks in the queue of the write thread pool.
+	WriteQueue int
+	// SearchRejections indicates the number of tasks rejected by the search thread pool since the last poll.
+	SearchRejections int
+	// WriteRejections indicates the number of tasks rejected by the write thread pool since the last poll.
+	WriteRejections int
+	// GcTime indicates the percentage of time spent in garbage collection since the last poll.
+	GcTime float32
+	// LoadAvg indicates the one minute load average of the node.
+	LoadAvg float32
 }
 
 // This struct will contain the static metrics of the cluster.
@@ -64,7 +83,7 @@ type ClusterStatic struct {
 	// ClusterName indicates the Cluster name for the OpenSearch cluster.
 	ClusterName string `yaml:"cluster_name" validate:"required,isValidName" json:"cluster_name"`
 	// CloudType indicate the type of the cloud service where the OpenSearch cluster is deployed.
-	CloudType string `yaml:"cloud_type" validate:"required,oneof=AWS GCP AZURE" json:"cloud_type"`
+	CloudType string `yaml:"cloud_type" validate:"required,oneof=AWS GCP AZURE LOCAL" json:"cloud_type"`
 	// NumMaxNodesAllowed indicates the number of maximum allowed node present in the cluster.
 	// Based on this value we will determine whether to scale out further or not.
 	MaxNodesAllowed int `yaml:"max_nodes_allowed" validate:"required,min=1" json:"max_nodes_allowed"`
@@ -120,6 +139,16 @@ type MetricStats struct {
 	Min float32
 	// Max indicates the maximum value for a metric for a time period.
 	Max float32
+	// P90 indicates the 90th percentile of a metric for a time period.
+	P90 float32
+	// P95 indicates the 95th percentile of a metric for a time period.
+	P95 float32
+	// P99 indicates the 99th percentile of a metric for a time period.
+	P99 float32
+	// Rate indicates the change of a metric per minute for a time period, which is the slope of the least squares line.
+	Rate float32
+	// Count indicates the number of data points from which the statistics are calculated.
+	Count int
 }
 
 // This struct contains statistics for a metric on a node for an evaluation period.
@@ -201,14 +230,16 @@ func dataPointsQuery(decisionPeriod int, pollingInterval int) string {
 // Input:
 //              metricName (string): The metric for which the average is needed.
 //              decisionPeriod (int): Time in minutes used to specify the time range for collecting data from Opensearch.
+//              pollingInterval (int): Time in seconds which is the interval between each metric is pushed into the index
 //
 // Description:
-//              Generates the query string for determining the average of the metric specified.
+//              Generates the query string for determining the statistics, percentiles and the average of the metric
+//              for every polling interval which is used to calculate the rate of change of the metric.
 //
 // Return:
 //              (string): Returns the query string that can be given as an OS query api parameter.
 
-func getClusterAvgQuery(metricName string, decisionPeriod int) string {
+func getClusterAvgQuery(metricName string, decisionPeriod int, pollingInterval int) string {
 	clusterAvgQueryString := `{
           "query": {
             "bool": {
@@ -229,6 +260,26 @@ func getClusterAvgQuery(metricName string, decisionPeriod int) string {
               "stats": {
                 "field": "` + metricName + `"
               }
+            },
+            "` + metricName + `_percentiles": {
+              "percentiles": {
+                "field": "` + metricName + `",
+                "percents": [90, 95, 99]
+              }
+            },
+            "` + metricName + `_series": {
+              "date_histogram": {
+                "field": "Timestamp",
+                "fixed_interval": "` + strconv.Itoa(pollingInterval) + `s",
+                "min_doc_count": 1
+              },
+              "aggs": {
+                "` + metricName + `": {
+                  "avg": {
+                    "field": "` + metricName + `"
+                  }
+                }
+              }
             }
           }
         }`
@@ -400,7 +451,7 @@ func GetClusterAvg(ctx context.Context, metricName string, decisionPeriod int, p
 	}
 
 	//Get the query and convert to json
-	var jsonQuery = []byte(getClusterAvgQuery(metricName, decisionPeriod))
+	var jsonQuery = []byte(getClusterAvgQuery(metricName, decisionPeriod, pollingInterval))
 
 	//create a search request and pass the query
 	searchResp, err := osutils.SearchQuery(ctx, jsonQuery)
@@ -420,27 +471,124 @@ func GetClusterAvg(ctx context.Context, metricName string, decisionPeriod int, p
 	}
 
 	//Parse the interface and populate the metricStatsCluster
-	avg := queryResultInterface["aggregations"].(map[string]interface{})[metricName].(map[string]interface{})["avg"]
+	aggregations, ok := queryResultInterface["aggregations"].(map[string]interface{})
+	if !ok {
+		return metricStats, invalidDatapoints, fmt.Errorf("no aggregations in the response: %v", queryResultInterface)
+	}
+	statsAgg, ok := aggregations[metricName].(map[string]interface{})
+	if !ok {
+		return metricStats, invalidDatapoints, fmt.Errorf("no %s aggregation in the response", metricName)
+	}
+	avg := statsAgg["avg"]
 	if avg != nil {
 		metricStats.Avg = float32(avg.(float64))
 	} else {
 		log.Warn.Println(metricName, " average is nil!")
 	}
-	max := queryResultInterface["aggregations"].(map[string]interface{})[metricName].(map[string]interface{})["max"]
+	max := statsAgg["max"]
 	if max != nil {
 		metricStats.Max = float32(max.(float64))
 	} else {
 		log.Warn.Println(metricName, " max is nil!")
 	}
-	min := queryResultInterface["aggregations"].(map[string]interface{})[metricName].(map[string]interface{})["min"]
+	min := statsAgg["min"]
 	if min != nil {
 		metricStats.Min = float32(min.(float64))
 	} else {
 		log.Warn.Println(metricName, " min is nil!")
 	}
+	if count, ok := statsAgg["count"].(float64); ok {
+		metricStats.Count = int(count)
+	}
+	// The percentiles and the series are needed by the P90, P95, P99, TREND and RATE rules, which would be met or not on a zero value
+	percentilesAgg, ok := aggregations[metricName+"_percentiles"].(map[string]interface{})
+	if !ok {
+		return metricStats, invalidDatapoints, fmt.Errorf("no %s_percentiles aggregation in the response", metricName)
+	}
+	parsePercentilesAgg(percentilesAgg, &metricStats)
+	seriesBuckets, err := getAggBuckets(aggregations, metricName+"_series")
+	if err != nil {
+		return metricStats, invalidDatapoints, err
+	}
+	var dataPoints []MetricDataPoint
+	for _, bucket := range seriesBuckets {
+		bucketMap, _ := bucket.(map[string]interface{})
+		timestamp, ok := bucketMap["key"].(float64)
+		if !ok {
+			return metricStats, invalidDatapoints, fmt.Errorf("invalid %s_series bucket in the response: %v", metricName, bucket)
+		}
+		avgAgg, _ := bucketMap[metricName].(map[string]interface{})
+		if value, ok := avgAgg["value"].(float64); ok {
+			dataPoints = append(dataPoints, MetricDataPoint{
+				Timestamp: int64(timestamp),
+				Value:     float32(value),
+			})
+		}
+	}
+	metricStats.Rate = getRate(dataPoints)
 	return metricStats, invalidDatapoints, nil
 }
 
+// Input:
+//              percentilesAgg (map[string]interface{}): The percentiles aggregation of a metric from the search response
+//              metricStats (*MetricStats): The statistics in which the percentiles are populated
+//
+// Description:
+//              Parses the 90th, 95th and 99th percentiles from the percentiles aggregation.
+//              The percentiles are left as zero if there were no documents.
+//
+// Return:
+
+func parsePercentilesAgg(percentilesAgg map[string]interface{}, metricStats *MetricStats) {
+	percentiles, ok := percentilesAgg["values"].(map[string]interface{})
+	if !ok {
+		log.Warn.Println("Percentiles are nil!")
+		return
+	}
+	if p90, ok := percentiles["90.0"].(float64); ok {
+		metricStats.P90 = float32(p90)
+	}
+	if p95, ok := percentiles["95.0"].(float64); ok {
+		metricStats.P95 = float32(p95)
+	}
+	if p99, ok := percentiles["99.0"].(float64); ok {
+		metricStats.P99 = float32(p99)
+	}
+}
+
+// Input:
+//              dataPoints ([]MetricDataPoint): The values of a metric sorted by time
+//
+// Description:
+//              Fits a line over the data points using least squares and returns its slope as the change of the metric per minute.
+//              Returns zero if there are less than two data points.
+//
+// Return:
+//              (float32): Return the change of the metric per minute.
+
+func getRate(dataPoints []MetricDataPoint) float32 {
+	n := float64(len(dataPoints))
+	if n < 2 {
+		return 0
+	}
+	// Use the time in minutes relative to the first data point to keep the sums small
+	origin := dataPoints[0].Timestamp
+	var sumX, sumY, sumXY, sumXX float64
+	for _, dataPoint := range dataPoints {
+		x := float64(dataPoint.Timestamp-origin) / 60000
+		y := float64(dataPoint.Value)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return float32((n*sumXY - sumX*sumY) / denominator)
+}
+
 // Input:
 //              metricName (string): The metric for which the count is needed.
 //              decisionPeriod (int): Time in minutes used to specify the time range for collecting data from Opensearch.
@@ -574,6 +722,257 @@ func GetClusterCount(ctx context.Context, metricName string, decisionPeriod int,
 	return metricViolatedCount, invalidDatapoints, nil
 }
 
+// Input:
+//              ctx (context.Context): Request-scoped data that transits processes and APIs.
+//              decisionPeriod (int): Time in minutes used to specify the time range for collecting data from Opensearch.
+//              pollingInterval (int): Time in seconds which is the interval between each metric is pushed into the index
+//
+// Description:
+//              Checks if there are data points from the start of the decision period, so that the statistics cover the whole decision period.
+//
+// Return:
+//              (bool, error): Return true if there are not enough data points and error if any.
+
+func checkInvalidDatapoints(ctx context.Context, decisionPeriod int, pollingInterval int) (bool, error) {
+	dataPointsResp, err := osutils.SearchQuery(ctx, []byte(dataPointsQuery(decisionPeriod, pollingInterval)))
+	if err != nil {
+		log.Error.Println("Can't query for data points!", err)
+		return false, err
+	}
+	defer dataPointsResp.Body.Close()
+
+	var dpRespInterface map[string]interface{}
+	decodeErr := json.NewDecoder(dataPointsResp.Body).Decode(&dpRespInterface)
+	if decodeErr != nil {
+		log.Error.Println("decode Error: ", decodeErr)
+		return false, decodeErr
+	}
+	hits, _ := dpRespInterface["hits"].(map[string]interface{})
+	total, _ := hits["total"].(map[string]interface{})
+	value, ok := total["value"].(float64)
+	if !ok {
+		return false, fmt.Errorf("no total hits in the response: %v", dpRespInterface)
+	}
+	return value == 0, nil
+}
+
+// Input:
+//              decisionPeriod (int): Time in minutes used to specify the time range for collecting data from Opensearch.
+//              dataNodesOnly (bool): Whether only the documents of the data nodes need to be considered.
+//              nodeAggs (string): The aggregations calculated for every node.
+//
+// Description:
+//              Generates the query string which runs the aggregations for every node using a terms aggregation over the NodeId.
+//              The HostIp of every node is collected using a terms aggregation inside the node bucket.
+//
+// Return:
+//              (string): Returns the query string that can be given as an OS query api parameter.
+
+func getNodeAggsQuery(decisionPeriod int, dataNodesOnly bool, nodeAggs string) string {
+	dataNodesFilter := ""
+	if dataNodesOnly {
+		dataNodesFilter = `,
+                {
+                  "term": {
+                    "IsData": true
+                  }
+                }`
+	}
+	nodeAggsQueryString := `{
+          "size": 0,
+          "query": {
+            "bool": {
+              "filter": {
+                "range": {
+                  "Timestamp": {
+                    "gte": "now-` + strconv.Itoa(decisionPeriod) + `m",
+                    "include_lower": true,
+                    "include_upper": true,
+                    "to": null
+                  }
+                }
+              },
+              "must": [
+                {
+                  "match": {
+                    "StatTag": "NodeStatistics"
+                  }
+                }` + dataNodesFilter + `
+              ]
+            }
+          },
+          "aggs": {
+            "nodes": {
+              "terms": {
+                "field": "NodeId.keyword",
+                "size": 1000
+              },
+              "aggs": {
+                "host_ip": {
+                  "terms": {
+                    "field": "HostIp.keyword",
+                    "size": 1
+                  }
+                },
+                ` + nodeAggs + `
+              }
+            }
+          }
+        }`
+	return nodeAggsQueryString
+}
+
+// Input:
+//              ctx (context.Context): Request-scoped data that transits processes and APIs.
+//              nodeAggsQuery (string): The query generated by getNodeAggsQuery.
+//
+// Description:
+//              Runs the query and returns the node buckets along with the HostIp of every node.
+//
+// Return:
+//              ([]map[string]interface{}, []string, error): Return the node buckets, the HostIp of every node and error if any.
+
+func getNodeBuckets(ctx context.Context, nodeAggsQuery string) ([]map[string]interface{}, []string, error) {
+	var nodeBuckets []map[string]interface{}
+	var hostIps []string
+
+	searchResp, err := osutils.SearchQuery(ctx, []byte(nodeAggsQuery))
+	if err != nil {
+		log.Error.Println("Cannot fetch node level statistics: ", err)
+		return nodeBuckets, hostIps, err
+	}
+	defer searchResp.Body.Close()
+
+	var queryResultInterface map[string]interface{}
+	decodeErr := json.NewDecoder(searchResp.Body).Decode(&queryResultInterface)
+	if decodeErr != nil {
+		log.Error.Println("decode Error: ", decodeErr)
+		return nodeBuckets, hostIps, decodeErr
+	}
+	aggregations, ok := queryResultInterface["aggregations"].(map[string]interface{})
+	if !ok {
+		return nodeBuckets, hostIps, fmt.Errorf("no aggregations in the response: %v", queryResultInterface)
+	}
+
+	buckets, err := getAggBuckets(aggregations, "nodes")
+	if err != nil {
+		return nodeBuckets, hostIps, err
+	}
+	for _, nodeBucket := range buckets {
+		nodeBucketMap, ok := nodeBucket.(map[string]interface{})
+		if !ok {
+			return nodeBuckets, hostIps, fmt.Errorf("invalid node bucket in the response: %v", nodeBucket)
+		}
+		hostIpBuckets, err := getAggBuckets(nodeBucketMap, "host_ip")
+		if err != nil {
+			return nodeBuckets, hostIps, err
+		}
+		var hostIp string
+		if len(hostIpBuckets) > 0 {
+			hostIpBucket, _ := hostIpBuckets[0].(map[string]interface{})
+			hostIp, _ = hostIpBucket["key"].(string)
+		}
+		nodeBuckets = append(nodeBuckets, nodeBucketMap)
+		hostIps = append(hostIps, hostIp)
+	}
+	return nodeBuckets, hostIps, nil
+}
+
+// Input:
+//              ctx (context.Context): Request-scoped data that transits processes and APIs.
+//              metricName (string): The metric name for which the statistics will be calculated
+//              decisionPeriod (int): The evaluation time over which the statistics will be computed
+//              pollingInterval (int): Time in seconds which is the interval between each metric is pushed into the index
+//              dataNodesOnly (bool): Whether only the data nodes need to be considered.
+//
+// Description:
+//              GetNodeStats calculates the statistics and percentiles of the metric for every node, so that a single node
+//              crossing the limit is not diluted by the other nodes of the cluster.
+//
+// Return:
+//              ([]MetricStatsNode, bool, error): Return the statistics of every node, a (bool) value indicating whether there were not enough data points and error if any.
+
+func GetNodeStats(ctx context.Context, metricName string, decisionPeriod int, pollingInterval int, dataNodesOnly bool) ([]MetricStatsNode, bool, error) {
+	var metricStatsNodes []MetricStatsNode
+
+	invalidDatapoints, err := checkInvalidDatapoints(ctx, decisionPeriod, pollingInterval)
+	if err != nil || invalidDatapoints {
+		return metricStatsNodes, invalidDatapoints, err
+	}
+
+	nodeAggs := `"` + metricName + `": {"stats": {"field": "` + metricName + `"}},
+                "` + metricName + `_percentiles": {"percentiles": {"field": "` + metricName + `", "percents": [90, 95, 99]}}`
+	nodeBuckets, hostIps, err := getNodeBuckets(ctx, getNodeAggsQuery(decisionPeriod, dataNodesOnly, nodeAggs))
+	if err != nil {
+		return metricStatsNodes, invalidDatapoints, err
+	}
+	for i, nodeBucket := range nodeBuckets {
+		statsAgg, statsOk := nodeBucket[metricName].(map[string]interface{})
+		percentilesAgg, percentilesOk := nodeBucket[metricName+"_percentiles"].(map[string]interface{})
+		if !statsOk || !percentilesOk {
+			return metricStatsNodes, invalidDatapoints, fmt.Errorf("no %s statistics for the node %s in the response", metricName, hostIps[i])
+		}
+		metricStats := parseStatsAgg(statsAgg)
+		parsePercentilesAgg(percentilesAgg, &metricStats)
+		metricStatsNodes = append(metricStatsNodes, MetricStatsNode{
+			MetricStats: metricStats,
+			HostIp:      hostIps[i],
+		})
+	}
+	return metricStatsNodes, invalidDatapoints, nil
+}
+
+// Input:
+//              ctx (context.Context): Request-scoped data that transits processes and APIs.
+//              metricName (string): The name of the metric that will be used to compute the number of times the limit is reached.
+//              decisionPeriod (int): The evaluation period for which the Count will be determined.
+//              pollingInterval (int): Time in seconds which is the interval between each metric is pushed into the index
+//              limit (float32): The limit for the metric for which the count is calculated.
+//              taskOperation (string); Recommended operation
+//              dataNodesOnly (bool): Whether only the data nodes need to be considered.
+//
+// Description:
+//              GetNodeCount returns the number of times the metric has reached the limit on every node.
+//              For scale_down the count is the number of times the metric was below the limit.
+//
+// Return:
+//              ([]MetricViolatedCountNode, bool, error): Return the count of every node, a (bool) value indicating whether there were not enough data points and error if any.
+
+func GetNodeCount(ctx context.Context, metricName string, decisionPeriod int, pollingInterval int, limit float32, taskOperation string, dataNodesOnly bool) ([]MetricViolatedCountNode, bool, error) {
+	var metricViolatedCountNodes []MetricViolatedCountNode
+
+	invalidDatapoints, err := checkInvalidDatapoints(ctx, decisionPeriod, pollingInterval)
+	if err != nil || invalidDatapoints {
+		return metricViolatedCountNodes, invalidDatapoints, err
+	}
+
+	operator := "gte"
+	if taskOperation != "scale_up" {
+		operator = "lt"
+	}
+	nodeAggs := `"violated": {"filter": {"range": {"` + metricName + `": {"` + operator + `": ` + fmt.Sprintf("%f", limit) + `}}}}`
+	nodeBuckets, hostIps, err := getNodeBuckets(ctx, getNodeAggsQuery(decisionPeriod, dataNodesOnly, nodeAggs))
+	if err != nil {
+		return metricViolatedCountNodes, invalidDatapoints, err
+	}
+	for i, nodeBucket := range nodeBuckets {
+		violatedAgg, _ := nodeBucket["violated"].(map[string]interface{})
+		violatedCount, violatedOk := violatedAgg["doc_count"].(float64)
+		totalCount, totalOk := nodeBucket["doc_count"].(float64)
+		if !violatedOk || !totalOk {
+			return metricViolatedCountNodes, invalidDatapoints, fmt.Errorf("no %s count for the node %s in the response", metricName, hostIps[i])
+		}
+		metricViolatedCountNodes = append(metricViolatedCountNodes, MetricViolatedCountNode{
+			MetricViolatedCount: MetricViolatedCount{
+				ViolatedCount: int(violatedCount),
+				TotalCount:    int(totalCount),
+			},
+			HostIp: hostIps[i],
+		})
+	}
+	return metricViolatedCountNodes, invalidDatapoints, nil
+}
+
 // Input:
 //
 // Description:
@@ -632,7 +1031,124 @@ func GetClusterCurrent(waitForShards bool) (ClusterDynamic, bool) {
 	return clusterStats, clusterHealthInterface["timed_out"].(bool)
 }
 
+// A list of the metrics present in the NodeStatistics documents which can be used in the rules
+// and for which the historic statistics are calculated.
+var HistoricMetrics = []string{"CpuUtil", "RamUtil", "HeapUtil", "DiskUtil", "NumShards", "ShardsPerGB",
+	"SearchLatency", "IndexingLatency", "IndexingRate", "SearchQueue", "WriteQueue",
+	"SearchRejections", "WriteRejections", "GcTime", "LoadAvg"}
+
+// This struct contains the cluster level value of a metric for an interval of time.
+type MetricDataPoint struct {
+	// Timestamp indicates the start of the interval in milliseconds.
+	Timestamp int64
+	// Value indicates the average of the metric over all the nodes for the interval.
+	Value float32
+}
+
+// Input:
+//              decisionPeriod (int): Time in minutes used to specify the time range for collecting data from Opensearch.
+//
+// Description:
+//              Generates the query string for determining the cluster and node level statistics of all the historic metrics.
+//              The node level statistics are calculated using a terms aggregation over the HostIp of the NodeStatistics documents.
+//
+// Return:
+//              (string): Returns the query string that can be given as an OS query api parameter.
+
+func getClusterHistoricAvgQuery(decisionPeriod int) string {
+	var statsAggs []string
+	for _, metricName := range HistoricMetrics {
+		statsAggs = append(statsAggs, `"`+metricName+`": {"stats": {"field": "`+metricName+`"}}`)
+	}
+	clusterHistoricAvgQueryString := `{
+          "size": 0,
+          "query": {
+            "bool": {
+              "filter": {
+                "range": {
+                  "Timestamp": {
+                    "gte": "now-` + strconv.Itoa(decisionPeriod) + `m",
+                    "include_lower": true,
+                    "include_upper": true,
+                    "to": null
+                  }
+                }
+              },
+              "must": [
+                {
+                  "match": {
+                    "StatTag": "NodeStatistics"
+                  }
+                }
+              ]
+            }
+          },
+          "aggs": {
+            ` + strings.Join(statsAggs, ",\n            ") + `,
+            "nodes": {
+              "terms": {
+                "field": "HostIp.keyword",
+                "size": 1000
+              },
+              "aggs": {
+                ` + strings.Join(statsAggs, ",\n                ") + `
+              }
+            }
+          }
+        }`
+	return clusterHistoricAvgQueryString
+}
+
+// Input:
+//              statsAgg (map[string]interface{}): The stats aggregation of a metric from the search response
+//
+// Description:
+//              Parses the stats aggregation and populates the MetricStats struct. The values are left as zero if there were no documents.
+//
+// Return:
+//              (MetricStats): Return populated MetricStats struct.
+
+func parseStatsAgg(statsAgg map[string]interface{}) MetricStats {
+	var metricStats MetricStats
+	if avg, ok := statsAgg["avg"].(float64); ok {
+		metricStats.Avg = float32(avg)
+	}
+	if max, ok := statsAgg["max"].(float64); ok {
+		metricStats.Max = float32(max)
+	}
+	if min, ok := statsAgg["min"].(float64); ok {
+		metricStats.Min = float32(min)
+	}
+	if count, ok := statsAgg["count"].(float64); ok {
+		metricStats.Count = int(count)
+	}
+	return metricStats
+}
+
 // Input:
+//              aggs (map[string]interface{}): The aggregations of the search response or of a bucket
+//              name (string): The name of the bucket aggregation, Ex: a terms or date_histogram aggregation
+//
+// Description:
+//              Returns the buckets of the aggregation, checking the type of every level of the response.
+//
+// Return:
+//              ([]interface{}, error): Return the buckets and error if the aggregation is missing or malformed.
+
+func getAggBuckets(aggs map[string]interface{}, name string) ([]interface{}, error) {
+	agg, ok := aggs[name].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("no %s aggregation in the response", name)
+	}
+	buckets, ok := agg["buckets"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("no buckets in the %s aggregation of the response", name)
+	}
+	return buckets, nil
+}
+
+// Input:
+//              ctx (context.Context): Request-scoped data that transits processes and APIs.
 //              decisionPeriod(int): The evaluation period for which the Average will be calculated.(int)
 //
 // Description:
@@ -641,14 +1157,117 @@ func GetClusterCurrent(waitForShards bool) (ClusterDynamic, bool) {
 //              Historic average for the mentioned decision period.
 //
 // Return:
-//              ([]MetricStatsCluster): Return an array of populated MetricStatsCluster struct collected for all the metrics.
+//              ([]MetricStatsCluster, error): Return an array of populated MetricStatsCluster struct collected for all the metrics and error if any.
 
-func GetClusterHistoricAvg(decisonPeriod int) []MetricStatsCluster {
+func GetClusterHistoricAvg(ctx context.Context, decisionPeriod int) ([]MetricStatsCluster, error) {
 	var metricStatsCluster []MetricStatsCluster
-	return metricStatsCluster
+
+	searchResp, err := osutils.SearchQuery(ctx, []byte(getClusterHistoricAvgQuery(decisionPeriod)))
+	if err != nil {
+		log.Error.Println("Cannot fetch cluster historic average: ", err)
+		return metricStatsCluster, err
+	}
+	defer searchResp.Body.Close()
+
+	var queryResultInterface map[string]interface{}
+	decodeErr := json.NewDecoder(searchResp.Body).Decode(&queryResultInterface)
+	if decodeErr != nil {
+		log.Error.Println("decode Error: ", decodeErr)
+		return metricStatsCluster, decodeErr
+	}
+	aggregations, ok := queryResultInterface["aggregations"].(map[string]interface{})
+	if !ok {
+		return metricStatsCluster, fmt.Errorf("no aggregations in the response: %v", queryResultInterface)
+	}
+
+	nodeBuckets, err := getAggBuckets(aggregations, "nodes")
+	if err != nil {
+		return metricStatsCluster, err
+	}
+	for _, metricName := range HistoricMetrics {
+		statsAgg, ok := aggregations[metricName].(map[string]interface{})
+		if !ok {
+			return metricStatsCluster, fmt.Errorf("no %s aggregation in the response", metricName)
+		}
+		metricStats := MetricStatsCluster{
+			MetricName:   metricName,
+			ClusterLevel: parseStatsAgg(statsAgg),
+		}
+		for _, nodeBucket := range nodeBuckets {
+			nodeBucketMap, _ := nodeBucket.(map[string]interface{})
+			hostIp, hostIpOk := nodeBucketMap["key"].(string)
+			nodeStatsAgg, statsOk := nodeBucketMap[metricName].(map[string]interface{})
+			if !hostIpOk || !statsOk {
+				return metricStatsCluster, fmt.Errorf("invalid %s node bucket in the response: %v", metricName, nodeBucket)
+			}
+			metricStats.NodeLevel = append(metricStats.NodeLevel, MetricStatsNode{
+				MetricStats: parseStatsAgg(nodeStatsAgg),
+				HostIp:      hostIp,
+			})
+		}
+		metricStatsCluster = append(metricStatsCluster, metricStats)
+	}
+	return metricStatsCluster, nil
+}
+
+// Input:
+//              decisionPeriod (int): Time in minutes used to specify the time range for collecting data from Opensearch.
+//              thresholdMap (map[string]int): The map of metric name and the threshold for which the Count is calculated.
+//
+// Description:
+//              Generates the query string for determining the number of documents in which every metric crossed its threshold,
+//              both for the cluster and for every node (terms aggregation over the HostIp).
+//
+// Return:
+//              (string): Returns the query string that can be given as an OS query api parameter.
+
+func getClusterHistoricCountQuery(decisionPeriod int, thresholdMap map[string]int) string {
+	var countAggs []string
+	for metricName, threshold := range thresholdMap {
+		countAggs = append(countAggs, `"`+metricName+`": {"filter": {"range": {"`+metricName+`": {"gte": `+strconv.Itoa(threshold)+`}}}}`)
+	}
+	countAggs = append(countAggs, `"docs_count": {"value_count": {"field": "Timestamp"}}`)
+	clusterHistoricCountQueryString := `{
+          "size": 0,
+          "query": {
+            "bool": {
+              "filter": {
+                "range": {
+                  "Timestamp": {
+                    "gte": "now-` + strconv.Itoa(decisionPeriod) + `m",
+                    "include_lower": true,
+                    "include_upper": true,
+                    "to": null
+                  }
+                }
+              },
+              "must": [
+                {
+                  "match": {
+                    "StatTag": "NodeStatistics"
+                  }
+                }
+              ]
+            }
+          },
+          "aggs": {
+            ` + strings.Join(countAggs, ",\n            ") + `,
+            "nodes": {
+              "terms": {
+                "field": "HostIp.keyword",
+                "size": 1000
+              },
+              "aggs": {
+                ` + strings.Join(countAggs, ",\n                ") + `
+              }
+            }
+          }
+        }`
+	return clusterHistoricCountQueryString
 }
 
 // Input:
+//              ctx (context.Context): Request-scoped data that transits processes and APIs.
 //              decisionPeriod(int): The evaluation period for which the Average will be calculated.
 //              thresholdMap( map[string]int): The map provide mapping of metric name and the threshold for which the Count is calculated.
 //
@@ -658,9 +1277,191 @@ func GetClusterHistoricAvg(decisonPeriod int) []MetricStatsCluster {
 //              It will return the array of node level and cluster level count been voilated for all the metrics.
 //
 // Return:
-//              ([]MetricViolatedCountCluster): Return array of populated MetricViolatedCountCluster struct.
+//              ([]MetricViolatedCountCluster, error): Return array of populated MetricViolatedCountCluster struct and error if any.
 
-func GetClusterHistoricCount(decisionPeriod int, thresholdMap map[string]int) []MetricViolatedCountCluster {
+func GetClusterHistoricCount(ctx context.Context, decisionPeriod int, thresholdMap map[string]int) ([]MetricViolatedCountCluster, error) {
 	var metricViolatedCount []MetricViolatedCountCluster
-	return metricViolatedCount
+	if len(thresholdMap) == 0 {
+		return metricViolatedCount, nil
+	}
+
+	searchResp, err := osutils.SearchQuery(ctx, []byte(getClusterHistoricCountQuery(decisionPeriod, thresholdMap)))
+	if err != nil {
+		log.Error.Println("Cannot fetch cluster historic count: ", err)
+		return metricViolatedCount, err
+	}
+	defer searchResp.Body.Close()
+
+	var queryResultInterface map[string]interface{}
+	decodeErr := json.NewDecoder(searchResp.Body).Decode(&queryResultInterface)
+	if decodeErr != nil {
+		log.Error.Println("decode Error: ", decodeErr)
+		return metricViolatedCount, decodeErr
+	}
+	aggregations, ok := queryResultInterface["aggregations"].(map[string]interface{})
+	if !ok {
+		return metricViolatedCount, fmt.Errorf("no aggregations in the response: %v", queryResultInterface)
+	}
+
+	parseCount := func(aggs map[string]interface{}, metricName string) (MetricViolatedCount, error) {
+		violatedAgg, _ := aggs[metricName].(map[string]interface{})
+		violatedCount, violatedOk := violatedAgg["doc_count"].(float64)
+		totalAgg, _ := aggs["docs_count"].(map[string]interface{})
+		totalCount, totalOk := totalAgg["value"].(float64)
+		if !violatedOk || !totalOk {
+			return MetricViolatedCount{}, fmt.Errorf("no %s count in the response", metricName)
+		}
+		return MetricViolatedCount{
+			ViolatedCount: int(violatedCount),
+			TotalCount:    int(totalCount),
+		}, nil
+	}
+
+	nodeBuckets, err := getAggBuckets(aggregations, "nodes")
+	if err != nil {
+		return metricViolatedCount, err
+	}
+	for _, metricName := range HistoricMetrics {
+		if _, ok := thresholdMap[metricName]; !ok {
+			continue
+		}
+		clusterCount, err := parseCount(aggregations, metricName)
+		if err != nil {
+			return metricViolatedCount, err
+		}
+		metricCount := MetricViolatedCountCluster{
+			MetricName:   metricName,
+			ClusterLevel: clusterCount,
+		}
+		for _, nodeBucket := range nodeBuckets {
+			nodeBucketMap, _ := nodeBucket.(map[string]interface{})
+			hostIp, ok := nodeBucketMap["key"].(string)
+			if !ok {
+				return metricViolatedCount, fmt.Errorf("invalid node bucket in the response: %v", nodeBucket)
+			}
+			nodeCount, err := parseCount(nodeBucketMap, metricName)
+			if err != nil {
+				return metricViolatedCount, fmt.Errorf("%w for the node %s", err, hostIp)
+			}
+			metricCount.NodeLevel = append(metricCount.NodeLevel, MetricViolatedCountNode{
+				MetricViolatedCount: nodeCount,
+				HostIp:              hostIp,
+			})
+		}
+		metricViolatedCount = append(metricViolatedCount, metricCount)
+	}
+	return metricViolatedCount, nil
+}
+
+// Input:
+//              metricName (string): The metric for which the series is needed.
+//              historyMinutes (int): Time in minutes used to specify the time range for collecting data from Opensearch.
+//              interval (int): Time in seconds of every interval of the series.
+//
+// Description:
+//              Generates the query string for determining the cluster average of the metric for every interval.
+//
+// Return:
+//              (string): Returns the query string that can be given as an OS query api parameter.
+
+func getClusterHistoricSeriesQuery(metricName string, historyMinutes int, interval int) string {
+	clusterHistoricSeriesQueryString := `{
+          "size": 0,
+          "query": {
+            "bool": {
+              "filter": {
+                "range": {
+                  "Timestamp": {
+                    "gte": "now-` + strconv.Itoa(historyMinutes) + `m",
+                    "include_lower": true,
+                    "include_upper": true,
+                    "to": null
+                  }
+                }
+              },
+              "must": [
+                {
+                  "match": {
+                    "StatTag": "NodeStatistics"
+                  }
+                }
+              ]
+            }
+          },
+          "aggs": {
+            "interval": {
+              "date_histogram": {
+                "field": "Timestamp",
+                "fixed_interval": "` + strconv.Itoa(interval) + `s",
+                "min_doc_count": 1
+              },
+              "aggs": {
+                "` + metricName + `": {
+                  "avg": {
+                    "field": "` + metricName + `"
+                  }
+                }
+              }
+            }
+          }
+        }`
+	return clusterHistoricSeriesQueryString
+}
+
+// Input:
+//              ctx (context.Context): Request-scoped data that transits processes and APIs.
+//              metricName (string): The metric for which the series is needed.
+//              historyMinutes (int): Time in minutes for which the series is collected.
+//              interval (int): Time in seconds of every interval of the series.
+//
+// Description:
+//              GetClusterHistoricSeries returns the cluster average of the metric for every interval of the history.
+//              The intervals without any document are skipped.
+//
+// Return:
+//              ([]MetricDataPoint, error): Return the data points sorted by time and error if any.
+
+func GetClusterHistoricSeries(ctx context.Context, metricName string, historyMinutes int, interval int) ([]MetricDataPoint, error) {
+	var dataPoints []MetricDataPoint
+
+	searchResp, err := osutils.SearchQuery(ctx, []byte(getClusterHistoricSeriesQuery(metricName, historyMinutes, interval)))
+	if err != nil {
+		log.Error.Println("Cannot fetch cluster historic series: ", err)
+		return dataPoints, err
+	}
+	defer searchResp.Body.Close()
+
+	var queryResultInterface map[string]interface{}
+	decodeErr := json.NewDecoder(searchResp.Body).Decode(&queryResultInterface)
+	if decodeErr != nil {
+		log.Error.Println("decode Error: ", decodeErr)
+		return dataPoints, decodeErr
+	}
+	aggregations, ok := queryResultInterface["aggregations"].(map[string]interface{})
+	if !ok {
+		return dataPoints, fmt.Errorf("no aggregations in the response: %v", queryResultInterface)
+	}
+
+	buckets, err := getAggBuckets(aggregations, "interval")
+	if err != nil {
+		return dataPoints, err
+	}
+	for _, bucket := range buckets {
+		bucketMap, _ := bucket.(map[string]interface{})
+		timestamp, ok := bucketMap["key"].(float64)
+		if !ok {
+			return dataPoints, fmt.Errorf("invalid interval bucket in the response: %v", bucket)
+		}
+		// The average is null for an interval without the metric
+		avgAgg, _ := bucketMap[metricName].(map[string]interface{})
+		value, ok := avgAgg["value"].(float64)
+		if !ok {
+			continue
+		}
+		dataPoints = append(dataPoints, MetricDataPoint{
+			Timestamp: int64(timestamp),
+			Value:     float32(value),
+		})
+	}
+	return dataPoints, nil
 }
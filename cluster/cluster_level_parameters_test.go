@@ -0,0 +1,48 @@
+package cluster
+
+import (
+	"testing"
+)
+
+func TestGetRate(t *testing.T) {
+	tests := []struct {
+		name       string
+		dataPoints []MetricDataPoint
+		expected   float32
+	}{
+		{"no data points", nil, 0},
+		{"single data point", []MetricDataPoint{{Timestamp: 0, Value: 40}}, 0},
+		{"not spread over time", []MetricDataPoint{{Timestamp: 60000, Value: 40}, {Timestamp: 60000, Value: 60}}, 0},
+		{"flat", []MetricDataPoint{{Timestamp: 0, Value: 40}, {Timestamp: 60000, Value: 40}, {Timestamp: 120000, Value: 40}}, 0},
+		{"rising", []MetricDataPoint{{Timestamp: 0, Value: 40}, {Timestamp: 60000, Value: 42}, {Timestamp: 120000, Value: 44}}, 2},
+		{"rising per minute", []MetricDataPoint{{Timestamp: 0, Value: 40}, {Timestamp: 300000, Value: 50}}, 2},
+		{"falling", []MetricDataPoint{{Timestamp: 0, Value: 60}, {Timestamp: 60000, Value: 57}, {Timestamp: 120000, Value: 54}}, -3},
+		{"least squares", []MetricDataPoint{{Timestamp: 0, Value: 40}, {Timestamp: 60000, Value: 46}, {Timestamp: 120000, Value: 44}}, 2},
+	}
+	for _, test := range tests {
+		if rate := getRate(test.dataPoints); rate != test.expected {
+			t.Errorf("%s: expected the rate %v got %v", test.name, test.expected, rate)
+		}
+	}
+}
+
+func TestGetAggBuckets(t *testing.T) {
+	tests := []struct {
+		name    string
+		aggs    map[string]interface{}
+		buckets int
+		isValid bool
+	}{
+		{"buckets", map[string]interface{}{"nodes": map[string]interface{}{"buckets": []interface{}{map[string]interface{}{}}}}, 1, true},
+		{"no buckets", map[string]interface{}{"nodes": map[string]interface{}{"buckets": []interface{}{}}}, 0, true},
+		{"missing aggregation", map[string]interface{}{}, 0, false},
+		{"aggregation of the wrong type", map[string]interface{}{"nodes": 1.0}, 0, false},
+		{"buckets of the wrong type", map[string]interface{}{"nodes": map[string]interface{}{"buckets": "none"}}, 0, false},
+	}
+	for _, test := range tests {
+		buckets, err := getAggBuckets(test.aggs, "nodes")
+		if (err == nil) != test.isValid || len(buckets) != test.buckets {
+			t.Errorf("%s: expected %d buckets with the validity %v got %d buckets with the error %v", test.name, test.buckets, test.isValid, len(buckets), err)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/maplelabs/opensearch-scaling-manager/provision"
+	"github.com/spf13/cobra"
+)
+
+// Command to abort the provision in progress
+var abortCmd = &cobra.Command{
+	Use:   "abort",
+	Short: "Abort the provision in progress",
+	Long:  `Abort the provision in progress. The leader stops the provision and rolls it back like a failed provision`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := abort()
+		if err != nil {
+			log.Error.Println(err)
+			os.Exit(1)
+		}
+		log.Info.Println("The abort is requested, the provision will be rolled back by the leader")
+	},
+}
+
+// Input:
+//
+// Description:
+//
+//	Requests the abort of the provision in progress in the state shared by all the nodes.
+//
+// Return:
+//
+//	(error): Returns error upon unsuccessful execution.
+func abort() error {
+	if _, err := initializeState(); err != nil {
+		return err
+	}
+	return provision.RequestAbort()
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/maplelabs/opensearch-scaling-manager/provision"
+	"github.com/spf13/cobra"
+)
+
+// Command to acknowledge a provision which needs attention
+var acknowledgeCmd = &cobra.Command{
+	Use:   "acknowledge",
+	Short: "Acknowledge the failed provision which needs attention",
+	Long:  `Acknowledge the failed provision which could not be rolled back, once it is resolved, so that the provisions can continue`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := acknowledge()
+		if err != nil {
+			log.Error.Println(err)
+			os.Exit(1)
+		}
+		log.Info.Println("The provision is acknowledged")
+	},
+}
+
+// Input:
+//
+// Description:
+//
+//	Sets the state which needs attention back to normal.
+//
+// Return:
+//
+//	(error): Returns error upon unsuccessful execution.
+func acknowledge() error {
+	if _, err := initializeState(); err != nil {
+		return err
+	}
+	return provision.AcknowledgeAttention()
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/maplelabs/opensearch-scaling-manager/config"
+	"github.com/spf13/cobra"
+)
+
+// Command to manage the configuration file
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the configuration of Opensearch Scaling Manager",
+}
+
+// Command to validate the configuration file
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Validate the configuration file",
+	Long: `Report every issue of the configuration file (config.yaml by default) with its line and column: the syntax errors, the unknown fields,
+the failed validations and the semantic issues, Ex: the scale_up and scale_down tasks which could flap. Exits with 1 if there are issues`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := config.ConfigFileName
+		if len(args) > 0 {
+			path = args[0]
+		}
+		if !validateConfig(path) {
+			os.Exit(1)
+		}
+	},
+}
+
+// Input:
+//
+// Description:
+//
+//	Initializes the config command by adding its subcommands
+//
+// Return:
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+}
+
+// Input:
+//
+//	path (string): The path of the configuration file
+//
+// Description:
+//
+//	Prints every issue of the configuration file as file:line:column: field: message.
+//
+// Return:
+//
+//	(bool): Returns true if the configuration file is valid.
+func validateConfig(path string) bool {
+	issues, err := config.ValidateConfigFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Unable to read the config file:", err)
+		return false
+	}
+	for _, issue := range issues {
+		fmt.Println(issue.Format(path))
+	}
+	if len(issues) > 0 {
+		fmt.Printf("%s: %d issue(s) found\n", path, len(issues))
+		return false
+	}
+	fmt.Printf("%s is valid\n", path)
+	return true
+}
@@ -34,4 +34,11 @@ func Execute() error{
 func init(){
         scaleManagerCmd.AddCommand(startCmd)
         scaleManagerCmd.AddCommand(stopCmd)
+        scaleManagerCmd.AddCommand(statusCmd)
+        scaleManagerCmd.AddCommand(acknowledgeCmd)
+        scaleManagerCmd.AddCommand(pauseCmd)
+        scaleManagerCmd.AddCommand(resumeCmd)
+        scaleManagerCmd.AddCommand(abortCmd)
+        scaleManagerCmd.AddCommand(scaleCmd)
+        scaleManagerCmd.AddCommand(configCmd)
 }
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/maplelabs/opensearch-scaling-manager/provision"
+	"github.com/spf13/cobra"
+)
+
+// Input:
+//
+// Description:
+//
+//	Initializes the pause command, adds the required flags
+//
+// Return:
+func init() {
+	pauseCmd.PersistentFlags().String("reason", "", "Reason to pause the recommendations")
+}
+
+// Command to pause the recommendations on all the nodes
+var pauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pause the recommendations of Opensearch Scaling Manager",
+	Long:  `Pause the recommendations and the event based scaling on all the nodes until they are resumed. The provision in progress and the manual scales are not affected`,
+	Run: func(cmd *cobra.Command, args []string) {
+		reason, _ := cmd.Flags().GetString("reason")
+		err := pause(reason)
+		if err != nil {
+			log.Error.Println(err)
+			os.Exit(1)
+		}
+		log.Info.Println("The recommendations are paused")
+	},
+}
+
+// Command to resume the recommendations paused on all the nodes
+var resumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume the recommendations of Opensearch Scaling Manager",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := resume()
+		if err != nil {
+			log.Error.Println(err)
+			os.Exit(1)
+		}
+		log.Info.Println("The recommendations are resumed")
+	},
+}
+
+// Input:
+//
+//	reason (string): Reason to pause the recommendations
+//
+// Description:
+//
+//	Pauses the recommendations in the state shared by all the nodes.
+//
+// Return:
+//
+//	(error): Returns error upon unsuccessful execution.
+func pause(reason string) error {
+	if _, err := initializeState(); err != nil {
+		return err
+	}
+	return provision.PauseRecommendations(reason)
+}
+
+// Input:
+//
+// Description:
+//
+//	Resumes the recommendations in the state shared by all the nodes.
+//
+// Return:
+//
+//	(error): Returns error upon unsuccessful execution.
+func resume() error {
+	if _, err := initializeState(); err != nil {
+		return err
+	}
+	return provision.ResumeRecommendations()
+}
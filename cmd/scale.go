@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/maplelabs/opensearch-scaling-manager/provision"
+	"github.com/spf13/cobra"
+)
+
+// Input:
+//
+// Description:
+//
+//	Initializes the scale command, adds the required flags
+//
+// Return:
+func init() {
+	scaleCmd.PersistentFlags().Int("nodes", 1, "Number of nodes to be added or removed")
+	scaleCmd.PersistentFlags().String("reason", "", "Reason to scale the cluster")
+}
+
+// Command to scale the cluster manually
+var scaleCmd = &cobra.Command{
+	Use:       "scale up|down",
+	Short:     "Scale the cluster up or down manually",
+	Long:      `Request the leader to add or remove the nodes. The scale is checked against the max and min nodes and provisioned even if the recommendations are paused`,
+	Args:      cobra.ExactValidArgs(1),
+	ValidArgs: []string{"up", "down"},
+	Run: func(cmd *cobra.Command, args []string) {
+		numNodes, _ := cmd.Flags().GetInt("nodes")
+		reason, _ := cmd.Flags().GetString("reason")
+		err := scale("scale_"+args[0], numNodes, reason)
+		if err != nil {
+			log.Error.Println(err)
+			os.Exit(1)
+		}
+		log.Info.Println(fmt.Sprintf("The scale %s by %d node(s) is requested, it will be provisioned by the leader", args[0], numNodes))
+	},
+}
+
+// Input:
+//
+//	operation (string): The operation requested (scale_up or scale_down)
+//	numNodes (int): Number of nodes to be added or removed
+//	reason (string): Reason to scale the cluster
+//
+// Description:
+//
+//	Requests the scale in the state shared by all the nodes, which is provisioned by the leader.
+//
+// Return:
+//
+//	(error): Returns error upon unsuccessful execution.
+func scale(operation string, numNodes int, reason string) error {
+	if reason == "" {
+		return fmt.Errorf("the reason of the scale is required, Ex: --reason \"expected traffic\"")
+	}
+	configStruct, err := initializeState()
+	if err != nil {
+		return err
+	}
+	return provision.RequestScale(configStruct.ClusterDetails, configStruct.UserConfig, operation, numNodes, reason)
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/maplelabs/opensearch-scaling-manager/config"
+	"github.com/maplelabs/opensearch-scaling-manager/crypto"
+	"github.com/maplelabs/opensearch-scaling-manager/provision"
+)
+
+// Input:
+//
+// Description:
+//
+//	Reads the config file and initializes the Opensearch client and the state store, so that the commands
+//	can read and update the state shared by all the nodes.
+//
+// Return:
+//
+//	(config.ConfigStruct, error): Returns the config and error if the config file could not be read
+func initializeState() (config.ConfigStruct, error) {
+	configStruct, err := config.GetConfig()
+	if err != nil {
+		return configStruct, err
+	}
+	crypto.InitializeOsClient(configStruct)
+	provision.InitializeDocId()
+	provision.InitializeStateStore(configStruct.UserConfig.StateStore)
+	return configStruct, nil
+}
@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/maplelabs/opensearch-scaling-manager/config"
+	"github.com/maplelabs/opensearch-scaling-manager/fetchmetrics"
+	"github.com/maplelabs/opensearch-scaling-manager/leader"
+	"github.com/maplelabs/opensearch-scaling-manager/provision"
+	"github.com/maplelabs/opensearch-scaling-manager/recommendation"
+	"github.com/spf13/cobra"
+)
+
+// The default number of the last provisions shown by the status command.
+const defaultStatusProvisions = 5
+
+// This struct contains the status of the Scaling Manager shown by the status command.
+type statusReport struct {
+	// State indicates the state of the provision
+	State provisionStatus
+	// Leader indicates the node holding the leader lease
+	Leader leaderStatus
+	// Provisions indicates the last provisions, latest first
+	Provisions []provision.ProvisionStats
+	// ClusterStatistics indicates the latest statistics of the cluster indexed by the leader
+	ClusterStatistics *fetchmetrics.ClusterMetrics `json:",omitempty"`
+	// NextEvents indicates when the EVENT tasks are triggered next
+	NextEvents []recommendation.EventFireTime
+	// Errors indicates the parts of the status which could not be fetched
+	Errors []string `json:",omitempty"`
+}
+
+// This struct contains the state of the provision shown by the status command.
+type provisionStatus struct {
+	CurrentState     provision.ProvisionState
+	PreviousState    provision.ProvisionState
+	StateEnteredTime time.Time
+	// TimeInState indicates the time since the current state was entered
+	TimeInState      string
+	RuleTriggered    string `json:",omitempty"`
+	RulesResponsible string `json:",omitempty"`
+	NumNodes         int    `json:",omitempty"`
+	RemainingNodes   int    `json:",omitempty"`
+	NodeIps          []string
+	NodeNames        []string
+	// Elapsed indicates the time since the provision in progress started
+	Elapsed        string `json:",omitempty"`
+	Retries        int
+	Remark         string `json:",omitempty"`
+	Paused         bool
+	PauseReason    string `json:",omitempty"`
+	AbortRequested bool
+	ManualScale    *provision.ManualScaleRequest `json:",omitempty"`
+}
+
+// This struct contains the leader lease shown by the status command.
+type leaderStatus struct {
+	Holder       string
+	FencingToken int64
+	RenewedTime  time.Time
+	ExpiryTime   time.Time
+	IsExpired    bool
+}
+
+// Input:
+//
+// Description:
+//
+//	Initializes the status command, adds the required flags
+//
+// Return:
+func init() {
+	statusCmd.PersistentFlags().Bool("json", false, "Print the status as JSON")
+	statusCmd.PersistentFlags().Int("provisions", defaultStatusProvisions, "Number of the last provisions to be shown")
+}
+
+// Command to show the status of the Scaling Manager
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the status of Opensearch Scaling Manager",
+	Long:  `Show the state of the provision, the leader, the last provisions, the latest statistics of the cluster and the next EVENT tasks`,
+	Run: func(cmd *cobra.Command, args []string) {
+		isJson, _ := cmd.Flags().GetBool("json")
+		numProvisions, _ := cmd.Flags().GetInt("provisions")
+		err := status(isJson, numProvisions)
+		if err != nil {
+			log.Error.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// Input:
+//
+//	isJson (bool): Whether the status is printed as JSON
+//	numProvisions (int): Number of the last provisions to be shown
+//
+// Description:
+//
+//	Prints the status of the Scaling Manager. The parts of the status which could not be fetched, Ex: the cluster statistics
+//	when no metrics are indexed yet, are reported as errors along with the rest of the status.
+//
+// Return:
+//
+//	(error): Returns error upon unsuccessful execution.
+func status(isJson bool, numProvisions int) error {
+	configStruct, err := initializeState()
+	if err != nil {
+		return err
+	}
+	report := getStatusReport(configStruct, numProvisions, time.Now())
+
+	if isJson {
+		content, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(content))
+		return nil
+	}
+	printStatusReport(report, time.Now())
+	return nil
+}
+
+// Input:
+//
+//	configStruct (config.ConfigStruct): The configuration of the Scaling Manager
+//	numProvisions (int): Number of the last provisions to be shown
+//	now (time.Time): The time at which the status is shown
+//
+// Description:
+//
+//	Collects the status from the state, the leader lease and the monitor-stats index.
+//	The state is only read, so that the status never creates the state or takes the leader lease.
+//
+// Return:
+//
+//	(statusReport): Returns the status
+func getStatusReport(configStruct config.ConfigStruct, numProvisions int, now time.Time) statusReport {
+	var report statusReport
+
+	state, found, err := provision.ReadState()
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("unable to read the state: %s", err))
+	} else if !found {
+		// The state is created in the normal state by the first provision check
+		state.CurrentState = provision.StateNormal
+	}
+	report.State = provisionStatus{
+		CurrentState:     state.CurrentState,
+		PreviousState:    state.PreviousState,
+		RuleTriggered:    state.RuleTriggered,
+		RulesResponsible: state.RulesResponsible,
+		NumNodes:         state.NumNodes,
+		RemainingNodes:   state.RemainingNodes,
+		NodeIps:          state.NodeIps,
+		NodeNames:        state.NodeNames,
+		Retries:          state.Retries,
+		Remark:           state.Remark,
+		Paused:           state.Paused,
+		PauseReason:      state.PauseReason,
+		AbortRequested:   state.AbortRequested,
+		ManualScale:      state.ManualScale,
+	}
+	if state.StateEnteredTime != 0 {
+		report.State.StateEnteredTime = time.UnixMilli(state.StateEnteredTime)
+		report.State.TimeInState = now.Sub(report.State.StateEnteredTime).Round(time.Second).String()
+	}
+	if state.CurrentState != provision.StateNormal && state.ProvisionStartTime != 0 {
+		report.State.Elapsed = now.Sub(time.UnixMilli(state.ProvisionStartTime)).Round(time.Second).String()
+	}
+
+	lease, err := leader.GetLease()
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("unable to read the leader lease: %s", err))
+	} else if lease.Holder != "" {
+		report.Leader = leaderStatus{
+			Holder:       lease.Holder,
+			FencingToken: lease.FencingToken,
+			RenewedTime:  time.UnixMilli(lease.RenewedTime),
+			ExpiryTime:   time.UnixMilli(lease.ExpiryTime),
+			IsExpired:    now.After(time.UnixMilli(lease.ExpiryTime)),
+		}
+	}
+
+	if numProvisions > 0 {
+		report.Provisions, err = provision.GetProvisionStats(numProvisions)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("unable to fetch the provisions: %s", err))
+		}
+	}
+
+	clusterMetrics, found, err := fetchmetrics.GetLatestClusterMetrics(context.Background())
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("unable to fetch the cluster statistics: %s", err))
+	} else if found {
+		report.ClusterStatistics = &clusterMetrics
+	}
+
+	_, eventTasks := recommendation.ParseTasks(config.TaskDetails{Tasks: configStruct.TaskDetails})
+	report.NextEvents = recommendation.NextEventFireTimes(eventTasks, now)
+	return report
+}
+
+// Input:
+//
+//	report (statusReport): The status to be printed
+//	now (time.Time): The time at which the status is shown
+//
+// Description:
+//
+//	Prints the status in a human readable form.
+//
+// Return:
+func printStatusReport(report statusReport, now time.Time) {
+	state := report.State
+	fmt.Printf("State: %s (previous %s", state.CurrentState, state.PreviousState)
+	if state.TimeInState != "" {
+		fmt.Printf(", for %s", state.TimeInState)
+	}
+	fmt.Println(")")
+	if state.Elapsed != "" {
+		fmt.Printf("Provision: %s of %d node(s), running for %s\n", state.RuleTriggered, state.NumNodes, state.Elapsed)
+		fmt.Println("Rules responsible:", state.RulesResponsible)
+		if len(state.NodeIps) > 0 {
+			fmt.Printf("Nodes: %s %s, %d remaining\n", strings.Join(state.NodeNames, ","), strings.Join(state.NodeIps, ","), state.RemainingNodes)
+		}
+		if state.Retries > 0 {
+			fmt.Println("Retries:", state.Retries)
+		}
+	}
+	if state.Remark != "" {
+		fmt.Println("Remark:", state.Remark)
+	}
+	if state.AbortRequested {
+		fmt.Println("Abort: requested")
+	}
+	if state.Paused {
+		fmt.Println("Recommendations: paused,", state.PauseReason)
+	}
+	if state.ManualScale != nil {
+		fmt.Printf("Manual scale: %s by %d node(s) requested by %s, %s\n", state.ManualScale.Operation, state.ManualScale.NumNodes, state.ManualScale.RequestedBy, state.ManualScale.Reason)
+	}
+
+	switch {
+	case report.Leader.Holder == "":
+		fmt.Println("Leader: none")
+	case report.Leader.IsExpired:
+		fmt.Printf("Leader: none (the lease of %s expired at %s)\n", report.Leader.Holder, report.Leader.ExpiryTime.Format(time.RFC3339))
+	default:
+		fmt.Printf("Leader: %s (fencing token %d, last heartbeat at %s)\n", report.Leader.Holder, report.Leader.FencingToken, report.Leader.RenewedTime.Format(time.RFC3339))
+	}
+
+	if cluster := report.ClusterStatistics; cluster != nil {
+		fmt.Printf("Cluster: %s with %d node(s), %d active, %d relocating and %d unassigned shards (as of %s ago)\n", cluster.ClusterStatus, cluster.NumNodes,
+			cluster.NumActiveShards, cluster.NumRelocatingShards, cluster.NumUnassignedShards, now.Sub(time.UnixMilli(cluster.Timestamp)).Round(time.Second))
+	}
+
+	if len(report.Provisions) > 0 {
+		fmt.Println("Last provisions:")
+		for _, provisionStats := range report.Provisions {
+			fmt.Printf("  %s %s of %d node(s): %s in %s", time.UnixMilli(provisionStats.ProvisionStartTime).Format(time.RFC3339), provisionStats.RuleTriggered,
+				provisionStats.NumNodes, provisionStats.Status, provisionStats.TimeTaken)
+			if provisionStats.FailureReason != "" {
+				fmt.Printf(", %s", provisionStats.FailureReason)
+			}
+			fmt.Println()
+		}
+	}
+
+	if len(report.NextEvents) > 0 {
+		fmt.Println("Next events:")
+		for _, event := range report.NextEvents {
+			fmt.Printf("  %s %s (%s)\n", event.NextFireTime.Format(time.RFC3339), event.TaskName, event.SchedulingTime)
+		}
+	}
+
+	for _, err := range report.Errors {
+		fmt.Println("Error:", err)
+	}
+}
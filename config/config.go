@@ -3,11 +3,14 @@ package config
 import (
 	"io/ioutil"
 	"os"
+	"reflect"
 	"regexp"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/maplelabs/opensearch-scaling-manager/cluster"
 	"github.com/maplelabs/opensearch-scaling-manager/logger"
+	osutils "github.com/maplelabs/opensearch-scaling-manager/opensearchUtils"
 	"gopkg.in/yaml.v3"
 )
 
@@ -35,22 +38,70 @@ type OsCredentials struct {
 }
 
 // This struct contains the Cloud Secret Key and Access Key via which we can connect to the cloud.
+// The fields that are required depends on the cloud_type of the cluster.
 type CloudCredentials struct {
-	PemFilePath string `yaml:"pem_file_path" validate:"required" json:"pem_file_path"`
+	PemFilePath string `yaml:"pem_file_path,omitempty" json:"pem_file_path"`
 	// SecretKey indicates the Secret key for connecting to the cloud.
-	SecretKey string `yaml:"secret_key" validate:"required_without=RoleArn" json:"secret_key"`
+	SecretKey string `yaml:"secret_key,omitempty" json:"secret_key"`
 	// AccessKey indicates the Access key for connecting to the cloud.
-	AccessKey string `yaml:"access_key" validate:"required_without=RoleArn" json:"access_key"`
-	Region    string `yaml:"region" validate:"required" json:"region"`
-	RoleArn   string `yaml:"role_arn" validate:"required_without_all=SecretKey AccessKey" json:"role_arn"`
+	AccessKey string `yaml:"access_key,omitempty" json:"access_key"`
+	// Region indicates the AWS region or the Azure location where the new nodes are created.
+	Region  string `yaml:"region,omitempty" json:"region"`
+	RoleArn string `yaml:"role_arn,omitempty" json:"role_arn"`
+	// GcpCredentialsFile indicates the path of the GCP service account key file.
+	// If it is not set then the application default credentials are used.
+	GcpCredentialsFile string `yaml:"gcp_credentials_file,omitempty" json:"gcp_credentials_file"`
+	// ProjectId indicates the GCP project where the new nodes are created.
+	ProjectId string `yaml:"project_id,omitempty" json:"project_id"`
+	// Zone indicates the GCP zone where the new nodes are created.
+	Zone string `yaml:"zone,omitempty" json:"zone"`
+	// SubscriptionId indicates the Azure subscription where the new nodes are created.
+	SubscriptionId string `yaml:"subscription_id,omitempty" json:"subscription_id"`
+	// ResourceGroup indicates the Azure resource group where the new nodes are created.
+	ResourceGroup string `yaml:"resource_group,omitempty" json:"resource_group"`
+	// TenantId, ClientId and ClientSecret indicates the Azure service principal.
+	// If they are not set then the default Azure credential chain is used.
+	TenantId     string `yaml:"tenant_id,omitempty" json:"tenant_id"`
+	ClientId     string `yaml:"client_id,omitempty" json:"client_id"`
+	ClientSecret string `yaml:"client_secret,omitempty" json:"client_secret"`
+}
+
+// This struct contains the details required to create a new virtual machine on Azure.
+type AzureVmConfig struct {
+	// VmSize indicates the size of the virtual machine. Ex: Standard_D4s_v3
+	VmSize string `yaml:"vm_size" json:"vm_size"`
+	// ImageId indicates either the resource ID of an image or a marketplace image in the form publisher:offer:sku:version.
+	ImageId string `yaml:"image_id" json:"image_id"`
+	// SubnetId indicates the resource ID of the subnet in which the network interface of the node is created.
+	SubnetId string `yaml:"subnet_id" json:"subnet_id"`
+}
+
+// This struct contains the details required to run the OpenSearch nodes on the local host when cloud_type is LOCAL.
+// Each node is bound to its own loopback address (127.0.0.x) so that the nodes can be identified by the ip address.
+type LocalConfig struct {
+	// Mode indicates whether the nodes are started as local processes or docker containers. These can be:
+	//	process: Runs the opensearch binary present in os_home.
+	//	docker: Runs the docker image in the host network.
+	Mode string `yaml:"mode" validate:"omitempty,oneof=process docker" json:"mode"`
+	// WorkDir indicates the directory where the configuration, data and logs of the local nodes are stored.
+	WorkDir string `yaml:"work_dir" json:"work_dir"`
+	// DockerImage indicates the OpenSearch image used in docker mode. Defaults to opensearchproject/opensearch:<os_version>.
+	DockerImage string `yaml:"docker_image,omitempty" json:"docker_image"`
+	// HeapSize indicates the JVM heap size of the local nodes. Defaults to 512m.
+	HeapSize string `yaml:"heap_size,omitempty" json:"heap_size"`
+	// Settings indicates additional OpenSearch settings applied to every local node. Ex: plugins.security.disabled: "true"
+	Settings map[string]string `yaml:"settings,omitempty" json:"settings"`
 }
 
 // This struct contains the data structure to parse the cluster details present in the configuration file.
 type ClusterDetails struct {
 	// ClusterStatic indicates the static configuration for the cluster.
 	cluster.ClusterStatic `yaml:",inline"`
-	LaunchTemplateId      string           `yaml:"launch_template_id" validate:"required" json:"launch_template_id"`
-	LaunchTemplateVersion string           `yaml:"launch_template_version" validate:"required" json:"launch_template_version"`
+	// LaunchTemplateId indicates the AWS launch template ID or the GCP instance template used to create a new node.
+	LaunchTemplateId      string           `yaml:"launch_template_id,omitempty" json:"launch_template_id"`
+	LaunchTemplateVersion string           `yaml:"launch_template_version,omitempty" json:"launch_template_version"`
+	AzureVmConfig         AzureVmConfig    `yaml:"azure_vm_config,omitempty" json:"azure_vm_config"`
+	LocalConfig           LocalConfig      `yaml:"local_config,omitempty" json:"local_config"`
 	SshUser               string           `yaml:"os_user" validate:"required" json:"os_user"`
 	OsGroup               string           `yaml:"os_group" validate:"required" json:"os_group"`
 	OpensearchVersion     string           `yaml:"os_version" validate:"required" json:"os_version"`
@@ -59,6 +110,9 @@ type ClusterDetails struct {
 	OsCredentials         OsCredentials    `yaml:"os_credentials" json:"os_credentials"`
 	CloudCredentials      CloudCredentials `yaml:"cloud_credentials" json:"cloud_credentials"`
 	JvmFactor             float64          `yaml:"jvm_factor" validate:"required,max=0.5" json:"jvm_factor"`
+
+	// OsConnection indicates how the OS client connects to the OS cluster, Ex: the addresses and the TLS.
+	OsConnection osutils.ConnectionConfig `yaml:"os_connection,omitempty" json:"os_connection"`
 }
 
 // Config for application behaviour from user
@@ -69,6 +123,61 @@ type UserConfig struct {
 	RecommendationPollingInterval int  `yaml:"recommendation_polling_interval_in_secs" validate:"required,min=60"`
 	FetchPollingInterval          int  `yaml:"fetchmetrics_polling_interval_in_secs" validate:"required,min=60"`
 	IsAccelerated                 bool `yaml:"is_accelerated"`
+	// RecommendationExpiry indicates the time in seconds after which a recommendation that was not recommended again is discarded.
+	// The default is twice the recommendation polling interval.
+	RecommendationExpiry int `yaml:"recommendation_expiry_in_secs,omitempty" validate:"min=0"`
+	// ConflictPolicy indicates what is provisioned when both scale_up and scale_down tasks are recommended. These can be:
+	//              priority: The task with the highest priority is provisioned. This is the default.
+	//              discard: All the recommendations are discarded, until the recommendations agree on the operation.
+	ConflictPolicy string `yaml:"recommendation_conflict_policy,omitempty" validate:"omitempty,oneof=priority discard"`
+	// DryRun indicates that the tasks are evaluated but never provisioned.
+	// The recommendations that would have been provisioned are indexed to Opensearch with the StatTag DryRunStats.
+	DryRun bool `yaml:"dry_run,omitempty"`
+	// LeaderLeaseDuration indicates the time in seconds for which the leader lease is held without a heartbeat.
+	// Only the node holding the lease evaluates and provisions the recommendations. The default is 30 seconds.
+	LeaderLeaseDuration int `yaml:"leader_lease_duration_in_secs,omitempty" validate:"omitempty,min=10"`
+	// StateStore indicates where the state of the provision is persisted.
+	StateStore StateStoreConfig `yaml:"state_store,omitempty"`
+	// ProvisionRetry indicates how a failed phase of the provision is retried before the provision is rolled back.
+	ProvisionRetry RetryConfig `yaml:"provision_retry,omitempty"`
+	// ProvisionTimeout indicates the deadlines after which a stuck provision is timed out and rolled back.
+	ProvisionTimeout TimeoutConfig `yaml:"provision_timeout,omitempty"`
+}
+
+// This struct contains how a failed phase of the provision is retried.
+type RetryConfig struct {
+	// MaxRetries indicates the number of times a failed phase is retried. The default is 0, i.e., the provision is rolled back on the first failure.
+	MaxRetries int `yaml:"max_retries,omitempty" validate:"min=0"`
+	// Backoff indicates the time in seconds before the first retry, which is doubled for every further retry. The default is 30 seconds.
+	Backoff int `yaml:"backoff_in_secs,omitempty" validate:"min=0"`
+	// MaxBackoff indicates the maximum time in seconds between the retries. The default is 600 seconds.
+	MaxBackoff int `yaml:"max_backoff_in_secs,omitempty" validate:"min=0"`
+	// Phases indicates the max_retries and backoff_in_secs of a phase, keyed by the state of the provision in the phase.
+	// i.e., start_scaleup_process, scaleup_triggered_spin_vm, provisioning_scaleup_configured, start_scaledown_process
+	Phases map[string]RetryConfig `yaml:"phases,omitempty" validate:"omitempty,dive"`
+}
+
+// This struct contains the deadlines of the provision.
+type TimeoutConfig struct {
+	// Total indicates the time in seconds from the start of the provision after which it is timed out. The default is 0, i.e., only the phases are timed out.
+	Total int `yaml:"total_in_secs,omitempty" validate:"min=0"`
+	// Phases indicates the time in seconds the provision can be in a phase, keyed by the state of the provision in the phase.
+	// The phases which are not configured have a default timeout, Ex: 900 seconds for provisioning_scaleup_configured.
+	Phases map[string]int `yaml:"phases_in_secs,omitempty" validate:"omitempty,dive,min=1"`
+}
+
+// The default path of the state file, relative to the working directory.
+const DefaultStateFilePath = "state.json"
+
+// This struct contains where the state of the provision is persisted.
+type StateStoreConfig struct {
+	// Backend indicates the store of the state. These can be:
+	//              opensearch: The state is a document in the monitor-stats index. This is the default.
+	//              file: The state is a local file.
+	//              mirror: The state is a document in Opensearch and it is mirrored to a local file, which is used while Opensearch is unavailable.
+	Backend string `yaml:"backend,omitempty" validate:"omitempty,oneof=opensearch file mirror"`
+	// FilePath indicates the path of the state file. The default is state.json in the working directory.
+	FilePath string `yaml:"file_path,omitempty"`
 }
 
 // This struct contains the data structure to parse the configuration file.
@@ -83,9 +192,37 @@ type Task struct {
 	// TaskName indicates the name of the task to recommend by the recommendation engine.
 	TaskName string `yaml:"task_name" validate:"required,isValidTaskName"`
 	// Rules indicates list of rules to evaluate the criteria for the recomm+endation engine.
-	Rules []Rule `yaml:"rules" validate:"gt=0,dive"`
+	Rules []Rule `yaml:"rules,omitempty" validate:"required_without=Groups,omitempty,dive"`
+	// Groups indicates list of nested rule groups which are evaluated along with the rules using the operator.
+	// It is applicable only when the operator is AND or OR.
+	Groups []RuleGroup `yaml:"groups,omitempty" validate:"omitempty,dive"`
 	// Operator indicates the logical operation needs to be performed while executing the rules
-	Operator string `yaml:"operator" validate:"required,oneof=AND OR EVENT"`
+	// It is not applicable for the target_tracking task.
+	Operator string `yaml:"operator" validate:"required_unless=TaskName target_tracking,omitempty,oneof=AND OR EVENT"`
+	// CooldownAfterScaleUp indicates the time in minutes after a scale up during which the task is not provisioned.
+	// The cooldown applies after a failed scale up as well.
+	// If neither of the cooldowns is specified, the largest decision period of the rules is used for both the cooldowns.
+	CooldownAfterScaleUp int `yaml:"cooldown_after_scale_up,omitempty" validate:"min=0"`
+	// CooldownAfterScaleDown indicates the time in minutes after a scale down during which the task is not provisioned.
+	// The cooldown applies after a failed scale down as well.
+	CooldownAfterScaleDown int `yaml:"cooldown_after_scale_down,omitempty" validate:"min=0"`
+	// Priority indicates the priority of the task when more than one task is recommended. The task with the highest priority is provisioned.
+	// The default priority is 2 for the scale_up tasks and 1 for the scale_down tasks, so that a scale up is preferred.
+	Priority int `yaml:"priority,omitempty" validate:"min=0"`
+	// StabilizationWindow indicates the time in minutes for which the task needs to be recommended continuously before it is provisioned.
+	StabilizationWindow int `yaml:"stabilization_window,omitempty" validate:"min=0"`
+}
+
+// This struct contains a group of rules and nested groups combined with an operator.
+// Example: (CpuUtil COUNT > 80 OR HeapUtil COUNT > 85) AND DiskUtil AVG > 50 is a task with the operator AND,
+// the DiskUtil rule and a group with the operator OR and the CpuUtil and HeapUtil rules.
+type RuleGroup struct {
+	// Operator indicates the logical operation performed over the rules and the groups. These can be AND, OR.
+	Operator string `yaml:"operator" validate:"required,oneof=AND OR"`
+	// Rules indicates list of rules of the group.
+	Rules []Rule `yaml:"rules,omitempty" validate:"required_without=Groups,omitempty,dive"`
+	// Groups indicates list of nested groups of the group.
+	Groups []RuleGroup `yaml:"groups,omitempty" validate:"omitempty,dive"`
 }
 
 // This struct contains the rule.
@@ -103,8 +240,20 @@ type Rule struct {
 	//              Avg: The average CPU or MEM value will be calculated for a given decision period.
 	//              Count: The number of occurences where CPU or MEM value crossed the threshold limit.
 	//              Term:
+	//              Max, Min: The maximum or minimum value of the metric for a given decision period.
+	//              P90, P95, P99: The 90th, 95th or 99th percentile of the metric for a given decision period.
+	//              Trend: The change of the metric over the decision period, calculated from the slope of the metric.
+	//              Rate: The change of the metric per minute, calculated from the slope of the metric.
+	//              Forecast: The forecasted value of the metric for the next decision period.
 	// For rule: Shard, the stat will not be applicable as the shard will be calculated across the cluster and is not a statistical value.
 	Stat string `yaml:"stat,omitempty"`
+	// Scope indicates on what the rule is evaluated. These can be:
+	//              cluster: The rule is evaluated on the metric aggregated over all the nodes. This is the default.
+	//              any_node: The rule is evaluated on every node and is met if it is met on any of the nodes.
+	//              all_nodes: The rule is evaluated on every node and is met if it is met on all the nodes.
+	//              data_nodes: The rule is evaluated on every data node. It is met for scale_up if it is met on any of the
+	//                          data nodes and for scale_down if it is met on all the data nodes.
+	Scope string `yaml:"scope,omitempty" validate:"omitempty,oneof=cluster any_node all_nodes data_nodes"`
 	// DecisionPeriod indicates the time in minutes for which a rule is evalated.
 	DecisionPeriod int `yaml:"decision_period,omitempty"`
 	// Occurrences indicate the number of time a rule reached the threshold limit for a give decision period.
@@ -115,6 +264,14 @@ type Rule struct {
 	// SchedulingTime = "30 5 * * 1-5"
 	// In the above example the cron job will run at 5:30 AM from Mon-Fri of every month
 	SchedulingTime string `yaml:"scheduling_time,omitempty"`
+	// ForecastModel indicates the model used to forecast the metric when the Stat is set to FORECAST. These can be:
+	//              linear: A linear trend fitted over the history.
+	//              seasonal: A daily seasonal model that uses the value at the same time of the previous days.
+	ForecastModel string `yaml:"forecast_model,omitempty"`
+	// HistoryDays indicates the number of days of NodeStatistics used to forecast the metric when the Stat is set to FORECAST.
+	HistoryDays int `yaml:"history_days,omitempty"`
+	// TargetValue indicates the value of the metric the target_tracking task tries to maintain by adding or removing nodes.
+	TargetValue float32 `yaml:"target_value,omitempty"`
 	// NumNodesRequired specifies the integer value of number of nodes to be present in cluster for event based scaling operations
 	// To be implemented.
 	// NumNodesRequired int `yaml:"num_nodes_required"`
@@ -124,13 +281,27 @@ type Rule struct {
 type TaskDetails struct {
 	// Tasks indicates list of task.
 	// A task indicates what operation needs to be recommended by recommendation engine.
-	// As of now tasks can be of two types:
+	// As of now tasks can be of three types:
 	//
-	//      scale_up_by_1
-	//      scale_down_by_1
+	//      scale_up_by_N
+	//      scale_down_by_N
+	//      target_tracking: Computes the number of nodes to be added or removed to keep the metric at the target value
 	Tasks []Task `yaml:"action" validate:"gt=0,dive"`
 }
 
+// Inputs:
+//
+// Description:
+//
+//	This function will be parsing the configuration file (ConfigFileName) and populate the ConfigStruct.
+//
+// Return:
+//
+//	(ConfigStruct, error): Return the configuration and the error if the file could not be read, parsed or validated.
+func GetConfig() (ConfigStruct, error) {
+	return ReadConfig(ConfigFileName)
+}
+
 // Inputs:
 //
 //	path (string): The path of the configuration file.
@@ -138,19 +309,22 @@ type TaskDetails struct {
 // Description:
 //
 //	This function will be parsing the provided configuration file and populate the ConfigStruct.
-func GetConfig() (ConfigStruct, error) {
-	yamlConfig, err := os.Open(ConfigFileName)
+//	Only the tag and struct level validations are run, ValidateConfigFile reports the semantic issues as well.
+//
+// Return:
+//
+//	(ConfigStruct, error): Return the configuration and the error if the file could not be read, parsed or validated.
+func ReadConfig(path string) (ConfigStruct, error) {
+	var config = new(ConfigStruct)
+	configByte, err := os.ReadFile(path)
 	if err != nil {
-		log.Panic.Println("Unable to read the config file: ", err)
-		panic(err)
+		log.Error.Println("Unable to read the config file: ", err)
+		return *config, err
 	}
-	defer yamlConfig.Close()
-	configByte, _ := ioutil.ReadAll(yamlConfig)
-	var config = new(ConfigStruct)
 	err = yaml.Unmarshal(configByte, &config)
 	if err != nil {
-		log.Panic.Println("Unmarshal Error : ", err)
-		panic(err)
+		log.Error.Println("Unmarshal Error : ", err)
+		return *config, err
 	}
 	err = validation(*config)
 	return *config, err
@@ -169,9 +343,19 @@ func GetConfig() (ConfigStruct, error) {
 //	(error): Return the error if there is a validation error.
 func validation(config ConfigStruct) error {
 	validate := validator.New()
+	// Name the fields as in the configuration file, Ex: task_details[0].rules[1].limit
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("yaml"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
 	validate.RegisterValidation("isValidName", isValidName)
 	validate.RegisterValidation("isValidTaskName", isValidTaskName)
+	validate.RegisterStructValidation(TaskStructLevelValidation, Task{})
 	validate.RegisterStructValidation(RuleStructLevelValidation, Rule{})
+	validate.RegisterStructValidation(ClusterDetailsStructLevelValidation, ClusterDetails{})
 	err := validate.Struct(config)
 	return err
 }
@@ -206,7 +390,7 @@ func isValidName(fl validator.FieldLevel) bool {
 //
 //	(bool): Return true if there is a valid Task name else false.
 func isValidTaskName(fl validator.FieldLevel) bool {
-	TaskNameRegexString := `scale_(up|down)_by_[0-9]+`
+	TaskNameRegexString := `scale_(up|down)_by_[0-9]+|target_tracking`
 	TaskNameRegex := regexp.MustCompile(TaskNameRegexString)
 
 	return TaskNameRegex.MatchString(fl.Field().String())
@@ -224,32 +408,65 @@ func isValidTaskName(fl validator.FieldLevel) bool {
 // Return:
 func RuleStructLevelValidation(sl validator.StructLevel) {
 
-	tasks := sl.Parent().Interface().(Task)
+	// The rule is either present directly in the task or inside a rule group
+	var tasks Task
+	switch parent := sl.Parent().Interface().(type) {
+	case Task:
+		tasks = parent
+	case RuleGroup:
+		tasks.Operator = parent.Operator
+	}
 	rule := sl.Current().Interface().(Rule)
 
-	if tasks.Operator == "AND" || tasks.Operator == "OR" {
+	if tasks.TaskName == "target_tracking" {
+		if rule.Metric != "CpuUtil" && rule.Metric != "HeapUtil" && rule.Metric != "DiskUtil" && rule.Metric != "ShardsPerGB" {
+			sl.ReportError(rule.Metric, "metric", "Metric", "OneOf", "")
+		}
+		if rule.TargetValue <= 0 {
+			sl.ReportError(rule.TargetValue, "target_value", "TargetValue", "required", "")
+		}
+		if rule.Stat != "" && rule.Stat != "AVG" {
+			sl.ReportError(rule.Stat, "stat", "Stat", "OneOf", "")
+		}
+		if rule.Scope != "" && rule.Scope != "cluster" {
+			sl.ReportError(rule.Scope, "scope", "Scope", "OneOf", "")
+		}
+		if rule.DecisionPeriod < 60 {
+			sl.ReportError(rule.DecisionPeriod, "decision_period", "DecisionPeriod", "required,min", "")
+		}
+	} else if tasks.Operator == "AND" || tasks.Operator == "OR" {
 		if rule.Stat != "COUNT" && rule.Occurrences > 0 {
-			sl.ReportError(rule.Stat, "occurrences", "Occurrences", "excluded_unless", "")
+			sl.ReportError(rule.Occurrences, "occurrences_percent", "Occurrences", "excluded_unless", "")
 		}
-		if rule.Metric != "CpuUtil" && rule.Metric != "RamUtil" && rule.Metric != "DiskUtil" &&
-			rule.Metric != "HeapUtil" && rule.Metric != "NumShards" && rule.Metric != "ShardsPerGB" {
+		if !isValidMetric(rule.Metric) {
 			sl.ReportError(rule.Metric, "metric", "Metric", "OneOf", "")
 		}
 		if rule.Limit <= 0 {
-			sl.ReportError(rule.Limit, "Limit", "Limit", "required", "")
+			sl.ReportError(rule.Limit, "limit", "Limit", "required", "")
 		}
-		if rule.Stat != "AVG" && rule.Stat != "COUNT" && rule.Stat != "TERM" {
-			sl.ReportError(rule.Stat, "Stat", "Stat", "OneOf", "")
+		if !isValidStat(rule.Stat) {
+			sl.ReportError(rule.Stat, "stat", "Stat", "OneOf", "")
+		}
+		if rule.Scope != "" && rule.Scope != "cluster" && !isValidNodeScopeStat(rule.Stat) {
+			sl.ReportError(rule.Scope, "scope", "Scope", "OneOf", "")
+		}
+		if rule.Stat == "FORECAST" {
+			if rule.ForecastModel != "linear" && rule.ForecastModel != "seasonal" {
+				sl.ReportError(rule.ForecastModel, "forecast_model", "ForecastModel", "OneOf", "")
+			}
+			if rule.HistoryDays < 1 || rule.ForecastModel == "seasonal" && rule.HistoryDays < 2 {
+				sl.ReportError(rule.HistoryDays, "history_days", "HistoryDays", "required,min", "")
+			}
 		}
 		if rule.DecisionPeriod < 60 {
-			sl.ReportError(rule.DecisionPeriod, "DecisionPeriod", "DecisionPeriod", "required,min", "")
+			sl.ReportError(rule.DecisionPeriod, "decision_period", "DecisionPeriod", "required,min", "")
 		}
 		if rule.Stat == "COUNT" && rule.Occurrences > 100 {
-			sl.ReportError(rule.Occurrences, "Occurrences", "Occurrences", "required,max", "")
+			sl.ReportError(rule.Occurrences, "occurrences_percent", "Occurrences", "required,max", "")
 		}
 	} else if tasks.Operator == "EVENT" {
 		if rule.SchedulingTime == "" {
-			sl.ReportError(rule.SchedulingTime, "SchedulingTime", "scheduling_time", "required", "")
+			sl.ReportError(rule.SchedulingTime, "scheduling_time", "SchedulingTime", "required", "")
 		}
 		// if rule.NumNodesRequired <= 0 {
 		//      sl.ReportError(rule.NumNodesRequired, "NumNodesRequired", "number_of_node", "required", "")
@@ -257,6 +474,177 @@ func RuleStructLevelValidation(sl validator.StructLevel) {
 	}
 }
 
+// Inputs:
+//
+//	sl (validator.StructLevel): The StructLevel of the Task which needs to be validated.
+//
+// Description:
+//
+//	This function will be validating the Task struct.
+//	The rule groups are only applicable for the metric based tasks with the operator AND or OR.
+//	The cooldowns and the stabilization window are not applicable for the event based tasks.
+//	The target_tracking task is metric based, so it can not be an EVENT task.
+//
+// Return:
+func TaskStructLevelValidation(sl validator.StructLevel) {
+	task := sl.Current().Interface().(Task)
+
+	if task.TaskName == "target_tracking" && task.Operator != "" && task.Operator != "AND" && task.Operator != "OR" {
+		sl.ReportError(task.Operator, "operator", "Operator", "excluded_unless", "")
+	}
+
+	if len(task.Groups) > 0 && task.Operator != "AND" && task.Operator != "OR" {
+		sl.ReportError(task.Groups, "groups", "Groups", "excluded_unless", "")
+	}
+	if task.Operator == "EVENT" {
+		if task.CooldownAfterScaleUp > 0 {
+			sl.ReportError(task.CooldownAfterScaleUp, "cooldown_after_scale_up", "CooldownAfterScaleUp", "excluded_unless", "")
+		}
+		if task.CooldownAfterScaleDown > 0 {
+			sl.ReportError(task.CooldownAfterScaleDown, "cooldown_after_scale_down", "CooldownAfterScaleDown", "excluded_unless", "")
+		}
+		if task.StabilizationWindow > 0 {
+			sl.ReportError(task.StabilizationWindow, "stabilization_window", "StabilizationWindow", "excluded_unless", "")
+		}
+	}
+}
+
+// Inputs:
+//
+//	metric (string): The metric of a rule.
+//
+// Description:
+//
+//	This function checks if the metric is one of the metrics present in the NodeStatistics documents.
+//
+// Return:
+//
+//	(bool): Return true if the metric is supported.
+func isValidMetric(metric string) bool {
+	for _, metricName := range cluster.HistoricMetrics {
+		if metric == metricName {
+			return true
+		}
+	}
+	return false
+}
+
+// Inputs:
+//
+//	stat (string): The stat of a rule.
+//
+// Description:
+//
+//	This function checks if the stat is one of the stats supported for the metric based tasks.
+//
+// Return:
+//
+//	(bool): Return true if the stat is supported.
+func isValidStat(stat string) bool {
+	switch stat {
+	case "AVG", "COUNT", "TERM", "MAX", "MIN", "P90", "P95", "P99", "TREND", "RATE", "FORECAST":
+		return true
+	}
+	return false
+}
+
+// Inputs:
+//
+//	stat (string): The stat of a rule.
+//
+// Description:
+//
+//	This function checks if the stat can be evaluated on every node when the scope of the rule is not cluster.
+//
+// Return:
+//
+//	(bool): Return true if the stat can be evaluated on every node.
+func isValidNodeScopeStat(stat string) bool {
+	switch stat {
+	case "AVG", "COUNT", "MAX", "MIN", "P90", "P95", "P99":
+		return true
+	}
+	return false
+}
+
+// Inputs:
+//
+//	fl (validator.StructLevel): The field of StructLevel needs to be validated.
+//
+// Description:
+//
+//	This function will be validating the ClusterDetails struct.
+//	The launch details and cloud credentials that are required depends on the cloud type.
+//	It will be Reporting Error when the validation for a field fails.
+//
+// Return:
+func ClusterDetailsStructLevelValidation(sl validator.StructLevel) {
+	clusterDetails := sl.Current().Interface().(ClusterDetails)
+	cred := clusterDetails.CloudCredentials
+
+	if clusterDetails.CloudType != "LOCAL" && cred.PemFilePath == "" {
+		sl.ReportError(cred.PemFilePath, "cloud_credentials.pem_file_path", "PemFilePath", "required", "")
+	}
+
+	switch clusterDetails.CloudType {
+	case "AWS":
+		if clusterDetails.LaunchTemplateId == "" {
+			sl.ReportError(clusterDetails.LaunchTemplateId, "launch_template_id", "LaunchTemplateId", "required", "")
+		}
+		if clusterDetails.LaunchTemplateVersion == "" {
+			sl.ReportError(clusterDetails.LaunchTemplateVersion, "launch_template_version", "LaunchTemplateVersion", "required", "")
+		}
+		if cred.Region == "" {
+			sl.ReportError(cred.Region, "cloud_credentials.region", "Region", "required", "")
+		}
+		if cred.RoleArn == "" && cred.SecretKey == "" {
+			sl.ReportError(cred.SecretKey, "cloud_credentials.secret_key", "SecretKey", "required_without", "RoleArn")
+		}
+		if cred.RoleArn == "" && cred.AccessKey == "" {
+			sl.ReportError(cred.AccessKey, "cloud_credentials.access_key", "AccessKey", "required_without", "RoleArn")
+		}
+	case "GCP":
+		if clusterDetails.LaunchTemplateId == "" {
+			sl.ReportError(clusterDetails.LaunchTemplateId, "launch_template_id", "LaunchTemplateId", "required", "")
+		}
+		if cred.ProjectId == "" {
+			sl.ReportError(cred.ProjectId, "cloud_credentials.project_id", "ProjectId", "required", "")
+		}
+		if cred.Zone == "" {
+			sl.ReportError(cred.Zone, "cloud_credentials.zone", "Zone", "required", "")
+		}
+	case "AZURE":
+		if cred.SubscriptionId == "" {
+			sl.ReportError(cred.SubscriptionId, "cloud_credentials.subscription_id", "SubscriptionId", "required", "")
+		}
+		if cred.ResourceGroup == "" {
+			sl.ReportError(cred.ResourceGroup, "cloud_credentials.resource_group", "ResourceGroup", "required", "")
+		}
+		if cred.Region == "" {
+			sl.ReportError(cred.Region, "cloud_credentials.region", "Region", "required", "")
+		}
+		if cred.ClientId != "" && (cred.TenantId == "" || cred.ClientSecret == "") {
+			sl.ReportError(cred.ClientSecret, "cloud_credentials.client_secret", "ClientSecret", "required_with", "ClientId")
+		}
+		if clusterDetails.AzureVmConfig.VmSize == "" {
+			sl.ReportError(clusterDetails.AzureVmConfig.VmSize, "azure_vm_config.vm_size", "VmSize", "required", "")
+		}
+		if clusterDetails.AzureVmConfig.ImageId == "" {
+			sl.ReportError(clusterDetails.AzureVmConfig.ImageId, "azure_vm_config.image_id", "ImageId", "required", "")
+		}
+		if clusterDetails.AzureVmConfig.SubnetId == "" {
+			sl.ReportError(clusterDetails.AzureVmConfig.SubnetId, "azure_vm_config.subnet_id", "SubnetId", "required", "")
+		}
+	case "LOCAL":
+		if clusterDetails.LocalConfig.Mode == "" {
+			sl.ReportError(clusterDetails.LocalConfig.Mode, "local_config.mode", "Mode", "required", "")
+		}
+		if clusterDetails.LocalConfig.WorkDir == "" {
+			sl.ReportError(clusterDetails.LocalConfig.WorkDir, "local_config.work_dir", "WorkDir", "required", "")
+		}
+	}
+}
+
 // Inputs:
 //
 //	conf (ConfigStruct) : Credentials encrypted structure of the config.yaml file
@@ -343,7 +343,7 @@ func TestRuleOccurences(t *testing.T) {
 }
 
 func TestConfig(t *testing.T) {
-	config, err := GetConfig("../config.yaml")
+	config, err := ReadConfig("../config.yaml")
 	if err != nil {
 		t.Fail()
 		t.Logf("expected validation got %v", err)
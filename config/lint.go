@@ -0,0 +1,418 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/robfig/cron/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// The heap above which the JVM can no longer use the compressed object pointers.
+const maxHeapInGB = 32
+
+// The RAM in GB of the Azure virtual machine sizes, used to check the heap of the nodes when the vm_size is known.
+var azureVmRamInGB = map[string]float64{
+	"Standard_D2s_v3": 8, "Standard_D4s_v3": 16, "Standard_D8s_v3": 32, "Standard_D16s_v3": 64, "Standard_D32s_v3": 128, "Standard_D48s_v3": 192, "Standard_D64s_v3": 256,
+	"Standard_D2s_v4": 8, "Standard_D4s_v4": 16, "Standard_D8s_v4": 32, "Standard_D16s_v4": 64, "Standard_D32s_v4": 128, "Standard_D48s_v4": 192, "Standard_D64s_v4": 256,
+	"Standard_D2s_v5": 8, "Standard_D4s_v5": 16, "Standard_D8s_v5": 32, "Standard_D16s_v5": 64, "Standard_D32s_v5": 128, "Standard_D48s_v5": 192, "Standard_D64s_v5": 256, "Standard_D96s_v5": 384,
+	"Standard_E2s_v3": 16, "Standard_E4s_v3": 32, "Standard_E8s_v3": 64, "Standard_E16s_v3": 128, "Standard_E32s_v3": 256, "Standard_E48s_v3": 384, "Standard_E64s_v3": 432,
+	"Standard_E2s_v4": 16, "Standard_E4s_v4": 32, "Standard_E8s_v4": 64, "Standard_E16s_v4": 128, "Standard_E32s_v4": 256, "Standard_E48s_v4": 384, "Standard_E64s_v4": 504,
+	"Standard_E2s_v5": 16, "Standard_E4s_v5": 32, "Standard_E8s_v5": 64, "Standard_E16s_v5": 128, "Standard_E32s_v5": 256, "Standard_E48s_v5": 384, "Standard_E64s_v5": 512, "Standard_E96s_v5": 672,
+	"Standard_F2s_v2": 4, "Standard_F4s_v2": 8, "Standard_F8s_v2": 16, "Standard_F16s_v2": 32, "Standard_F32s_v2": 64, "Standard_F48s_v2": 96, "Standard_F64s_v2": 128, "Standard_F72s_v2": 144,
+}
+
+// The line reported by the yaml parser in its errors. Ex: yaml: line 12: mapping values are not allowed in this context
+var yamlErrorLineRegex = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// This struct contains an issue found in the configuration file along with where it is present in the file.
+type ConfigIssue struct {
+	// Line and Column indicate the position of the field in the file. These are 0 if the position is not known.
+	Line   int
+	Column int
+	// Field indicates the path of the field. Ex: task_details[0].rules[1].decision_period
+	Field string
+	// Message indicates what is wrong with the field
+	Message string
+}
+
+// Inputs:
+//
+//	path (string): The path of the configuration file.
+//
+// Caller:
+//
+//	Object of type ConfigIssue
+//
+// Description:
+//
+//	Formats the issue as path:line:column: field: message, as reported by the compilers.
+//
+// Return:
+//
+//	(string): Return the formatted issue.
+func (i ConfigIssue) Format(path string) string {
+	position := path
+	if i.Line > 0 {
+		position += ":" + strconv.Itoa(i.Line)
+		if i.Column > 0 {
+			position += ":" + strconv.Itoa(i.Column)
+		}
+	}
+	if i.Field == "" {
+		return fmt.Sprintf("%s: %s", position, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", position, i.Field, i.Message)
+}
+
+// Inputs:
+//
+//	path (string): The path of the configuration file.
+//
+// Description:
+//
+//	This function reports every issue of the configuration file along with its line and column, rather than the first one.
+//	The issues are the syntax errors, the values of the wrong type, the unknown fields, the failed validations
+//	and the semantic issues which GetConfig does not check, Ex: the scale_up and scale_down tasks which could flap.
+//
+// Return:
+//
+//	([]ConfigIssue, error): Return the issues ordered by their position and the error if the file could not be read.
+func ValidateConfigFile(path string) ([]ConfigIssue, error) {
+	configByte, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(configByte, &root); err != nil {
+		return []ConfigIssue{yamlErrorIssue(err.Error())}, nil
+	}
+	if len(root.Content) == 0 {
+		return []ConfigIssue{{Message: "the configuration file is empty"}}, nil
+	}
+
+	var issues []ConfigIssue
+	var config ConfigStruct
+	decoder := yaml.NewDecoder(bytes.NewReader(configByte))
+	decoder.KnownFields(true)
+	err = decoder.Decode(&config)
+	var typeErr *yaml.TypeError
+	switch {
+	case errors.As(err, &typeErr):
+		// The fields which could be decoded are still validated
+		for _, typeErrMsg := range typeErr.Errors {
+			issues = append(issues, yamlErrorIssue(typeErrMsg))
+		}
+	case err != nil && err != io.EOF:
+		return []ConfigIssue{yamlErrorIssue(err.Error())}, nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	if err := validation(config); errors.As(err, &validationErrs) {
+		for _, fieldErr := range validationErrs {
+			field := fieldErr.Namespace()
+			// Drop the name of the ConfigStruct
+			if index := strings.Index(field, "."); index >= 0 {
+				field = field[index+1:]
+			}
+			issues = append(issues, ConfigIssue{Field: field, Message: describeFieldError(fieldErr)})
+		}
+	} else if err != nil {
+		issues = append(issues, ConfigIssue{Message: err.Error()})
+	}
+	issues = append(issues, lintConfig(config)...)
+
+	for i := range issues {
+		if issues[i].Line == 0 && issues[i].Field != "" {
+			issues[i].Line, issues[i].Column = findField(root.Content[0], issues[i].Field)
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Line != issues[j].Line {
+			return issues[i].Line < issues[j].Line
+		}
+		return issues[i].Column < issues[j].Column
+	})
+	return issues, nil
+}
+
+// Inputs:
+//
+//	msg (string): The error reported by the yaml parser.
+//
+// Description:
+//
+//	Converts an error of the yaml parser to an issue, taking the line from the error.
+//
+// Return:
+//
+//	(ConfigIssue): Return the issue.
+func yamlErrorIssue(msg string) ConfigIssue {
+	match := yamlErrorLineRegex.FindStringSubmatch(msg)
+	if match == nil {
+		return ConfigIssue{Message: strings.TrimPrefix(msg, "yaml: ")}
+	}
+	line, _ := strconv.Atoi(match[1])
+	return ConfigIssue{Line: line, Message: match[2]}
+}
+
+// Inputs:
+//
+//	fieldErr (validator.FieldError): The failed validation of a field.
+//
+// Description:
+//
+//	Describes the failed validation of a field. The struct level validations report their rule, Ex: required,min, as the tag.
+//
+// Return:
+//
+//	(string): Return the description.
+func describeFieldError(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	switch strings.ToLower(fieldErr.Tag()) {
+	case "required":
+		return "is required"
+	case "required,min":
+		return "is required and is below the minimum"
+	case "required,max":
+		return "is required and is above the maximum"
+	case "min":
+		return fmt.Sprintf("needs to be at least %s", param)
+	case "max":
+		return fmt.Sprintf("needs to be at most %s", param)
+	case "gt":
+		return fmt.Sprintf("needs more than %s entries", param)
+	case "oneof":
+		if param == "" {
+			return fmt.Sprintf("%v is not supported", fieldErr.Value())
+		}
+		return fmt.Sprintf("needs to be one of %s", param)
+	case "excluded_unless":
+		return "is not applicable here"
+	case "required_without", "required_with":
+		return fmt.Sprintf("is required with %s", param)
+	case "required_if":
+		return fmt.Sprintf("is required when %s", param)
+	case "url":
+		return "is not a valid URL"
+	case "required_unless":
+		return fmt.Sprintf("is required unless %s", param)
+	case "isvalidname", "isvalidtaskname":
+		return fmt.Sprintf("%v is not a valid name", fieldErr.Value())
+	}
+	if param != "" {
+		return fmt.Sprintf("failed the %s=%s validation", fieldErr.Tag(), param)
+	}
+	return fmt.Sprintf("failed the %s validation", fieldErr.Tag())
+}
+
+// Inputs:
+//
+//	config (ConfigStruct): config structure populated with unmarshalled data.
+//
+// Description:
+//
+//	This function checks the configuration for the issues which pass the validation of the fields but are wrong in combination:
+//	min_nodes_allowed above max_nodes_allowed, decision periods which are not a multiple of the fetchmetrics polling interval,
+//	scale_up and scale_down rules on the same metric and stat whose limits overlap so that the tasks could flap,
+//	scheduling times which are not valid cron expressions and a heap above 32GB for the known AZURE vm sizes, the heap is not checked for the other clouds.
+//
+// Return:
+//
+//	([]ConfigIssue): Return the issues, without their positions.
+func lintConfig(config ConfigStruct) []ConfigIssue {
+	var issues []ConfigIssue
+	clusterDetails := config.ClusterDetails
+
+	if clusterDetails.MinNodesAllowed > 0 && clusterDetails.MaxNodesAllowed > 0 && clusterDetails.MinNodesAllowed > clusterDetails.MaxNodesAllowed {
+		issues = append(issues, ConfigIssue{
+			Field:   "cluster_details.min_nodes_allowed",
+			Message: fmt.Sprintf("%d is above max_nodes_allowed %d", clusterDetails.MinNodesAllowed, clusterDetails.MaxNodesAllowed),
+		})
+	}
+
+	// The RAM of the nodes is known only from the vm_size of AZURE. The heap is not checked for AWS and GCP, where the instance type
+	// is part of the launch or instance template, for LOCAL where the heap_size is set directly, nor for the vm sizes not listed.
+	if ramInGB, ok := azureVmRamInGB[clusterDetails.AzureVmConfig.VmSize]; ok && clusterDetails.CloudType == "AZURE" {
+		if heapInGB := clusterDetails.JvmFactor * ramInGB; heapInGB > maxHeapInGB {
+			issues = append(issues, ConfigIssue{
+				Field: "cluster_details.jvm_factor",
+				Message: fmt.Sprintf("%v of the %vGB RAM of %s is a heap of %vGB, which is above %dGB", clusterDetails.JvmFactor, ramInGB,
+					clusterDetails.AzureVmConfig.VmSize, heapInGB, maxHeapInGB),
+			})
+		}
+	}
+
+	var scaleUpRules, scaleDownRules []lintRule
+	for i, task := range config.TaskDetails {
+		taskField := fmt.Sprintf("task_details[%d]", i)
+		if task.Operator == "EVENT" {
+			for j, rule := range task.Rules {
+				if rule.SchedulingTime == "" {
+					continue
+				}
+				if _, err := cron.ParseStandard(rule.SchedulingTime); err != nil {
+					issues = append(issues, ConfigIssue{
+						Field:   fmt.Sprintf("%s.rules[%d].scheduling_time", taskField, j),
+						Message: fmt.Sprintf("%q is not a valid cron expression: %s", rule.SchedulingTime, err),
+					})
+				}
+			}
+			continue
+		}
+
+		rules := collectRules(taskField, task.Rules, task.Groups)
+		for _, rule := range rules {
+			pollingInterval := config.UserConfig.FetchPollingInterval
+			if rule.DecisionPeriod > 0 && pollingInterval > 0 && rule.DecisionPeriod*60%pollingInterval != 0 {
+				issues = append(issues, ConfigIssue{
+					Field:   rule.field + ".decision_period",
+					Message: fmt.Sprintf("%d minutes is not a multiple of fetchmetrics_polling_interval_in_secs %d", rule.DecisionPeriod, pollingInterval),
+				})
+			}
+		}
+		switch {
+		case strings.HasPrefix(task.TaskName, "scale_up_by_"):
+			scaleUpRules = append(scaleUpRules, rules...)
+		case strings.HasPrefix(task.TaskName, "scale_down_by_"):
+			scaleDownRules = append(scaleDownRules, rules...)
+		}
+	}
+
+	// A scale_up rule is met above its limit and a scale_down rule below its limit, so the tasks could flap
+	// if a value is both above the scale_up limit and below the scale_down limit.
+	// The TREND and RATE rules are met when the metric climbs or falls by the limit, so they never overlap.
+	for _, scaleDownRule := range scaleDownRules {
+		if scaleDownRule.Stat == "TREND" || scaleDownRule.Stat == "RATE" {
+			continue
+		}
+		for _, scaleUpRule := range scaleUpRules {
+			if scaleUpRule.Metric != scaleDownRule.Metric || scaleUpRule.Stat != scaleDownRule.Stat || scaleDownRule.Limit < scaleUpRule.Limit {
+				continue
+			}
+			issues = append(issues, ConfigIssue{
+				Field: scaleDownRule.field + ".limit",
+				Message: fmt.Sprintf("the scale_down limit %v of %s %s is not below the scale_up limit %v of %s, the tasks could flap",
+					scaleDownRule.Limit, scaleDownRule.Metric, scaleDownRule.Stat, scaleUpRule.Limit, scaleUpRule.field),
+			})
+		}
+	}
+	return issues
+}
+
+// This struct contains a rule of a metric based task along with the path of the rule in the configuration file.
+type lintRule struct {
+	Rule
+	field string
+}
+
+// Inputs:
+//
+//	field (string): The path of the task or the group.
+//	rules ([]Rule): The rules of the task or the group.
+//	groups ([]RuleGroup): The nested groups of the task or the group.
+//
+// Description:
+//
+//	Collects the rules of a task, including the rules of the nested groups.
+//
+// Return:
+//
+//	([]lintRule): Return the rules along with their paths.
+func collectRules(field string, rules []Rule, groups []RuleGroup) []lintRule {
+	var lintRules []lintRule
+	for i, rule := range rules {
+		lintRules = append(lintRules, lintRule{Rule: rule, field: fmt.Sprintf("%s.rules[%d]", field, i)})
+	}
+	for i, group := range groups {
+		lintRules = append(lintRules, collectRules(fmt.Sprintf("%s.groups[%d]", field, i), group.Rules, group.Groups)...)
+	}
+	return lintRules
+}
+
+// Inputs:
+//
+//	node (*yaml.Node): The root mapping of the configuration file.
+//	field (string): The path of the field. Ex: task_details[0].rules[1].decision_period
+//
+// Description:
+//
+//	Finds the position of the field in the configuration file. The names are matched ignoring the case and the underscores,
+//	as the tag name of a field without a yaml name is the name of the struct field. A name which is not present in the mapping
+//	is skipped, Ex: ClusterStatic which is inlined in the cluster_details.
+//	If the field is missing, the position of the closest parent present in the file is returned.
+//
+// Return:
+//
+//	(int, int): Return the line and the column.
+func findField(node *yaml.Node, field string) (int, int) {
+	line, column := node.Line, node.Column
+	for _, segment := range strings.Split(field, ".") {
+		name, index := segment, ""
+		if open := strings.Index(segment, "["); open >= 0 && strings.HasSuffix(segment, "]") {
+			name, index = segment[:open], segment[open+1:len(segment)-1]
+		}
+		key, value := findKey(node, name)
+		if value == nil {
+			continue
+		}
+		line, column = key.Line, key.Column
+		node = value
+		if index == "" {
+			continue
+		}
+		switch node.Kind {
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(index)
+			if err != nil || i < 0 || i >= len(node.Content) {
+				return line, column
+			}
+			node = node.Content[i]
+			line, column = node.Line, node.Column
+		case yaml.MappingNode:
+			key, value := findKey(node, index)
+			if value == nil {
+				return line, column
+			}
+			line, column = key.Line, key.Column
+			node = value
+		}
+	}
+	return line, column
+}
+
+// Inputs:
+//
+//	node (*yaml.Node): The mapping in which the key is searched.
+//	name (string): The name of the key.
+//
+// Description:
+//
+//	Finds the key in the mapping.
+//
+// Return:
+//
+//	(*yaml.Node, *yaml.Node): Return the key and the value, nil if the key is not found.
+func findKey(node *yaml.Node, name string) (*yaml.Node, *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	normalize := func(s string) string {
+		return strings.ToLower(strings.ReplaceAll(s, "_", ""))
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if normalize(node.Content[i].Value) == normalize(name) {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const lintTestConfig = `user_config:
+    monitor_with_logs: true
+    monitor_with_simulator: false
+    purge_old_docs_after_hours: 72
+    recommendation_polling_interval_in_secs: 300
+    fetchmetrics_polling_interval_in_secs: 300
+cluster_details:
+    cluster_name: cluster.1
+    cloud_type: AWS
+    max_nodes_allowed: 10
+    min_nodes_allowed: 3
+    launch_template_id: lt-000123f47e5c68904
+    launch_template_version: "1"
+    os_user: ubuntu
+    os_group: ubuntu
+    os_version: 2.3.0
+    os_home: /usr/share/opensearch
+    domain_name: snappyflow.com
+    os_credentials:
+        os_admin_username: admin
+        os_admin_password: admin
+    cloud_credentials:
+        pem_file_path: /usr/share/pemfile.pem
+        secret_key: secret_key
+        access_key: access_key
+        region: us-west-2
+    jvm_factor: 0.5
+task_details:
+    - task_name: scale_up_by_1
+      operator: OR
+      rules:
+        - metric: CpuUtil
+          limit: 80
+          stat: AVG
+          decision_period: 60
+    - task_name: scale_down_by_1
+      operator: OR
+      rules:
+        - metric: CpuUtil
+          limit: 30
+          stat: AVG
+          decision_period: 60
+`
+
+// The tests check that the issue is reported at its position, line 0 is any line
+func TestValidateConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     string
+		new     string
+		line    int
+		field   string
+		message string
+	}{
+		{"valid", "", "", 0, "", ""},
+		{"syntax error", "    cluster_name: cluster.1", "    cluster_name: [cluster.1", 0, "", "did not find expected ',' or ']'"},
+		{"unknown field", "    os_group: ubuntu", "    os_group: ubuntu\n    os_grup: ubuntu", 16, "", "field os_grup not found"},
+		{"wrong type", "max_nodes_allowed: 10", "max_nodes_allowed: ten", 10, "", "cannot unmarshal !!str `ten` into int"},
+		{"missing field at its parent", "    os_group: ubuntu\n", "", 7, "cluster_details.os_group", "is required"},
+		{"min above max nodes", "min_nodes_allowed: 3", "min_nodes_allowed: 12", 11, "cluster_details.min_nodes_allowed", "12 is above max_nodes_allowed 10"},
+		{"decision period", "decision_period: 60\n    - task_name: scale_down_by_1", "decision_period: 62\n    - task_name: scale_down_by_1", 35,
+			"task_details[0].rules[0].decision_period", "62 minutes is not a multiple of fetchmetrics_polling_interval_in_secs 300"},
+		{"overlapping limits", "limit: 30", "limit: 85", 40, "task_details[1].rules[0].limit", "the scale_down limit 85 of CpuUtil AVG is not below the scale_up limit 80"},
+		{"limits of different stats", "limit: 30\n          stat: AVG", "limit: 85\n          stat: MAX", 0, "", ""},
+		{"trend not checked", "limit: 30\n          stat: AVG", "limit: 85\n          stat: TREND", 0, "", ""},
+		{"invalid cron", "", "    - task_name: scale_up_by_2\n      operator: EVENT\n      rules:\n        - scheduling_time: 0 0 * *\n", 46,
+			"task_details[2].rules[0].scheduling_time", `"0 0 * *" is not a valid cron expression`},
+		{"nested group", "", "    - task_name: scale_up_by_2\n      operator: AND\n      groups:\n        - operator: OR\n          rules:\n" +
+			"            - metric: RamUtil\n              limit: 80\n              stat: AVG\n              decision_period: 62\n", 51,
+			"task_details[2].groups[0].rules[0].decision_period", "62 minutes is not a multiple of fetchmetrics_polling_interval_in_secs 300"},
+	}
+	for _, test := range tests {
+		content := lintTestConfig + test.new
+		if test.old != "" {
+			content = strings.Replace(lintTestConfig, test.old, test.new, 1)
+		}
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write the config file: %v", err)
+		}
+		issues, err := ValidateConfigFile(path)
+		if err != nil {
+			t.Fatalf("%s: failed to validate the config file: %v", test.name, err)
+		}
+		if test.message == "" {
+			if len(issues) != 0 {
+				t.Errorf("%s: expected no issues got %+v", test.name, issues)
+			}
+			continue
+		}
+		found := false
+		for _, issue := range issues {
+			if issue.Line == 0 {
+				t.Errorf("%s: expected the position of the issue %+v", test.name, issue)
+			}
+			if strings.Contains(issue.Message, test.message) && issue.Field == test.field && (test.line == 0 || issue.Line == test.line) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s: expected %s: %s at line %d got %+v", test.name, test.field, test.message, test.line, issues)
+		}
+	}
+}
+
+func TestLintConfigHeap(t *testing.T) {
+	tests := []struct {
+		name      string
+		cloudType string
+		vmSize    string
+		jvmFactor float64
+		isIssue   bool
+	}{
+		{"azure heap above 32GB", "AZURE", "Standard_E16s_v5", 0.5, true},
+		{"azure heap of 32GB", "AZURE", "Standard_D16s_v3", 0.5, false},
+		{"azure unknown vm size skipped", "AZURE", "Standard_X1", 0.5, false},
+		{"aws skipped", "AWS", "", 0.5, false},
+		{"gcp skipped", "GCP", "", 0.5, false},
+		{"local skipped", "LOCAL", "", 0.5, false},
+	}
+	for _, test := range tests {
+		var config ConfigStruct
+		config.ClusterDetails.CloudType = test.cloudType
+		config.ClusterDetails.AzureVmConfig.VmSize = test.vmSize
+		config.ClusterDetails.JvmFactor = test.jvmFactor
+		issues := lintConfig(config)
+		if isIssue := len(issues) == 1 && issues[0].Field == "cluster_details.jvm_factor"; isIssue != test.isIssue || (!test.isIssue && len(issues) != 0) {
+			t.Errorf("%s: expected an issue %v got %+v", test.name, test.isIssue, issues)
+		}
+	}
+}
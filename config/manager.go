@@ -0,0 +1,181 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// The time for which the events of the configuration file are collected before it is reloaded, as an editor writes the file in more than one event.
+const reloadDelay = time.Second
+
+// This struct holds the last valid configuration read from the configuration file and reloads it when the file changes.
+// An edit which can not be parsed or validated is rejected and the last valid configuration is kept.
+type Manager struct {
+	path        string
+	mutex       sync.RWMutex
+	current     ConfigStruct
+	subscribers []chan ConfigStruct
+}
+
+// Inputs:
+//
+//	path (string): The path of the configuration file.
+//
+// Description:
+//
+//	Creates the manager of the configuration file with the configuration currently present in the file.
+//
+// Return:
+//
+//	(*Manager, error): Return the manager and the error if the configuration could not be read or is not valid.
+func NewManager(path string) (*Manager, error) {
+	config, err := ReadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{path: path, current: config}, nil
+}
+
+// Inputs:
+//
+// Caller:
+//
+//	Object of type Manager
+//
+// Description:
+//
+//	Returns the last valid configuration.
+//
+// Return:
+//
+//	(ConfigStruct): Return the configuration.
+func (m *Manager) Get() ConfigStruct {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.current
+}
+
+// Inputs:
+//
+// Caller:
+//
+//	Object of type Manager
+//
+// Description:
+//
+//	Subscribes to the changes of the configuration. Every new valid configuration is sent on the channel.
+//	A subscriber which has not received the previous configuration receives only the latest one, so a slow subscriber never blocks the reload.
+//
+// Return:
+//
+//	(<-chan ConfigStruct): Return the channel on which the configurations are sent.
+func (m *Manager) Subscribe() <-chan ConfigStruct {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	subscriber := make(chan ConfigStruct, 1)
+	m.subscribers = append(m.subscribers, subscriber)
+	return subscriber
+}
+
+// Inputs:
+//
+// Caller:
+//
+//	Object of type Manager
+//
+// Description:
+//
+//	Reads and validates the configuration file and swaps it in if it changed, notifying the subscribers.
+//	The configuration is validated as a whole before it is swapped in, so the subscribers never see a partially applied edit.
+//	It is checked as by the config validate command, so an edit with any issue, Ex: min_nodes_allowed above max_nodes_allowed, is rejected.
+//
+// Return:
+//
+//	(bool, error): Return true if the configuration changed and the error if the configuration is rejected.
+func (m *Manager) Reload() (bool, error) {
+	issues, err := ValidateConfigFile(m.path)
+	if err != nil {
+		return false, err
+	}
+	if len(issues) > 0 {
+		var messages []string
+		for _, issue := range issues {
+			messages = append(messages, issue.Format(m.path))
+		}
+		return false, fmt.Errorf("%d issue(s) found: %s", len(issues), strings.Join(messages, "; "))
+	}
+	config, err := ReadConfig(m.path)
+	if err != nil {
+		return false, err
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if reflect.DeepEqual(config, m.current) {
+		return false, nil
+	}
+	m.current = config
+	for _, subscriber := range m.subscribers {
+		// Replace the configuration which was not received yet
+		select {
+		case <-subscriber:
+		default:
+		}
+		subscriber <- config
+	}
+	return true, nil
+}
+
+// Inputs:
+//
+// Caller:
+//
+//	Object of type Manager
+//
+// Description:
+//
+//	Watches the directory of the configuration file and reloads the configuration once the events of the file settle for reloadDelay.
+//	The directory is watched rather than the file, as the editors and ansible replace the file by renaming a new one over it.
+//	A rejected configuration is logged and the last valid configuration is kept until the file is fixed.
+//
+// Return:
+func (m *Manager) Watch() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Error.Println("Error while creating new fileWatcher : ", err)
+		return
+	}
+	defer watcher.Close()
+	if err := watcher.Add(filepath.Dir(m.path)); err != nil {
+		log.Error.Println("Error while adding the config file changes to the fileWatcher :", err)
+		return
+	}
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	for {
+		select {
+		case event := <-watcher.Events:
+			if filepath.Clean(event.Name) != filepath.Clean(m.path) || event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			timer.Reset(reloadDelay)
+		case err := <-watcher.Errors:
+			log.Warn.Println("Error in fileWatcher: ", err)
+		case <-timer.C:
+			isChanged, err := m.Reload()
+			if err != nil {
+				log.Error.Println("The changes of the config file are rejected and the last valid config is used: ", err)
+				continue
+			}
+			if isChanged {
+				log.Info.Println("The config file is reloaded")
+			}
+		}
+	}
+}
@@ -1,12 +1,12 @@
 package crypto
 
 import (
-	"context"
 	"crypto/aes"
 	"crypto/cipher"
 	"encoding/base32"
 	ansibleutils "github.com/maplelabs/opensearch-scaling-manager/ansible_scripts"
 	"github.com/maplelabs/opensearch-scaling-manager/config"
+	"github.com/maplelabs/opensearch-scaling-manager/leader"
 	"github.com/maplelabs/opensearch-scaling-manager/logger"
 	osutils "github.com/maplelabs/opensearch-scaling-manager/opensearchUtils"
 	utils "github.com/maplelabs/opensearch-scaling-manager/utilities"
@@ -26,18 +26,43 @@ func init() {
 	log.Init("logger")
 	log.Info.Println("Crypto module initiated")
 	mrand.Seed(seed)
-	configStruct, err := config.GetConfig()
-	if err != nil {
-		log.Error.Println("Error validating config file", err)
-		panic(err)
-	}
-	if _, err = os.Stat(SecretFilepath); err == nil {
+}
+
+// Inputs:
+//
+//	configStruct (config.ConfigStruct): The config read from the config file
+//
+// Description:
+//
+//	Initializes the Opensearch client with the credentials of the config, which are decrypted if the secret is present.
+//	Called by the commands which need to reach Opensearch, rather than when the module is loaded, so that the commands
+//	which only read the config file, Ex: config validate, do not need a valid config file or Opensearch.
+//
+// Return:
+func InitializeOsClient(configStruct config.ConfigStruct) {
+	if _, err := os.Stat(SecretFilepath); err == nil {
 		EncryptionSecret = GetEncryptionSecret()
 		GetDecryptedOsCreds(&configStruct.ClusterDetails.OsCredentials)
-		GetDecryptedCloudCreds(&configStruct.ClusterDetails.CloudCredentials)
 	}
+	osutils.InitializeOsClient(configStruct.ClusterDetails.OsConnection, configStruct.ClusterDetails.OsCredentials.OsAdminUsername, configStruct.ClusterDetails.OsCredentials.OsAdminPassword)
+}
 
-	osutils.InitializeOsClient(configStruct.ClusterDetails.OsCredentials.OsAdminUsername, configStruct.ClusterDetails.OsCredentials.OsAdminPassword)
+// Inputs:
+//
+//	configStruct (config.ConfigStruct): The config read from the config file
+//
+// Description:
+//
+//	Initializes the Opensearch client and, on the leader, generates a new secret and encrypts the credentials of the config file.
+//	Called when the Scaling Manager is started.
+//
+// Return:
+func Initialize(configStruct config.ConfigStruct) {
+	InitializeOsClient(configStruct)
+	if EncryptionSecret != "" {
+		GetDecryptedOsCreds(&configStruct.ClusterDetails.OsCredentials)
+		GetDecryptedCloudCreds(&configStruct.ClusterDetails.CloudCredentials)
+	}
 	UpdateSecretAndEncryptCreds(true, configStruct)
 }
 
@@ -130,6 +155,11 @@ func GetEncryptedCloudCred(cloudCred *config.CloudCredentials) error {
 		return err
 	}
 
+	cloudCred.ClientSecret, err = GetEncryptedData(cloudCred.ClientSecret)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -164,6 +194,11 @@ func GetDecryptedCloudCreds(cloudCred *config.CloudCredentials) {
 		cloudCred.RoleArn = role_arn
 	}
 
+	client_secret := GetDecryptedData(cloudCred.ClientSecret)
+	if client_secret != "" {
+		cloudCred.ClientSecret = client_secret
+	}
+
 }
 
 func UpdateEncryptedCred(initialRun bool, config_struct config.ConfigStruct) error {
@@ -188,46 +223,29 @@ func UpdateEncryptedCred(initialRun bool, config_struct config.ConfigStruct) err
 
 	// initialize new os client connection with the updated creds
 	if !initialRun {
-		osutils.InitializeOsClient(copyCreds.OsAdminUsername, copyCreds.OsAdminPassword)
+		osutils.InitializeOsClient(config_struct.ClusterDetails.OsConnection, copyCreds.OsAdminUsername, copyCreds.OsAdminPassword)
 	}
 	return nil
 }
 
 func DecryptCredsAndInitializeOs(config_struct config.ConfigStruct) {
 	GetDecryptedOsCreds(&config_struct.ClusterDetails.OsCredentials)
-	osutils.InitializeOsClient(config_struct.ClusterDetails.OsCredentials.OsAdminUsername, config_struct.ClusterDetails.OsCredentials.OsAdminPassword)
+	osutils.InitializeOsClient(config_struct.ClusterDetails.OsConnection, config_struct.ClusterDetails.OsCredentials.OsAdminUsername, config_struct.ClusterDetails.OsCredentials.OsAdminPassword)
 }
 
 func UpdateSecretAndEncryptCreds(initial_run bool, config_struct config.ConfigStruct) error {
 	if initial_run {
-		if utils.CheckIfMaster(context.Background(), "") {
+		if leader.IsLeader() {
 			GenerateAndScrambleSecret()
 			UpdateEncryptedCred(initial_run, config_struct)
-			//ansible logic to copy the secret and config
-			hostFileName := "broadcast_hosts"
-			utils.HostsWithCurrentNodes(hostFileName, config_struct.ClusterDetails)
-			err := ansibleutils.UpdateWithTags(hostFileName, config_struct.ClusterDetails, []string{"update_secret", "update_config"})
-			if err != nil {
-				log.Error.Println(err)
-				log.Error.Println("Unable to update config.yaml and .secret.txt on the other node")
-				panic(err)
-			}
+			broadcastSecretAndConfig(config_struct.ClusterDetails)
 		}
 	} else {
 		GetDecryptedOsCreds(&config_struct.ClusterDetails.OsCredentials)
 		GetDecryptedCloudCreds(&config_struct.ClusterDetails.CloudCredentials)
 		GenerateAndScrambleSecret()
 		UpdateEncryptedCred(initial_run, config_struct)
-		//ansible logic to copy the secret and config
-		hostFileName := "broadcast_hosts"
-		utils.HostsWithCurrentNodes(hostFileName, config_struct.ClusterDetails)
-		err := ansibleutils.UpdateWithTags(hostFileName, config_struct.ClusterDetails, []string{"update_secret", "update_config"})
-		if err != nil {
-			log.Error.Println(err)
-			log.Error.Println("Unable to update config.yaml and .secret.txt on the other node")
-			panic(err)
-		}
-
+		broadcastSecretAndConfig(config_struct.ClusterDetails)
 	}
 
 	return nil
@@ -241,7 +259,7 @@ func OsCredsMismatch(currOsCred config.OsCredentials, prevOsCred config.OsCreden
 }
 
 func CloudCredsMismatch(currCloudCred config.CloudCredentials, prevCloudCred config.CloudCredentials) bool {
-	if (currCloudCred.SecretKey != prevCloudCred.SecretKey) || (currCloudCred.AccessKey != prevCloudCred.AccessKey) || (currCloudCred.RoleArn != prevCloudCred.RoleArn) {
+	if (currCloudCred.SecretKey != prevCloudCred.SecretKey) || (currCloudCred.AccessKey != prevCloudCred.AccessKey) || (currCloudCred.RoleArn != prevCloudCred.RoleArn) || (currCloudCred.ClientSecret != prevCloudCred.ClientSecret) {
 		return true
 	}
 	return false
@@ -388,3 +406,28 @@ func getScrambledOrOriginalSecret(secret string, scrambled bool) string {
 	}
 	return strings.Join(requiredArr, "")
 }
+
+// Inputs:
+//
+//	clusterCfg (config.ClusterDetails): Cluster Level config details
+//
+// Description:
+//
+//	Copies the secret and config to the other nodes of the cluster using ansible.
+//	When cloud_type is LOCAL all the nodes share the host and hence the same files, so nothing is copied.
+//
+// Return:
+func broadcastSecretAndConfig(clusterCfg config.ClusterDetails) {
+	if clusterCfg.CloudType == "LOCAL" {
+		return
+	}
+	//ansible logic to copy the secret and config
+	hostFileName := "broadcast_hosts"
+	utils.HostsWithCurrentNodes(hostFileName, clusterCfg)
+	err := ansibleutils.UpdateWithTags(hostFileName, clusterCfg, []string{"update_secret", "update_config"})
+	if err != nil {
+		log.Error.Println(err)
+		log.Error.Println("Unable to update config.yaml and .secret.txt on the other node")
+		panic(err)
+	}
+}
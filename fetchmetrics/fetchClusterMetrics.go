@@ -3,6 +3,7 @@ package fetchmetrics
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 
 	"github.com/maplelabs/opensearch-scaling-manager/cluster"
 	osutils "github.com/maplelabs/opensearch-scaling-manager/opensearchUtils"
@@ -113,3 +114,46 @@ func IndexClusterHealth(ctx context.Context) {
 	defer resp.Body.Close()
 	log.Info.Println("Cluster document indexed successfully")
 }
+
+// Input:
+//
+//	ctx (context.Context): Request-scoped data that transits processes and APIs.
+//
+// Description:
+//
+//	Fetches the latest ClusterStatistics document indexed by IndexClusterHealth
+//
+// Return:
+//
+//	(ClusterMetrics, bool, error): Returns the cluster metrics, false if no document is indexed yet and error if any
+func GetLatestClusterMetrics(ctx context.Context) (ClusterMetrics, bool, error) {
+	var clusterMetrics ClusterMetrics
+	query := `{
+          "size": 1,
+          "sort": [{"Timestamp": {"order": "desc"}}],
+          "query": {"match": {"StatTag": "ClusterStatistics"}}
+        }`
+	searchResp, err := osutils.SearchQuery(ctx, []byte(query))
+	if err != nil {
+		return clusterMetrics, false, err
+	}
+	defer searchResp.Body.Close()
+	if searchResp.IsError() {
+		return clusterMetrics, false, fmt.Errorf("unable to fetch the cluster statistics: %s", searchResp.String())
+	}
+
+	var result struct {
+		Hits struct {
+			Hits []struct {
+				Source ClusterMetrics `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(searchResp.Body).Decode(&result); err != nil {
+		return clusterMetrics, false, err
+	}
+	if len(result.Hits.Hits) == 0 {
+		return clusterMetrics, false, nil
+	}
+	return result.Hits.Hits[0].Source, true, nil
+}
@@ -4,8 +4,8 @@ import (
 	"context"
 	"time"
 
+	"github.com/maplelabs/opensearch-scaling-manager/leader"
 	"github.com/maplelabs/opensearch-scaling-manager/logger"
-	utils "github.com/maplelabs/opensearch-scaling-manager/utilities"
 )
 
 var ctx = context.Background()
@@ -21,6 +21,7 @@ func init() {
 // Input:
 //
 //	pollingInterval(int): Interval (minutes) at which metrics are fetched and indexed
+//	stop (<-chan struct{}): Closed to stop fetching, Ex: when the polling interval is changed in the config file
 //
 // Descriptions:
 //
@@ -29,16 +30,22 @@ func init() {
 //	    72 hours
 //
 // Return:
-func FetchMetrics(pollingInterval int, purgeAfter int) {
+func FetchMetrics(pollingInterval int, purgeAfter int, stop <-chan struct{}) {
 	ticker := time.NewTicker(time.Duration(pollingInterval) * time.Second)
-	for ; true; <-ticker.C {
-		//check if current node is the master node and update the cluster stats if it is master
-		if utils.CheckIfMaster(ctx, "") {
+	defer ticker.Stop()
+	for {
+		//check if current node holds the leader lease and update the cluster stats if it is the leader
+		if leader.IsLeader() {
 			IndexClusterHealth(ctx)
 		}
 		//Index the the node stats
 		IndexNodeStats(ctx)
 		//Purge documents from elasticsearch index that are older than 72 hours
 		// DeleteOldDocs(ctx, purgeAfter)
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
 	}
 }
@@ -100,6 +100,122 @@ func getRamUtil() float32 {
 	return float32(memFloat)
 }
 
+// Description: nodeCounters struct holds the cumulative counters of the node stats which are needed to calculate
+// the latencies, rates and rejections since the last poll
+type nodeCounters struct {
+	NodeId           string
+	Timestamp        float64
+	QueryTotal       float64
+	QueryTimeMillis  float64
+	IndexTotal       float64
+	IndexTimeMillis  float64
+	SearchRejections float64
+	WriteRejections  float64
+	GcTimeMillis     float64
+}
+
+// The counters of the previous poll, used to calculate the metrics since the last poll
+var previousCounters *nodeCounters
+
+// Input:
+//
+//	m(map[string]interface): Holds a section of the node stats response
+//	path(...string): The keys to reach the value inside the section
+//
+// Description:
+//
+//	The function returns the numeric value present at the path of the node stats response.
+//	Some of the values are not present in every version or operating system, hence zero is returned if the value is not present.
+//
+// Return:
+//
+//	(float64): Returns the value present at the path
+func getNodeStatValue(m map[string]interface{}, path ...string) float64 {
+	for i, key := range path {
+		if i == len(path)-1 {
+			value, _ := m[key].(float64)
+			return value
+		}
+		next, ok := m[key].(map[string]interface{})
+		if !ok {
+			return 0
+		}
+		m = next
+	}
+	return 0
+}
+
+// Input:
+//
+//	nodeId(string): Unique id of the node
+//	nodeInfo(map[string]interface): Holds the node stats response of the node
+//
+// Description:
+//
+//	The function returns the cumulative counters of the node from the indices, thread_pool and jvm sections.
+//
+// Return:
+//
+//	(nodeCounters): Returns the counters of the node
+func getNodeCounters(nodeId string, nodeInfo map[string]interface{}) nodeCounters {
+	counters := nodeCounters{
+		NodeId:           nodeId,
+		Timestamp:        getNodeStatValue(nodeInfo, "timestamp"),
+		QueryTotal:       getNodeStatValue(nodeInfo, "indices", "search", "query_total"),
+		QueryTimeMillis:  getNodeStatValue(nodeInfo, "indices", "search", "query_time_in_millis"),
+		IndexTotal:       getNodeStatValue(nodeInfo, "indices", "indexing", "index_total"),
+		IndexTimeMillis:  getNodeStatValue(nodeInfo, "indices", "indexing", "index_time_in_millis"),
+		SearchRejections: getNodeStatValue(nodeInfo, "thread_pool", "search", "rejected"),
+		WriteRejections:  getNodeStatValue(nodeInfo, "thread_pool", "write", "rejected"),
+	}
+	jvm, _ := nodeInfo["jvm"].(map[string]interface{})
+	gc, _ := jvm["gc"].(map[string]interface{})
+	collectors, _ := gc["collectors"].(map[string]interface{})
+	for collector := range collectors {
+		counters.GcTimeMillis += getNodeStatValue(collectors, collector, "collection_time_in_millis")
+	}
+	return counters
+}
+
+// Input:
+//
+//	nodeMetrics(*NodeMetrics): The node metrics in which the performance metrics are populated
+//	nodeId(string): Unique id of the node
+//	nodeInfo(map[string]interface): Holds the node stats response of the node
+//
+// Description:
+//
+//	The function populates the search and indexing latency, indexing rate, thread pool queues and rejections, GC time and load average.
+//	The latencies, rate, rejections and GC time are calculated from the difference of the counters since the last poll.
+//	They are left as zero for the first poll and when the counters are reset by a restart of the node.
+//
+// Return:
+func populatePerformanceMetrics(nodeMetrics *NodeMetrics, nodeId string, nodeInfo map[string]interface{}) {
+	nodeMetrics.SearchQueue = int(getNodeStatValue(nodeInfo, "thread_pool", "search", "queue"))
+	nodeMetrics.WriteQueue = int(getNodeStatValue(nodeInfo, "thread_pool", "write", "queue"))
+	nodeMetrics.LoadAvg = float32(getNodeStatValue(nodeInfo, "os", "cpu", "load_average", "1m"))
+
+	counters := getNodeCounters(nodeId, nodeInfo)
+	previous := previousCounters
+	previousCounters = &counters
+	if previous == nil || previous.NodeId != counters.NodeId || counters.Timestamp <= previous.Timestamp ||
+		counters.QueryTotal < previous.QueryTotal || counters.IndexTotal < previous.IndexTotal {
+		return
+	}
+
+	if queries := counters.QueryTotal - previous.QueryTotal; queries > 0 {
+		nodeMetrics.SearchLatency = float32((counters.QueryTimeMillis - previous.QueryTimeMillis) / queries)
+	}
+	if indexed := counters.IndexTotal - previous.IndexTotal; indexed > 0 {
+		nodeMetrics.IndexingLatency = float32((counters.IndexTimeMillis - previous.IndexTimeMillis) / indexed)
+	}
+	elapsedMillis := counters.Timestamp - previous.Timestamp
+	nodeMetrics.IndexingRate = float32((counters.IndexTotal - previous.IndexTotal) * 1000 / elapsedMillis)
+	nodeMetrics.SearchRejections = int(counters.SearchRejections - previous.SearchRejections)
+	nodeMetrics.WriteRejections = int(counters.WriteRejections - previous.WriteRejections)
+	nodeMetrics.GcTime = float32((counters.GcTimeMillis - previous.GcTimeMillis) * 100 / elapsedMillis)
+}
+
 // Input:
 //
 //	ctx (context.Context): Request-scoped data that transits processes and APIs.
@@ -115,7 +231,7 @@ func IndexNodeStats(ctx context.Context) {
 
 	//creating a node stats requests with filter to reduce the response to requirement
 	nodes := []string{"_local"}
-	metrics := []string{"jvm", "os", "fs", "indices"}
+	metrics := []string{"jvm", "os", "fs", "indices", "thread_pool"}
 	nodeStatResp, err := osutils.GetNodeStats(ctx, nodes, metrics)
 	if err != nil {
 		log.Error.Println("Node stat fetch error: ", err)
@@ -166,6 +282,7 @@ func IndexNodeStats(ctx context.Context) {
 	heapInGB := heapInBytes / (1 << 30)
 	nodeMetrics.ShardsPerGB = float64(nodeMetrics.NumShards) / heapInGB
 	nodeMetrics.DiskUtil = getDiskUtil(nodeStatsInterface, nodeId)
+	populatePerformanceMetrics(nodeMetrics, nodeId, nodeInfo)
 	nodeMetrics.StatTag = "NodeStatistics"
 	nodeMetrics._documentType = "NodeStatistics"
 
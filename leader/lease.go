@@ -0,0 +1,326 @@
+package leader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/maplelabs/opensearch-scaling-manager/logger"
+	osutils "github.com/maplelabs/opensearch-scaling-manager/opensearchUtils"
+	utils "github.com/maplelabs/opensearch-scaling-manager/utilities"
+
+	osapi "github.com/opensearch-project/opensearch-go/opensearchapi"
+)
+
+// A global logger variable used across the package for logging.
+var log = new(logger.LOG)
+
+// The default time in seconds for which the lease is held without a heartbeat.
+const DefaultLeaseDuration = 30
+
+// This struct contains the lease of the leader which is stored as a document in the monitor-stats index.
+// Only the holder of the lease evaluates the recommendations and provisions, irrespective of which node is the Opensearch master.
+type Lease struct {
+	// Holder indicates the host name of the node holding the lease
+	Holder string
+	// FencingToken is incremented every time the lease changes hands. The writes of the state carry the token,
+	// so that a node which lost the lease can not overwrite the state written by the new leader.
+	FencingToken int64
+	// AcquiredTime indicates when the holder acquired the lease
+	AcquiredTime int64
+	// RenewedTime indicates the last heartbeat of the holder
+	RenewedTime int64
+	// ExpiryTime indicates when the lease can be acquired by another node if the holder does not renew it
+	ExpiryTime int64
+	// StatTag
+	StatTag string
+	// For snappyflow dashboard
+	DocumentType string `json:"_documentType"`
+	// Timestamp
+	Timestamp int64
+}
+
+// The lease as last seen by this node along with whether this node holds it.
+var (
+	mutex         sync.Mutex
+	leaseDuration = DefaultLeaseDuration * time.Second
+	docId         string
+	current       Lease
+	isHolder      bool
+	heldToken     int64
+	validUntil    time.Time
+	checked       bool
+)
+
+// Input:
+//
+// Description:
+//
+//	Initialize the leader module
+//
+// Return:
+func init() {
+	log.Init("logger")
+	log.Info.Println("Leader module initiated")
+}
+
+// Input:
+//
+//	duration (int): Time in seconds for which the lease is held without a heartbeat. The default is used if it is 0.
+//
+// Description:
+//
+//	Acquires the lease if it is free and keeps renewing it with a heartbeat every third of the lease duration.
+//	If the lease is held by another node, it is acquired once the holder stops renewing it.
+//	It is to be called as a goroutine and does not return.
+//
+// Return:
+func Run(duration int) {
+	SetLeaseDuration(duration)
+	mutex.Lock()
+	heartbeatInterval := leaseDuration / 3
+	mutex.Unlock()
+	ticker := time.NewTicker(heartbeatInterval)
+	for ; true; <-ticker.C {
+		heartbeat()
+	}
+}
+
+// Input:
+//
+//	duration (int): Time in seconds for which the lease is held without a heartbeat. The default is used if it is 0.
+//
+// Description:
+//
+//	Sets the lease duration. It needs to be called before the lease is first checked, Ex: by IsLeader,
+//	so that the lease is not acquired with the default duration when Run has not started yet.
+//
+// Return:
+func SetLeaseDuration(duration int) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if duration > 0 {
+		leaseDuration = time.Duration(duration) * time.Second
+	}
+}
+
+// Input:
+//
+// Description:
+//
+//	Checks if this node holds the lease. The lease is held only until it expires on the clock of this node,
+//	even if the heartbeat is delayed. If the lease was never checked, it is acquired or checked once.
+//
+// Return:
+//
+//	(bool): Returns true if this node is the leader
+func IsLeader() bool {
+	mutex.Lock()
+	isChecked := checked
+	mutex.Unlock()
+	if !isChecked {
+		heartbeat()
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	return isHolder && time.Now().Before(validUntil)
+}
+
+// Input:
+//
+// Description:
+//
+//	Returns the fencing token of the last lease acquired by this node. The token is returned even if the lease expired,
+//	e.g. when Opensearch is unavailable, as the writes with the token are rejected once a newer leader has written with its token.
+//
+// Return:
+//
+//	(int64): Returns the fencing token or 0 if this node never acquired the lease
+func FencingToken() int64 {
+	mutex.Lock()
+	isChecked := checked
+	mutex.Unlock()
+	if !isChecked {
+		heartbeat()
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	return heldToken
+}
+
+// Input:
+//
+// Description:
+//
+//	Reads the lease from Opensearch, so that the holder of the lease can be shown.
+//
+// Return:
+//
+//	(Lease, error): Returns the lease and error if it could not be read. An empty lease is returned if no node acquired it yet.
+func GetLease() (Lease, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	lease, _, _, _, err := readLease()
+	return lease, err
+}
+
+// Input:
+//
+// Description:
+//
+//	Renews the lease if this node holds it, acquires it if it expired or was never acquired and records the lease otherwise.
+//	The lease is written only if it was not written by another node since it was read, so only one node can acquire it.
+//	The fencing token is incremented whenever the lease is acquired by a node which did not hold it.
+//
+// Return:
+func heartbeat() {
+	mutex.Lock()
+	defer mutex.Unlock()
+	checked = true
+	start := time.Now()
+	host := getHostName()
+
+	lease, seqNo, primaryTerm, found, err := readLease()
+	if err != nil {
+		log.Error.Println("Unable to read the leader lease: ", err)
+		return
+	}
+	wasHolder := isHolder
+	newLease := lease
+	switch {
+	case found && lease.Holder == host && wasHolder && lease.FencingToken == current.FencingToken:
+		// Renew the lease held by this node
+	case !found || start.UnixMilli() > lease.ExpiryTime || lease.Holder == host:
+		// The lease is free, or it was held by this host before a restart
+		newLease.Holder = host
+		newLease.FencingToken = lease.FencingToken + 1
+		newLease.AcquiredTime = start.UnixMilli()
+	default:
+		if wasHolder {
+			log.Warn.Println("The leader lease is lost to ", lease.Holder)
+		} else if lease.Holder != current.Holder {
+			log.Info.Println("The leader lease is held by ", lease.Holder)
+		}
+		current = lease
+		isHolder = false
+		return
+	}
+	newLease.RenewedTime = start.UnixMilli()
+	newLease.ExpiryTime = start.Add(leaseDuration).UnixMilli()
+	newLease.StatTag = "LeaderLease"
+	newLease.DocumentType = "LeaderLease"
+	newLease.Timestamp = start.UnixMilli()
+
+	if err := writeLease(newLease, found, seqNo, primaryTerm); err != nil {
+		if wasHolder {
+			log.Warn.Println("Unable to renew the leader lease: ", err)
+		} else {
+			log.Debug.Println("Unable to acquire the leader lease: ", err)
+		}
+		isHolder = false
+		return
+	}
+	if !wasHolder || newLease.FencingToken != current.FencingToken {
+		log.Info.Println(fmt.Sprintf("Acquired the leader lease with the fencing token %d", newLease.FencingToken))
+	}
+	current = newLease
+	isHolder = true
+	heldToken = newLease.FencingToken
+	validUntil = start.Add(leaseDuration)
+}
+
+// Input:
+//
+// Description:
+//
+//	Reads the lease document from Opensearch along with its sequence number and primary term.
+//
+// Return:
+//
+//	(Lease, int, int, bool, error): Returns the lease, its sequence number and primary term, false if it does not exist and error if any
+func readLease() (Lease, int, int, bool, error) {
+	var lease Lease
+	if docId == "" {
+		docId = fmt.Sprint(utils.Hash(utils.GetClusterId() + "_leader_lease"))
+	}
+	response, err := osutils.SearchDoc(context.Background(), docId)
+	if err != nil {
+		return lease, 0, 0, false, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		return lease, 0, 0, false, nil
+	}
+	if response.IsError() {
+		return lease, 0, 0, false, fmt.Errorf("%s", response.String())
+	}
+	var leaseDoc struct {
+		Source      Lease `json:"_source"`
+		SeqNo       int   `json:"_seq_no"`
+		PrimaryTerm int   `json:"_primary_term"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&leaseDoc); err != nil {
+		return lease, 0, 0, false, err
+	}
+	return leaseDoc.Source, leaseDoc.SeqNo, leaseDoc.PrimaryTerm, true, nil
+}
+
+// Input:
+//
+//	lease (Lease): The lease to be written
+//	found (bool): Whether the lease document exists
+//	seqNo (int): The sequence number of the lease document when it was read
+//	primaryTerm (int): The primary term of the lease document when it was read
+//
+// Description:
+//
+//	Writes the lease document only if it was not written by another node since it was read.
+//
+// Return:
+//
+//	(error): Returns error if the lease was written by another node or could not be written
+func writeLease(lease Lease, found bool, seqNo int, primaryTerm int) error {
+	content, err := json.Marshal(lease)
+	if err != nil {
+		return err
+	}
+	var response *osapi.Response
+	if found {
+		response, err = osutils.UpdateDocIfVersion(context.Background(), docId, string(content), seqNo, primaryTerm)
+	} else {
+		response, err = osutils.CreateDoc(context.Background(), docId, string(content))
+	}
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusConflict {
+		return fmt.Errorf("the lease was written by another node")
+	}
+	if response.IsError() {
+		return fmt.Errorf("%s", response.String())
+	}
+	return nil
+}
+
+// Input:
+//
+// Description:
+//
+//	Returns the name of the node which identifies the holder of the lease.
+//
+// Return:
+//
+//	(string): Returns the host name
+func getHostName() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Warn.Println("Unable to get the host name: ", err)
+		return "unknown"
+	}
+	return hostname
+}
@@ -62,38 +62,44 @@ func (l *LOG) Init(module string) {
 		PANIC   = fmt.Sprintf("%5s%15s ", "PANIC", module)
 	)
 
-	if err := k.Load(file.Provider(configFile), json.Parser()); err != nil {
-		log.Fatalf("error loading config: %v", err)
-	}
+	// The logs are written to the log file only when the logging config is present. Without it, Ex: when the tests of a module
+	// are run from its directory, the logs are written only to the console at the INFO level.
+	var logFile io.Writer = ioutil.Discard
+	level := "INFO"
+	if _, err := os.Stat(configFile); !os.IsNotExist(err) {
+		if err := k.Load(file.Provider(configFile), json.Parser()); err != nil {
+			log.Fatalf("error loading config: %v", err)
+		}
 
-	if _, err := os.Stat(k.String("logpath")); os.IsNotExist(err) {
-		// Path does not exist, create necessary folders in specified path
-		err := os.MkdirAll(k.String("logpath"), os.ModePerm)
-		if err != nil {
-			log.Fatalf("Error creating dir: %v", err)
+		if _, err := os.Stat(k.String("logpath")); os.IsNotExist(err) {
+			// Path does not exist, create necessary folders in specified path
+			err := os.MkdirAll(k.String("logpath"), os.ModePerm)
+			if err != nil {
+				log.Fatalf("Error creating dir: %v", err)
+			}
 		}
-	}
 
-	// create log path
-	path := path.Join(k.String("logpath"), k.String("logfile"))
+		// create log path
+		path := path.Join(k.String("logpath"), k.String("logfile"))
 
-	// create lumberjack loger object for rotaing file handling
-	logger := &lumberjack.Logger{
-		Filename:   path,
-		MaxSize:    k.Int("MaxSize"), // megabytes
-		MaxBackups: k.Int("MaxBackups"),
-		MaxAge:     k.Int("MaxAge"), // days
+		// create lumberjack loger object for rotaing file handling
+		logFile = &lumberjack.Logger{
+			Filename:   path,
+			MaxSize:    k.Int("MaxSize"), // megabytes
+			MaxBackups: k.Int("MaxBackups"),
+			MaxAge:     k.Int("MaxAge"), // days
+		}
+		// get log level and convert to upper case for switch statement
+		level = strings.ToUpper(k.String("level"))
 	}
-	// get log level and convert to upper case for switch statement
-	level := strings.ToUpper(k.String("level"))
-
-	traceHandle := io.MultiWriter(ioutil.Discard, logger)
-	infoHandle := io.MultiWriter(os.Stdout, logger)
-	warningHandle := io.MultiWriter(os.Stdout, logger)
-	errorHandle := io.MultiWriter(os.Stderr, logger)
-	debugHandle := io.MultiWriter(os.Stdout, logger)
-	fatalHandle := io.MultiWriter(os.Stderr, logger)
-	panicHandle := io.MultiWriter(os.Stderr, logger)
+
+	traceHandle := io.MultiWriter(ioutil.Discard, logFile)
+	infoHandle := io.MultiWriter(os.Stdout, logFile)
+	warningHandle := io.MultiWriter(os.Stdout, logFile)
+	errorHandle := io.MultiWriter(os.Stderr, logFile)
+	debugHandle := io.MultiWriter(os.Stdout, logFile)
+	fatalHandle := io.MultiWriter(os.Stderr, logFile)
+	panicHandle := io.MultiWriter(os.Stderr, logFile)
 
 	l.Trace = log.New(ioutil.Discard, "", log.Ldate|log.Ltime|log.Lshortfile)
 	l.Debug = log.New(ioutil.Discard, "", log.Ldate|log.Ltime|log.Lshortfile)
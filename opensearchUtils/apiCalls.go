@@ -179,6 +179,57 @@ func UpdateDoc(ctx context.Context, docId string, content string) (*osapi.Respon
 	}.Do(ctx, osClient)
 }
 
+// Input:
+//
+//	docId (string): The _id of the document which needs to be updated
+//	content (string): The body of the request that needs to be updated in the document
+//	seqNo (int): The sequence number of the document when it was read
+//	primaryTerm (int): The primary term of the document when it was read
+//	ctx (context.Context): Request-scoped data that transits processes and APIs.
+//
+// Description:
+//
+//	Calls the osapi IndexRequest along with document ID to update the document only if it was not updated since it was read.
+//	The response has the status 409 Conflict if the document was updated by someone else.
+//
+// Return:
+//
+//	(*osapi.Response, error): Returns the api response and error if any
+func UpdateDocIfVersion(ctx context.Context, docId string, content string, seqNo int, primaryTerm int) (*osapi.Response, error) {
+	return osapi.IndexRequest{
+		Index:         IndexName,
+		DocumentID:    docId,
+		Body:          strings.NewReader(content),
+		IfSeqNo:       &seqNo,
+		IfPrimaryTerm: &primaryTerm,
+		Refresh:       "wait_for",
+	}.Do(ctx, osClient)
+}
+
+// Input:
+//
+//	docId (string): The _id of the document which needs to be created
+//	content (string): The body of the document
+//	ctx (context.Context): Request-scoped data that transits processes and APIs.
+//
+// Description:
+//
+//	Calls the osapi IndexRequest with op_type create, which creates the document only if it does not exist.
+//	The response has the status 409 Conflict if the document already exists.
+//
+// Return:
+//
+//	(*osapi.Response, error): Returns the api response and error if any
+func CreateDoc(ctx context.Context, docId string, content string) (*osapi.Response, error) {
+	return osapi.IndexRequest{
+		Index:      IndexName,
+		DocumentID: docId,
+		Body:       strings.NewReader(content),
+		OpType:     "create",
+		Refresh:    "wait_for",
+	}.Do(ctx, osClient)
+}
+
 // Input:
 //
 //	jsonQuery ([]byte): Query by which the deletion of documents is carried
@@ -215,3 +266,21 @@ func RerouteRetryFailed(ctx context.Context) (*osapi.Response, error) {
 		RetryFailed: &retry,
 	}.Do(ctx, osClient)
 }
+
+// Input:
+//
+//	ctx (context.Context): Request-scoped data that transits processes and APIs.
+//	settings (string): The body of the request containing the persistent and/or transient settings
+//
+// Description:
+//
+//	Calls the osapi ClusterPutSettingsRequest and returns the response
+//
+// Return:
+//
+//	(*osapi.Response, error): Returns the api response and error if any
+func PutClusterSettings(ctx context.Context, settings string) (*osapi.Response, error) {
+	return osapi.ClusterPutSettingsRequest{
+		Body: strings.NewReader(settings),
+	}.Do(ctx, osClient)
+}
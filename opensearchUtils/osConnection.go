@@ -3,13 +3,24 @@ package osutils
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	_ "embed"
+	"fmt"
 	"github.com/maplelabs/opensearch-scaling-manager/logger"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
 	opensearch "github.com/opensearch-project/opensearch-go"
 	osapi "github.com/opensearch-project/opensearch-go/opensearchapi"
+	"github.com/opensearch-project/opensearch-go/opensearchtransport"
+	requestsigner "github.com/opensearch-project/opensearch-go/signer/aws"
 )
 
 //go:embed mappings.json
@@ -21,6 +32,83 @@ const (
 	IndexName string = "monitor-stats"
 )
 
+// The address of the OS node used when no address is configured.
+const defaultAddress = "http://localhost:9200"
+
+// The default time in seconds to connect to an OS node.
+const defaultConnectTimeout = 30
+
+// The default time in seconds after which an idle connection to an OS node is closed.
+const defaultIdleConnTimeout = 90
+
+// This struct contains how the OS client connects to the OS cluster.
+type ConnectionConfig struct {
+	// Addresses indicates the URLs of the OS nodes. The default is http://localhost:9200.
+	// The addresses are used in the order they are configured, the next one only while the previous ones are unavailable.
+	// The node stats are fetched from the node serving the request, so the first address needs to be the OS node on this host.
+	Addresses []string `yaml:"addresses,omitempty" validate:"omitempty,dive,url" json:"addresses"`
+	// CaCertFile indicates the path of the PEM bundle of the certificate authorities which signed the certificates of the OS nodes.
+	// If it is not set then the certificate authorities of the system are used.
+	CaCertFile string `yaml:"ca_cert_file,omitempty" json:"ca_cert_file"`
+	// ClientCertFile and ClientKeyFile indicates the paths of the PEM certificate and key presented to the OS nodes for the TLS client authentication.
+	ClientCertFile string `yaml:"client_cert_file,omitempty" validate:"required_with=ClientKeyFile" json:"client_cert_file"`
+	ClientKeyFile  string `yaml:"client_key_file,omitempty" validate:"required_with=ClientCertFile" json:"client_key_file"`
+	// InsecureSkipVerify indicates that the certificates of the OS nodes are not verified. It is meant only for testing.
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify,omitempty" json:"insecure_skip_verify"`
+	// MaxIdleConnsPerHost indicates the number of idle connections kept open to every OS node to be reused.
+	// The default is 0, i.e., the keep-alives are disabled and every request opens a new connection.
+	MaxIdleConnsPerHost int `yaml:"max_idle_conns_per_host,omitempty" validate:"min=0" json:"max_idle_conns_per_host"`
+	// IdleConnTimeout indicates the time in seconds after which an idle connection is closed. The default is 90 seconds.
+	IdleConnTimeout int `yaml:"idle_conn_timeout_in_secs,omitempty" validate:"min=0" json:"idle_conn_timeout_in_secs"`
+	// ConnectTimeout indicates the time in seconds to connect to an OS node, including the TLS handshake. The default is 30 seconds.
+	ConnectTimeout int `yaml:"connect_timeout_in_secs,omitempty" validate:"min=0" json:"connect_timeout_in_secs"`
+	// RequestTimeout indicates the time in seconds to wait for the response of a request once it is sent.
+	// The default is 0, i.e., the response is waited for indefinitely.
+	RequestTimeout int `yaml:"request_timeout_in_secs,omitempty" validate:"min=0" json:"request_timeout_in_secs"`
+	// AwsSigV4 indicates that the requests are signed with AWS Signature Version 4 rather than the basic authentication, Ex: for Amazon OpenSearch Service.
+	AwsSigV4 AwsSigV4Config `yaml:"aws_sigv4,omitempty" json:"aws_sigv4"`
+}
+
+// This struct contains how the requests are signed with AWS Signature Version 4.
+// The credentials are taken from the default AWS credential chain, Ex: the environment or the IAM role of the instance.
+type AwsSigV4Config struct {
+	// Enabled indicates that the requests are signed.
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled"`
+	// Region indicates the AWS region of the OpenSearch domain.
+	Region string `yaml:"region,omitempty" validate:"required_if=Enabled true" json:"region"`
+}
+
+// A selector which prefers the OS nodes in the order of the configured addresses, unlike the default round robin,
+// so that the requests are served by the OS node on this host while it is available.
+type orderedSelector struct {
+	order map[string]int
+}
+
+// Input:
+//
+//	connections ([]*opensearchtransport.Connection): The live connections
+//
+// Caller:
+//
+//	Object of type orderedSelector
+//
+// Description:
+//
+//	Selects the live connection whose address is configured first.
+//
+// Return:
+//
+//	(*opensearchtransport.Connection, error): Returns the connection
+func (s orderedSelector) Select(connections []*opensearchtransport.Connection) (*opensearchtransport.Connection, error) {
+	selected := connections[0]
+	for _, connection := range connections[1:] {
+		if s.order[connection.URL.String()] < s.order[selected.URL.String()] {
+			selected = connection
+		}
+	}
+	return selected, nil
+}
+
 // A global logger variable used across the package for logging.
 var log = new(logger.LOG)
 
@@ -41,26 +129,23 @@ func init() {
 
 // Input:
 //
+//	connCfg (ConnectionConfig): How the OS client connects to the OS cluster
 //	username (string): Username for OS cluster
 //	password (string): Password for OS cluster
 //
 // Description:
 //
-//	Initialize the Opensearch client
+//	Initialize the Opensearch client, which is used for all the Opensearch operations of the application
 //
 // Return:
-func InitializeOsClient(username string, password string) {
-	var err error
-
-	osClient, err = opensearch.NewClient(opensearch.Config{
-		Addresses: []string{"http://localhost:9200"},
-		Username:  username,
-		Password:  password,
-		Transport: &http.Transport{
-			DisableKeepAlives: true,
-		},
-		MaxRetries: 5,
-	})
+func InitializeOsClient(connCfg ConnectionConfig, username string, password string) {
+	osConfig, err := newOsConfig(connCfg, username, password)
+	if err != nil {
+		log.Fatal.Println(err)
+		os.Exit(1)
+	}
+
+	osClient, err = opensearch.NewClient(osConfig)
 	if err != nil {
 		log.Fatal.Println(err)
 		os.Exit(1)
@@ -83,6 +168,111 @@ func InitializeOsClient(username string, password string) {
 
 }
 
+// Input:
+//
+//	connCfg (ConnectionConfig): How the OS client connects to the OS cluster
+//	username (string): Username for OS cluster
+//	password (string): Password for OS cluster
+//
+// Description:
+//
+//	Builds the configuration of the Opensearch client: the addresses, the transport with the TLS, the pooling and the timeouts,
+//	and either the basic authentication or the AWS SigV4 signer.
+//
+// Return:
+//
+//	(opensearch.Config, error): Returns the configuration and the error if a certificate could not be loaded or the signer could not be created
+func newOsConfig(connCfg ConnectionConfig, username string, password string) (opensearch.Config, error) {
+	addresses := connCfg.Addresses
+	if len(addresses) == 0 {
+		addresses = []string{defaultAddress}
+	}
+	// The addresses are keyed as parsed by the client
+	order := make(map[string]int)
+	for i, address := range addresses {
+		if u, err := url.Parse(strings.TrimRight(address, "/")); err == nil {
+			order[u.String()] = i
+		}
+	}
+
+	transport, err := newTransport(connCfg)
+	if err != nil {
+		return opensearch.Config{}, err
+	}
+	osConfig := opensearch.Config{
+		Addresses:  addresses,
+		Transport:  transport,
+		Selector:   orderedSelector{order: order},
+		MaxRetries: 5,
+	}
+	if connCfg.AwsSigV4.Enabled {
+		signer, err := requestsigner.NewSigner(session.Options{
+			Config:            aws.Config{Region: aws.String(connCfg.AwsSigV4.Region)},
+			SharedConfigState: session.SharedConfigEnable,
+		})
+		if err != nil {
+			return opensearch.Config{}, err
+		}
+		osConfig.Signer = signer
+	} else {
+		osConfig.Username = username
+		osConfig.Password = password
+	}
+	return osConfig, nil
+}
+
+// Input:
+//
+//	connCfg (ConnectionConfig): How the OS client connects to the OS cluster
+//
+// Description:
+//
+//	Builds the HTTP transport to the OS nodes with the certificate authorities, the client certificate, the pooling and the timeouts.
+//
+// Return:
+//
+//	(*http.Transport, error): Returns the transport and the error if a certificate could not be loaded
+func newTransport(connCfg ConnectionConfig) (*http.Transport, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: connCfg.InsecureSkipVerify}
+	if connCfg.CaCertFile != "" {
+		caCert, err := os.ReadFile(connCfg.CaCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the CA bundle: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificate found in the CA bundle %s", connCfg.CaCertFile)
+		}
+	}
+	if connCfg.ClientCertFile != "" {
+		clientCert, err := tls.LoadX509KeyPair(connCfg.ClientCertFile, connCfg.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+
+	connectTimeout := time.Duration(defaultConnectTimeout) * time.Second
+	if connCfg.ConnectTimeout > 0 {
+		connectTimeout = time.Duration(connCfg.ConnectTimeout) * time.Second
+	}
+	idleConnTimeout := time.Duration(defaultIdleConnTimeout) * time.Second
+	if connCfg.IdleConnTimeout > 0 {
+		idleConnTimeout = time.Duration(connCfg.IdleConnTimeout) * time.Second
+	}
+	dialer := &net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   connectTimeout,
+		DisableKeepAlives:     connCfg.MaxIdleConnsPerHost == 0,
+		MaxIdleConnsPerHost:   connCfg.MaxIdleConnsPerHost,
+		IdleConnTimeout:       idleConnTimeout,
+		ResponseHeaderTimeout: time.Duration(connCfg.RequestTimeout) * time.Second,
+	}, nil
+}
+
 // Input:
 //
 //	ctx (context.Context)
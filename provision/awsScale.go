@@ -1,6 +1,8 @@
 package provision
 
 import (
+	"fmt"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
@@ -10,32 +12,60 @@ import (
 	"github.com/maplelabs/opensearch-scaling-manager/config"
 )
 
+// This struct implements the CloudProvider interface for AWS EC2.
+type awsProvider struct {
+	// launchTemplateId indicates the launch template using which a new ec2 instance will be spinned up
+	launchTemplateId string
+	// launchTemplateVersion indicates the template version of the launch template specified
+	launchTemplateVersion string
+	// cred indicates the cloud credentials to connect to AWS
+	cred config.CloudCredentials
+}
+
 // Input:
-//	launchTemplateId (string): Launch Template ID using which a new ec2 instance will be spinned up
-//	launchTemplateVersion (string): Template version of the launch template specified
-//	cred (config.CloudCredentials): Cloud credentials to connect to AWS
+//
+// Caller:
+//
+//	Object of awsProvider
 //
 // Description:
 //
-//	Spins a new ec2 instance on AWS using the launchTemplate specified.
-//	Returns the ip address of the created ec2 instance for further configuration of Opensearch
+//	Creates the ec2 client using either the role arn or the access and secret key.
 //
 // Return:
 //
-//	(string, string, error): Returns the private ip address, instance ID of the spinned node and error if any
-func SpinNewVm(launchTemplateId string, launchTemplateVersion string, cred config.CloudCredentials) (string, string, error) {
+//	(*ec2.EC2): Returns the ec2 client
+func (p *awsProvider) client() *ec2.EC2 {
 	sess := session.Must(session.NewSession())
 	var creds *credentials.Credentials
-	if cred.RoleArn != "" {
-		creds = stscreds.NewCredentials(sess, cred.RoleArn)
+	if p.cred.RoleArn != "" {
+		creds = stscreds.NewCredentials(sess, p.cred.RoleArn)
 	} else {
-		creds = credentials.NewStaticCredentials(cred.AccessKey, cred.SecretKey, "")
+		creds = credentials.NewStaticCredentials(p.cred.AccessKey, p.cred.SecretKey, "")
 	}
-	svc := ec2.New(sess, &aws.Config{Region: aws.String(cred.Region), Credentials: creds})
+	return ec2.New(sess, &aws.Config{Region: aws.String(p.cred.Region), Credentials: creds})
+}
+
+// Input:
+//
+// Caller:
+//
+//	Object of awsProvider
+//
+// Description:
+//
+//	Spins a new ec2 instance on AWS using the launchTemplate specified.
+//	Returns the ip address of the created ec2 instance for further configuration of Opensearch
+//
+// Return:
+//
+//	(string, string, error): Returns the private ip address, instance ID of the spinned node and error if any
+func (p *awsProvider) SpinNewVm() (string, string, error) {
+	svc := p.client()
 
 	launchTemplate := &ec2.LaunchTemplateSpecification{
-		LaunchTemplateId: &launchTemplateId,
-		Version:          &launchTemplateVersion,
+		LaunchTemplateId: &p.launchTemplateId,
+		Version:          &p.launchTemplateVersion,
 	}
 
 	// Specify the details of the instance that you want to create.
@@ -65,7 +95,10 @@ func SpinNewVm(launchTemplateId string, launchTemplateVersion string, cred confi
 // Input:
 //
 //	instanceId (string): Instance ID of the ec2 instance to wait until it's status to be Okay
-// 	cred (config.CloudCredentials): Cloud credentials required to connect to AWS account
+//
+// Caller:
+//
+//	Object of awsProvider
 //
 // Description:
 //
@@ -74,15 +107,8 @@ func SpinNewVm(launchTemplateId string, launchTemplateVersion string, cred confi
 // Return:
 //
 //	(error): Returns error if any while checking for the status
-func InstanceStatusCheck(instanceId string, cred config.CloudCredentials) error {
-	sess := session.Must(session.NewSession())
-	var creds *credentials.Credentials
-	if cred.RoleArn != "" {
-		creds = stscreds.NewCredentials(sess, cred.RoleArn)
-	} else {
-		creds = credentials.NewStaticCredentials(cred.AccessKey, cred.SecretKey, "")
-	}
-	svc := ec2.New(sess, &aws.Config{Region: aws.String(cred.Region), Credentials: creds})
+func (p *awsProvider) WaitUntilReady(instanceId string) error {
+	svc := p.client()
 
 	allInstances := true
 
@@ -101,28 +127,22 @@ func InstanceStatusCheck(instanceId string, cred config.CloudCredentials) error
 
 // Input:
 //
-//	privateIp (string): private ip address of the instance that needs to be terminated
-//      cred (config.CloudCredentials): Cloud credentials required to connect to AWS account
+//	privateIp (string): private ip address of the instance that needs to be described
+//
+// Caller:
+//
+//	Object of awsProvider
 //
 // Description:
 //
-//	Uses the private ip address passed as input to identify the instance id.
-//	Terminates the ec2 instance.
+//	Uses the private ip address passed as input to identify the ec2 instance and its state.
 //
 // Return:
 //
-//	(error): Returns error if any while terminating the instance
-func TerminateInstance(privateIp string, cred config.CloudCredentials) error {
-	sess := session.Must(session.NewSession())
-	var creds *credentials.Credentials
-
-	if cred.RoleArn != "" {
-		creds = stscreds.NewCredentials(sess, cred.RoleArn)
-	} else {
-		creds = credentials.NewStaticCredentials(cred.AccessKey, cred.SecretKey, "")
-	}
-
-	svc := ec2.New(sess, &aws.Config{Region: aws.String(cred.Region), Credentials: creds})
+//	(InstanceDetails, error): Returns the details of the instance and error if any
+func (p *awsProvider) DescribeInstance(privateIp string) (InstanceDetails, error) {
+	var instanceDetails InstanceDetails
+	svc := p.client()
 
 	describeInput := &ec2.DescribeInstancesInput{
 		Filters: []*ec2.Filter{
@@ -139,16 +159,51 @@ func TerminateInstance(privateIp string, cred config.CloudCredentials) error {
 
 	if descErr != nil {
 		log.Info.Println("Could not get the description of instance", descErr)
+		return instanceDetails, descErr
+	}
+
+	if len(describeResult.Reservations) == 0 || len(describeResult.Reservations[0].Instances) == 0 {
+		return instanceDetails, fmt.Errorf("no instance found with private ip %s", privateIp)
+	}
+
+	instance := describeResult.Reservations[0].Instances[0]
+	instanceDetails.InstanceId = aws.StringValue(instance.InstanceId)
+	instanceDetails.PrivateIp = aws.StringValue(instance.PrivateIpAddress)
+	if instance.State != nil {
+		instanceDetails.State = aws.StringValue(instance.State.Name)
+	}
+	return instanceDetails, nil
+}
+
+// Input:
+//
+//	privateIp (string): private ip address of the instance that needs to be terminated
+//
+// Caller:
+//
+//	Object of awsProvider
+//
+// Description:
+//
+//	Uses the private ip address passed as input to identify the instance id.
+//	Terminates the ec2 instance.
+//
+// Return:
+//
+//	(error): Returns error if any while terminating the instance
+func (p *awsProvider) TerminateInstance(privateIp string) error {
+	instanceDetails, descErr := p.DescribeInstance(privateIp)
+	if descErr != nil {
 		return descErr
 	}
 
-	instanceId := *describeResult.Reservations[0].Instances[0].InstanceId
+	svc := p.client()
 
-	log.Info.Println("Terminating instance with ID: ", instanceId)
+	log.Info.Println("Terminating instance with ID: ", instanceDetails.InstanceId)
 
 	input := &ec2.TerminateInstancesInput{
 		InstanceIds: []*string{
-			aws.String(instanceId),
+			aws.String(instanceDetails.InstanceId),
 		},
 	}
 
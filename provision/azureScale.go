@@ -0,0 +1,459 @@
+package provision
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+	"github.com/maplelabs/opensearch-scaling-manager/config"
+	"golang.org/x/crypto/ssh"
+)
+
+// This struct implements the CloudProvider interface for Azure virtual machines.
+type azureProvider struct {
+	// vmConfig indicates the size, image and subnet of the virtual machine to be created
+	vmConfig config.AzureVmConfig
+	// sshUser indicates the admin user of the virtual machine
+	sshUser string
+	// namePrefix indicates the prefix used while naming the new virtual machines
+	namePrefix string
+	// cred indicates the cloud credentials to connect to Azure
+	cred config.CloudCredentials
+}
+
+// Input:
+//
+// Caller:
+//
+//	Object of azureProvider
+//
+// Description:
+//
+//	Creates the Azure credential using the service principal if specified,
+//	otherwise the default Azure credential chain is used.
+//
+// Return:
+//
+//	(azcore.TokenCredential, error): Returns the credential and error if any
+func (p *azureProvider) credential() (azcore.TokenCredential, error) {
+	if p.cred.ClientId != "" {
+		return azidentity.NewClientSecretCredential(p.cred.TenantId, p.cred.ClientId, p.cred.ClientSecret, nil)
+	}
+	return azidentity.NewDefaultAzureCredential(nil)
+}
+
+// Input:
+//
+// Caller:
+//
+//	Object of azureProvider
+//
+// Description:
+//
+//	Creates the virtual machines and network interfaces clients for the configured subscription.
+//
+// Return:
+//
+//	(*armcompute.VirtualMachinesClient, *armnetwork.InterfacesClient, error): Returns the clients and error if any
+func (p *azureProvider) clients() (*armcompute.VirtualMachinesClient, *armnetwork.InterfacesClient, error) {
+	cred, err := p.credential()
+	if err != nil {
+		log.Error.Println("Unable to create Azure credential: ", err)
+		return nil, nil, err
+	}
+	vmClient, err := armcompute.NewVirtualMachinesClient(p.cred.SubscriptionId, cred, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	nicClient, err := armnetwork.NewInterfacesClient(p.cred.SubscriptionId, cred, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return vmClient, nicClient, nil
+}
+
+// Input:
+//
+// Caller:
+//
+//	Object of azureProvider
+//
+// Description:
+//
+//	Derives the ssh public key from the pem file so that the same key can be used to ssh into the new virtual machine.
+//
+// Return:
+//
+//	(string, error): Returns the public key in authorized_keys format and error if any
+func (p *azureProvider) sshPublicKey() (string, error) {
+	pemBytes, err := os.ReadFile(p.cred.PemFilePath)
+	if err != nil {
+		return "", err
+	}
+	signer, err := ssh.ParsePrivateKey(pemBytes)
+	if err != nil {
+		return "", err
+	}
+	return string(ssh.MarshalAuthorizedKey(signer.PublicKey())), nil
+}
+
+// Input:
+//
+// Caller:
+//
+//	Object of azureProvider
+//
+// Description:
+//
+//	Returns the image reference using the image ID configured.
+//	A marketplace image is specified as publisher:offer:sku:version and any other value is considered as an image resource ID.
+//
+// Return:
+//
+//	(*armcompute.ImageReference): Returns the image reference
+func (p *azureProvider) imageReference() *armcompute.ImageReference {
+	parts := strings.Split(p.vmConfig.ImageId, ":")
+	if len(parts) == 4 {
+		return &armcompute.ImageReference{
+			Publisher: to.Ptr(parts[0]),
+			Offer:     to.Ptr(parts[1]),
+			SKU:       to.Ptr(parts[2]),
+			Version:   to.Ptr(parts[3]),
+		}
+	}
+	return &armcompute.ImageReference{ID: to.Ptr(p.vmConfig.ImageId)}
+}
+
+// Input:
+//
+// Caller:
+//
+//	Object of azureProvider
+//
+// Description:
+//
+//	Creates a network interface in the configured subnet and a new virtual machine attached to it.
+//	The network interface and OS disk are marked to be deleted along with the virtual machine.
+//	Returns the ip address of the created virtual machine for further configuration of Opensearch
+//	If the virtual machine could not be created or has no ip address, the virtual machine and the network interface are deleted,
+//	as the rollback of the provision identifies the instances only by their ip address.
+//
+// Return:
+//
+//	(string, string, error): Returns the private ip address, virtual machine name of the spinned node and error if any
+func (p *azureProvider) SpinNewVm() (string, string, error) {
+	ctx := context.Background()
+	vmClient, nicClient, err := p.clients()
+	if err != nil {
+		return "", "", err
+	}
+	publicKey, err := p.sshPublicKey()
+	if err != nil {
+		log.Error.Println("Unable to read the ssh public key from pem file: ", err)
+		return "", "", err
+	}
+
+	vmName := newInstanceName(p.namePrefix)
+	log.Info.Println("Creating new instance *************")
+
+	nicPoller, err := nicClient.BeginCreateOrUpdate(ctx, p.cred.ResourceGroup, vmName+"-nic", armnetwork.Interface{
+		Location: to.Ptr(p.cred.Region),
+		Properties: &armnetwork.InterfacePropertiesFormat{
+			IPConfigurations: []*armnetwork.InterfaceIPConfiguration{
+				{
+					Name: to.Ptr("ipconfig1"),
+					Properties: &armnetwork.InterfaceIPConfigurationPropertiesFormat{
+						Subnet:                    &armnetwork.Subnet{ID: to.Ptr(p.vmConfig.SubnetId)},
+						PrivateIPAllocationMethod: to.Ptr(armnetwork.IPAllocationMethodDynamic),
+					},
+				},
+			},
+		},
+	}, nil)
+	if err != nil {
+		log.Info.Println("Could not create network interface", err)
+		return "", "", err
+	}
+	nicResp, err := nicPoller.PollUntilDone(ctx, nil)
+	if err != nil {
+		log.Info.Println("Could not create network interface", err)
+		p.deleteFailedInstance(ctx, vmClient, nicClient, vmName, false)
+		return "", "", err
+	}
+
+	vmPoller, err := vmClient.BeginCreateOrUpdate(ctx, p.cred.ResourceGroup, vmName, armcompute.VirtualMachine{
+		Location: to.Ptr(p.cred.Region),
+		Properties: &armcompute.VirtualMachineProperties{
+			HardwareProfile: &armcompute.HardwareProfile{
+				VMSize: to.Ptr(armcompute.VirtualMachineSizeTypes(p.vmConfig.VmSize)),
+			},
+			StorageProfile: &armcompute.StorageProfile{
+				ImageReference: p.imageReference(),
+				OSDisk: &armcompute.OSDisk{
+					CreateOption: to.Ptr(armcompute.DiskCreateOptionTypesFromImage),
+					DeleteOption: to.Ptr(armcompute.DiskDeleteOptionTypesDelete),
+				},
+			},
+			OSProfile: &armcompute.OSProfile{
+				ComputerName:  to.Ptr(vmName),
+				AdminUsername: to.Ptr(p.sshUser),
+				LinuxConfiguration: &armcompute.LinuxConfiguration{
+					DisablePasswordAuthentication: to.Ptr(true),
+					SSH: &armcompute.SSHConfiguration{
+						PublicKeys: []*armcompute.SSHPublicKey{
+							{
+								Path:    to.Ptr("/home/" + p.sshUser + "/.ssh/authorized_keys"),
+								KeyData: to.Ptr(publicKey),
+							},
+						},
+					},
+				},
+			},
+			NetworkProfile: &armcompute.NetworkProfile{
+				NetworkInterfaces: []*armcompute.NetworkInterfaceReference{
+					{
+						ID: nicResp.ID,
+						Properties: &armcompute.NetworkInterfaceReferenceProperties{
+							Primary:      to.Ptr(true),
+							DeleteOption: to.Ptr(armcompute.DeleteOptionsDelete),
+						},
+					},
+				},
+			},
+		},
+	}, nil)
+	if err != nil {
+		log.Info.Println("Could not create instance", err)
+		p.deleteFailedInstance(ctx, vmClient, nicClient, vmName, false)
+		return "", "", err
+	}
+	if _, err = vmPoller.PollUntilDone(ctx, nil); err != nil {
+		log.Info.Println("Could not create instance", err)
+		p.deleteFailedInstance(ctx, vmClient, nicClient, vmName, true)
+		return "", "", err
+	}
+
+	var privateIp string
+	if nicResp.Properties != nil && len(nicResp.Properties.IPConfigurations) > 0 && nicResp.Properties.IPConfigurations[0].Properties != nil &&
+		nicResp.Properties.IPConfigurations[0].Properties.PrivateIPAddress != nil {
+		privateIp = *nicResp.Properties.IPConfigurations[0].Properties.PrivateIPAddress
+	}
+	if privateIp == "" {
+		p.deleteFailedInstance(ctx, vmClient, nicClient, vmName, true)
+		return "", "", errors.New("created instance does not have a private ip address")
+	}
+	log.Info.Println("Created instance, Instance name: ", vmName)
+	log.Info.Println("Created instance, Private IP: ", privateIp)
+
+	return privateIp, vmName, nil
+}
+
+// Input:
+//
+//	instanceId (string): Name of the virtual machine to wait until it is running
+//
+// Caller:
+//
+//	Object of azureProvider
+//
+// Description:
+//
+//	Polls the instance view of the virtual machine every 10 seconds for 10 minutes until
+//	the provisioning has succeeded and the virtual machine is running.
+//
+// Return:
+//
+//	(error): Returns error if the virtual machine is not running even after maximum wait window
+func (p *azureProvider) WaitUntilReady(instanceId string) error {
+	ctx := context.Background()
+	vmClient, _, err := p.clients()
+	if err != nil {
+		return err
+	}
+
+	log.Info.Println("Waiting until instance status to be running.......")
+	for i := 0; i < 60; i++ {
+		instanceView, err := vmClient.InstanceView(ctx, p.cred.ResourceGroup, instanceId, nil)
+		if err != nil {
+			return err
+		}
+		var provisioned, running bool
+		for _, status := range instanceView.Statuses {
+			if status == nil || status.Code == nil {
+				continue
+			}
+			switch *status.Code {
+			case "ProvisioningState/succeeded":
+				provisioned = true
+			case "PowerState/running":
+				running = true
+			}
+		}
+		if provisioned && running {
+			return nil
+		}
+		time.Sleep(10 * time.Second)
+	}
+	log.Error.Println("Instance state is not okay even after maximum wait window")
+	return fmt.Errorf("instance %s is not running even after maximum wait window", instanceId)
+}
+
+// Input:
+//
+//	privateIp (string): private ip address of the instance that needs to be described
+//
+// Caller:
+//
+//	Object of azureProvider
+//
+// Description:
+//
+//	Lists the network interfaces in the configured resource group to find the virtual machine with the private ip address.
+//
+// Return:
+//
+//	(InstanceDetails, error): Returns the details of the instance and error if any
+func (p *azureProvider) DescribeInstance(privateIp string) (InstanceDetails, error) {
+	var instanceDetails InstanceDetails
+	ctx := context.Background()
+	vmClient, nicClient, err := p.clients()
+	if err != nil {
+		return instanceDetails, err
+	}
+
+	pager := nicClient.NewListPager(p.cred.ResourceGroup, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			log.Info.Println("Could not get the description of instance", err)
+			return instanceDetails, err
+		}
+		for _, nic := range page.Value {
+			if nic.Properties == nil || nic.Properties.VirtualMachine == nil || nic.Properties.VirtualMachine.ID == nil {
+				continue
+			}
+			for _, ipConfig := range nic.Properties.IPConfigurations {
+				if ipConfig.Properties == nil || ipConfig.Properties.PrivateIPAddress == nil || *ipConfig.Properties.PrivateIPAddress != privateIp {
+					continue
+				}
+				vmId := *nic.Properties.VirtualMachine.ID
+				instanceDetails.InstanceId = vmId[strings.LastIndex(vmId, "/")+1:]
+				instanceDetails.PrivateIp = privateIp
+				instanceView, err := vmClient.InstanceView(ctx, p.cred.ResourceGroup, instanceDetails.InstanceId, nil)
+				if err != nil {
+					return instanceDetails, err
+				}
+				for _, status := range instanceView.Statuses {
+					if status != nil && status.Code != nil && strings.HasPrefix(*status.Code, "PowerState/") {
+						instanceDetails.State = strings.TrimPrefix(*status.Code, "PowerState/")
+					}
+				}
+				return instanceDetails, nil
+			}
+		}
+	}
+	return instanceDetails, fmt.Errorf("no instance found with private ip %s", privateIp)
+}
+
+// Input:
+//
+//	privateIp (string): private ip address of the instance that needs to be terminated
+//
+// Caller:
+//
+//	Object of azureProvider
+//
+// Description:
+//
+//	Uses the private ip address passed as input to identify the virtual machine.
+//	Deletes the virtual machine, the network interface and OS disk are deleted along with it.
+//
+// Return:
+//
+//	(error): Returns error if any while terminating the instance
+func (p *azureProvider) TerminateInstance(privateIp string) error {
+	instanceDetails, err := p.DescribeInstance(privateIp)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	vmClient, _, err := p.clients()
+	if err != nil {
+		return err
+	}
+
+	log.Info.Println("Terminating instance with name: ", instanceDetails.InstanceId)
+	poller, err := vmClient.BeginDelete(ctx, p.cred.ResourceGroup, instanceDetails.InstanceId, nil)
+	if err != nil {
+		log.Error.Println(err)
+		return err
+	}
+	_, err = poller.PollUntilDone(ctx, nil)
+	return err
+}
+
+// Input:
+//
+//	ctx (context.Context): Request-scoped data that transits processes and APIs.
+//	vmClient (*armcompute.VirtualMachinesClient): The virtual machines client
+//	nicClient (*armnetwork.InterfacesClient): The network interfaces client
+//	vmName (string): Name of the virtual machine which could not be created
+//	isVmCreated (bool): Whether the creation of the virtual machine was started, so that it may be partially created
+//
+// Caller:
+//
+//	Object of azureProvider
+//
+// Description:
+//
+//	Deletes the virtual machine which could not be created and its network interface, so that they are not left
+//	without being known to the provision. The resources which could not be deleted are reported for the operator.
+//
+// Return:
+func (p *azureProvider) deleteFailedInstance(ctx context.Context, vmClient *armcompute.VirtualMachinesClient, nicClient *armnetwork.InterfacesClient, vmName string, isVmCreated bool) {
+	log.Info.Println("Deleting the instance which could not be created: ", vmName)
+	if isVmCreated {
+		poller, err := vmClient.BeginDelete(ctx, p.cred.ResourceGroup, vmName, nil)
+		if err == nil {
+			_, err = poller.PollUntilDone(ctx, nil)
+		}
+		if err != nil && !isAzureNotFound(err) {
+			log.Error.Println("Unable to delete the instance ", vmName, " which could not be created. Please delete it and its network interface manually: ", err)
+			return
+		}
+	}
+	// The network interface is deleted along with the virtual machine, unless it was not attached to it yet
+	poller, err := nicClient.BeginDelete(ctx, p.cred.ResourceGroup, vmName+"-nic", nil)
+	if err == nil {
+		_, err = poller.PollUntilDone(ctx, nil)
+	}
+	if err != nil && !isAzureNotFound(err) {
+		log.Error.Println("Unable to delete the network interface ", vmName+"-nic", " of the instance which could not be created. Please delete it manually: ", err)
+	}
+}
+
+// Input:
+//
+//	err (error): The error returned by Azure
+//
+// Description:
+//
+//	Checks if the Azure resource is not found, Ex: it was never created or it is already deleted.
+//
+// Return:
+//
+//	(bool): Returns true if the resource is not found
+func isAzureNotFound(err error) bool {
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound
+}
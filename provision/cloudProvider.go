@@ -0,0 +1,113 @@
+package provision
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/maplelabs/opensearch-scaling-manager/config"
+)
+
+// This interface contains the cloud specific operations needed to add or remove a node from the cluster.
+// Each supported cloud_type provides its own implementation of the interface.
+type CloudProvider interface {
+	// SpinNewVm creates a new instance and returns the private ip address and instance ID of the instance.
+	SpinNewVm() (string, string, error)
+	// WaitUntilReady waits until the instance with the given instance ID is ready to be configured.
+	WaitUntilReady(instanceId string) error
+	// TerminateInstance terminates the instance with the given private ip address.
+	TerminateInstance(privateIp string) error
+	// DescribeInstance returns the details of the instance with the given private ip address.
+	DescribeInstance(privateIp string) (InstanceDetails, error)
+}
+
+// This struct contains the details of an instance as reported by the cloud provider.
+type InstanceDetails struct {
+	// InstanceId indicates the ID (or name) by which the cloud provider identifies the instance.
+	InstanceId string
+	// PrivateIp indicates the private ip address of the instance.
+	PrivateIp string
+	// State indicates the state of the instance as reported by the cloud provider.
+	State string
+}
+
+// Input:
+//
+//	clusterCfg (config.ClusterDetails): Cluster Level config details
+//
+// Description:
+//
+//	Returns the CloudProvider implementation for the cloud_type specified in the cluster config.
+//
+// Return:
+//
+//	(CloudProvider, error): Returns the cloud provider and error if the cloud type is not supported
+func GetCloudProvider(clusterCfg config.ClusterDetails) (CloudProvider, error) {
+	switch clusterCfg.CloudType {
+	case "AWS":
+		return &awsProvider{
+			launchTemplateId:      clusterCfg.LaunchTemplateId,
+			launchTemplateVersion: clusterCfg.LaunchTemplateVersion,
+			cred:                  clusterCfg.CloudCredentials,
+		}, nil
+	case "GCP":
+		return &gcpProvider{
+			instanceTemplate: clusterCfg.LaunchTemplateId,
+			namePrefix:       instanceNamePrefix(clusterCfg.ClusterName),
+			cred:             clusterCfg.CloudCredentials,
+		}, nil
+	case "AZURE":
+		return &azureProvider{
+			vmConfig:   clusterCfg.AzureVmConfig,
+			sshUser:    clusterCfg.SshUser,
+			namePrefix: instanceNamePrefix(clusterCfg.ClusterName),
+			cred:       clusterCfg.CloudCredentials,
+		}, nil
+	case "LOCAL":
+		return newLocalProvider(clusterCfg), nil
+	}
+	return nil, fmt.Errorf("cloud type %s is not supported for provisioning", clusterCfg.CloudType)
+}
+
+// The characters of the cluster name which are not accepted in an instance name by GCP and Azure.
+var invalidInstanceNameRegex = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// Input:
+//
+//	clusterName (string): Name of the OpenSearch cluster
+//
+// Description:
+//
+//	Converts the cluster name into a prefix that is accepted as an instance name by GCP and Azure.
+//	Only lower case letters, digits and hyphens are retained and the prefix is limited to 40 characters.
+//
+// Return:
+//
+//	(string): Returns the instance name prefix
+func instanceNamePrefix(clusterName string) string {
+	prefix := invalidInstanceNameRegex.ReplaceAllString(strings.ToLower(clusterName), "-")
+	prefix = strings.Trim(prefix, "-")
+	if len(prefix) > 40 {
+		prefix = strings.Trim(prefix[:40], "-")
+	}
+	if prefix == "" || prefix[0] < 'a' || prefix[0] > 'z' {
+		prefix = "os-" + prefix
+	}
+	return prefix
+}
+
+// Input:
+//
+//	prefix (string): Instance name prefix generated from the cluster name
+//
+// Description:
+//
+//	Generates a unique instance name using the prefix and the current time.
+//
+// Return:
+//
+//	(string): Returns the instance name
+func newInstanceName(prefix string) string {
+	return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
+}
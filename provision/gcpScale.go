@@ -0,0 +1,301 @@
+package provision
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	compute "cloud.google.com/go/compute/apiv1"
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"github.com/maplelabs/opensearch-scaling-manager/config"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+	"google.golang.org/protobuf/proto"
+)
+
+// This struct implements the CloudProvider interface for GCP Compute Engine.
+type gcpProvider struct {
+	// instanceTemplate indicates the instance template (name or URL) using which a new instance will be created
+	instanceTemplate string
+	// namePrefix indicates the prefix used while naming the new instances
+	namePrefix string
+	// cred indicates the cloud credentials to connect to GCP
+	cred config.CloudCredentials
+}
+
+// Input:
+//
+//	ctx (context.Context): Request-scoped data that transits processes and APIs.
+//
+// Caller:
+//
+//	Object of gcpProvider
+//
+// Description:
+//
+//	Creates the Compute Engine instances client using the service account key file if specified,
+//	otherwise the application default credentials are used.
+//
+// Return:
+//
+//	(*compute.InstancesClient, error): Returns the instances client and error if any
+func (p *gcpProvider) client(ctx context.Context) (*compute.InstancesClient, error) {
+	var opts []option.ClientOption
+	if p.cred.GcpCredentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(p.cred.GcpCredentialsFile))
+	}
+	return compute.NewInstancesRESTClient(ctx, opts...)
+}
+
+// Input:
+//
+// Caller:
+//
+//	Object of gcpProvider
+//
+// Description:
+//
+//	Creates a new Compute Engine instance using the instance template specified and waits for the insert operation to complete.
+//	Returns the ip address of the created instance for further configuration of Opensearch
+//	If the instance could not be created or its ip address could not be found, the instance is deleted,
+//	as the rollback of the provision identifies the instances only by their ip address.
+//
+// Return:
+//
+//	(string, string, error): Returns the private ip address, instance name of the spinned node and error if any
+func (p *gcpProvider) SpinNewVm() (string, string, error) {
+	ctx := context.Background()
+	client, err := p.client(ctx)
+	if err != nil {
+		log.Error.Println("Unable to create GCP compute client: ", err)
+		return "", "", err
+	}
+	defer client.Close()
+
+	instanceName := newInstanceName(p.namePrefix)
+	log.Info.Println("Creating new instance *************")
+	op, err := client.Insert(ctx, &computepb.InsertInstanceRequest{
+		Project:                p.cred.ProjectId,
+		Zone:                   p.cred.Zone,
+		SourceInstanceTemplate: proto.String(p.instanceTemplate),
+		InstanceResource: &computepb.Instance{
+			Name: proto.String(instanceName),
+		},
+	})
+	if err != nil {
+		log.Info.Println("Could not create instance", err)
+		return "", "", err
+	}
+	if err = op.Wait(ctx); err != nil {
+		log.Info.Println("Could not create instance", err)
+		p.deleteFailedInstance(ctx, client, instanceName)
+		return "", "", err
+	}
+
+	instance, err := client.Get(ctx, &computepb.GetInstanceRequest{
+		Project:  p.cred.ProjectId,
+		Zone:     p.cred.Zone,
+		Instance: instanceName,
+	})
+	if err != nil {
+		log.Error.Println("Could not get the created instance", err)
+		p.deleteFailedInstance(ctx, client, instanceName)
+		return "", "", err
+	}
+	if len(instance.GetNetworkInterfaces()) == 0 {
+		p.deleteFailedInstance(ctx, client, instanceName)
+		return "", "", errors.New("created instance does not have a network interface")
+	}
+
+	privateIp := instance.GetNetworkInterfaces()[0].GetNetworkIP()
+	log.Info.Println("Created instance, Instance name: ", instanceName)
+	log.Info.Println("Created instance, Private IP: ", privateIp)
+
+	return privateIp, instanceName, nil
+}
+
+// Input:
+//
+//	instanceId (string): Name of the Compute Engine instance to wait until it is running
+//
+// Caller:
+//
+//	Object of gcpProvider
+//
+// Description:
+//
+//	Polls the instance every 10 seconds for 10 minutes until the instance status is RUNNING.
+//
+// Return:
+//
+//	(error): Returns error if the instance is not running even after maximum wait window
+func (p *gcpProvider) WaitUntilReady(instanceId string) error {
+	ctx := context.Background()
+	client, err := p.client(ctx)
+	if err != nil {
+		log.Error.Println("Unable to create GCP compute client: ", err)
+		return err
+	}
+	defer client.Close()
+
+	log.Info.Println("Waiting until instance status to be RUNNING.......")
+	for i := 0; i < 60; i++ {
+		instance, err := client.Get(ctx, &computepb.GetInstanceRequest{
+			Project:  p.cred.ProjectId,
+			Zone:     p.cred.Zone,
+			Instance: instanceId,
+		})
+		if err != nil {
+			return err
+		}
+		if instance.GetStatus() == "RUNNING" {
+			return nil
+		}
+		time.Sleep(10 * time.Second)
+	}
+	log.Error.Println("Instance state is not okay even after maximum wait window")
+	return fmt.Errorf("instance %s is not running even after maximum wait window", instanceId)
+}
+
+// Input:
+//
+//	privateIp (string): private ip address of the instance that needs to be described
+//
+// Caller:
+//
+//	Object of gcpProvider
+//
+// Description:
+//
+//	Lists the instances in the configured zone and finds the instance with the private ip address.
+//
+// Return:
+//
+//	(InstanceDetails, error): Returns the details of the instance and error if any
+func (p *gcpProvider) DescribeInstance(privateIp string) (InstanceDetails, error) {
+	var instanceDetails InstanceDetails
+	ctx := context.Background()
+	client, err := p.client(ctx)
+	if err != nil {
+		log.Error.Println("Unable to create GCP compute client: ", err)
+		return instanceDetails, err
+	}
+	defer client.Close()
+
+	it := client.List(ctx, &computepb.ListInstancesRequest{
+		Project: p.cred.ProjectId,
+		Zone:    p.cred.Zone,
+	})
+	for {
+		instance, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.Info.Println("Could not get the description of instance", err)
+			return instanceDetails, err
+		}
+		for _, networkInterface := range instance.GetNetworkInterfaces() {
+			if networkInterface.GetNetworkIP() == privateIp {
+				instanceDetails.InstanceId = instance.GetName()
+				instanceDetails.PrivateIp = privateIp
+				instanceDetails.State = instance.GetStatus()
+				return instanceDetails, nil
+			}
+		}
+	}
+	return instanceDetails, fmt.Errorf("no instance found with private ip %s", privateIp)
+}
+
+// Input:
+//
+//	privateIp (string): private ip address of the instance that needs to be terminated
+//
+// Caller:
+//
+//	Object of gcpProvider
+//
+// Description:
+//
+//	Uses the private ip address passed as input to identify the instance.
+//	Deletes the Compute Engine instance and waits for the operation to complete.
+//
+// Return:
+//
+//	(error): Returns error if any while terminating the instance
+func (p *gcpProvider) TerminateInstance(privateIp string) error {
+	instanceDetails, err := p.DescribeInstance(privateIp)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	client, err := p.client(ctx)
+	if err != nil {
+		log.Error.Println("Unable to create GCP compute client: ", err)
+		return err
+	}
+	defer client.Close()
+
+	log.Info.Println("Terminating instance with name: ", instanceDetails.InstanceId)
+	return p.deleteInstance(ctx, client, instanceDetails.InstanceId)
+}
+
+// Input:
+//
+//	ctx (context.Context): Request-scoped data that transits processes and APIs.
+//	client (*compute.InstancesClient): The Compute Engine client
+//	instanceName (string): Name of the instance to be deleted
+//
+// Caller:
+//
+//	Object of gcpProvider
+//
+// Description:
+//
+//	Deletes the Compute Engine instance and waits for the operation to complete.
+//
+// Return:
+//
+//	(error): Returns error if any while deleting the instance
+func (p *gcpProvider) deleteInstance(ctx context.Context, client *compute.InstancesClient, instanceName string) error {
+	op, err := client.Delete(ctx, &computepb.DeleteInstanceRequest{
+		Project:  p.cred.ProjectId,
+		Zone:     p.cred.Zone,
+		Instance: instanceName,
+	})
+	if err != nil {
+		log.Error.Println(err)
+		return err
+	}
+	return op.Wait(ctx)
+}
+
+// Input:
+//
+//	ctx (context.Context): Request-scoped data that transits processes and APIs.
+//	client (*compute.InstancesClient): The Compute Engine client
+//	instanceName (string): Name of the instance which could not be created
+//
+// Caller:
+//
+//	Object of gcpProvider
+//
+// Description:
+//
+//	Deletes the instance which could not be created, so that it is not left running without being known to the provision.
+//	The instance is reported for the operator if it could not be deleted.
+//
+// Return:
+func (p *gcpProvider) deleteFailedInstance(ctx context.Context, client *compute.InstancesClient, instanceName string) {
+	log.Info.Println("Deleting the instance which could not be created: ", instanceName)
+	var apiErr *googleapi.Error
+	if err := p.deleteInstance(ctx, client, instanceName); errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+		log.Info.Println("The instance ", instanceName, " was not created")
+	} else if err != nil {
+		log.Error.Println("Unable to delete the instance ", instanceName, " which could not be created. Please delete it manually: ", err)
+	}
+}
@@ -0,0 +1,767 @@
+package provision
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/maplelabs/opensearch-scaling-manager/config"
+	osutils "github.com/maplelabs/opensearch-scaling-manager/opensearchUtils"
+	utils "github.com/maplelabs/opensearch-scaling-manager/utilities"
+	"gopkg.in/yaml.v3"
+)
+
+// This struct implements the CloudProvider interface for cloud_type LOCAL.
+// The nodes are started on the same host as local processes or docker containers, each bound to its own loopback address.
+type localProvider struct {
+	// localCfg indicates the local provider configuration
+	localCfg config.LocalConfig
+	// osHome indicates the OpenSearch installation used to start the nodes in process mode
+	osHome string
+	// osVersion indicates the OpenSearch version used to pick the default docker image
+	osVersion string
+	// clusterName indicates the name of the cluster the new nodes join
+	clusterName string
+}
+
+// This struct contains the details of a local node which are persisted in the node directory.
+type localInstance struct {
+	// InstanceId indicates the name of the local node. This is also the name of the node directory.
+	InstanceId string `json:"instance_id"`
+	// PrivateIp indicates the loopback address on which the node is bound.
+	PrivateIp string `json:"private_ip"`
+	// Pid indicates the process ID of the node when started in process mode.
+	Pid int `json:"pid,omitempty"`
+	// ContainerId indicates the container ID of the node when started in docker mode.
+	ContainerId string `json:"container_id,omitempty"`
+}
+
+const localInstanceFile = "instance.json"
+
+// Input:
+//
+//	clusterCfg (config.ClusterDetails): Cluster Level config details
+//
+// Description:
+//
+//	Creates the local provider using the local_config and the OpenSearch details of the cluster config.
+//
+// Return:
+//
+//	(*localProvider): Returns the local provider
+func newLocalProvider(clusterCfg config.ClusterDetails) *localProvider {
+	return &localProvider{
+		localCfg:    clusterCfg.LocalConfig,
+		osHome:      clusterCfg.OpensearchHome,
+		osVersion:   clusterCfg.OpensearchVersion,
+		clusterName: clusterCfg.ClusterName,
+	}
+}
+
+// Input:
+//
+// Caller:
+//
+//	Object of localProvider
+//
+// Description:
+//
+//	Returns the docker image used to start the nodes in docker mode.
+//
+// Return:
+//
+//	(string): Returns the docker image
+func (p *localProvider) dockerImage() string {
+	if p.localCfg.DockerImage != "" {
+		return p.localCfg.DockerImage
+	}
+	return "opensearchproject/opensearch:" + p.osVersion
+}
+
+// Input:
+//
+// Caller:
+//
+//	Object of localProvider
+//
+// Description:
+//
+//	Returns the JVM options used to start the nodes.
+//
+// Return:
+//
+//	(string): Returns the JVM options
+func (p *localProvider) javaOpts() string {
+	heapSize := p.localCfg.HeapSize
+	if heapSize == "" {
+		heapSize = "512m"
+	}
+	return fmt.Sprintf("-Xms%s -Xmx%s", heapSize, heapSize)
+}
+
+// Input:
+//
+// Caller:
+//
+//	Object of localProvider
+//
+// Description:
+//
+//	Reads all the local nodes present in the work directory.
+//
+// Return:
+//
+//	([]localInstance, error): Returns the local nodes and error if any
+func (p *localProvider) instances() ([]localInstance, error) {
+	var instances []localInstance
+	entries, err := os.ReadDir(p.localCfg.WorkDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return instances, nil
+		}
+		return instances, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(p.localCfg.WorkDir, entry.Name(), localInstanceFile))
+		if err != nil {
+			continue
+		}
+		var instance localInstance
+		if err = json.Unmarshal(content, &instance); err != nil {
+			log.Warn.Println("Unable to parse the local node details of ", entry.Name(), err)
+			continue
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+// Input:
+//
+//	instance (localInstance): Details of the local node
+//
+// Caller:
+//
+//	Object of localProvider
+//
+// Description:
+//
+//	Persists the details of the local node in the node directory.
+//
+// Return:
+//
+//	(error): Returns error if any
+func (p *localProvider) saveInstance(instance localInstance) error {
+	content, err := json.Marshal(instance)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(p.localCfg.WorkDir, instance.InstanceId, localInstanceFile), content, 0644)
+}
+
+// Input:
+//
+//	privateIp (string): loopback address of the local node
+//
+// Caller:
+//
+//	Object of localProvider
+//
+// Description:
+//
+//	Finds the local node bound to the loopback address.
+//
+// Return:
+//
+//	(localInstance, error): Returns the details of the local node and error if it is not found
+func (p *localProvider) findInstance(privateIp string) (localInstance, error) {
+	instances, err := p.instances()
+	if err != nil {
+		return localInstance{}, err
+	}
+	for _, instance := range instances {
+		if instance.PrivateIp == privateIp {
+			return instance, nil
+		}
+	}
+	return localInstance{}, fmt.Errorf("no local node found with private ip %s", privateIp)
+}
+
+// Input:
+//
+// Caller:
+//
+//	Object of localProvider
+//
+// Description:
+//
+//	Allocates a free loopback address (127.0.0.2 - 127.0.0.254) for a new local node and creates the node directory.
+//	The addresses already used by the local nodes and by the nodes of the cluster are skipped.
+//
+// Return:
+//
+//	(string, string, error): Returns the loopback address, name of the local node and error if any
+func (p *localProvider) SpinNewVm() (string, string, error) {
+	if err := os.MkdirAll(p.localCfg.WorkDir, 0755); err != nil {
+		log.Error.Println("Unable to create the work directory for local nodes: ", err)
+		return "", "", err
+	}
+
+	usedIps := make(map[string]bool)
+	instances, err := p.instances()
+	if err != nil {
+		return "", "", err
+	}
+	for _, instance := range instances {
+		usedIps[instance.PrivateIp] = true
+	}
+	for _, nodeIdInfo := range utils.GetNodes() {
+		usedIps[nodeIdInfo.(map[string]string)["hostIp"]] = true
+	}
+
+	log.Info.Println("Creating new local node *************")
+	for i := 2; i < 255; i++ {
+		privateIp := fmt.Sprintf("127.0.0.%d", i)
+		if usedIps[privateIp] {
+			continue
+		}
+		instanceId := "node-" + strings.ReplaceAll(privateIp, ".", "-")
+		// Mkdir fails if the directory exists, so that two scaling managers never pick the same address
+		if err = os.Mkdir(filepath.Join(p.localCfg.WorkDir, instanceId), 0755); err != nil {
+			if errors.Is(err, os.ErrExist) {
+				continue
+			}
+			return "", "", err
+		}
+		if err = p.saveInstance(localInstance{InstanceId: instanceId, PrivateIp: privateIp}); err != nil {
+			return "", "", err
+		}
+		log.Info.Println("Created local node, Name: ", instanceId)
+		log.Info.Println("Created local node, Private IP: ", privateIp)
+		return privateIp, instanceId, nil
+	}
+	return "", "", errors.New("no free loopback address left for a new local node")
+}
+
+// Input:
+//
+//	instanceId (string): Name of the local node
+//
+// Caller:
+//
+//	Object of localProvider
+//
+// Description:
+//
+//	Checks that the loopback address of the node can be bound and that OpenSearch can be started,
+//	i.e. the opensearch binary is present in process mode or the docker image is available in docker mode.
+//
+// Return:
+//
+//	(error): Returns error if the local node cannot be started
+func (p *localProvider) WaitUntilReady(instanceId string) error {
+	content, err := os.ReadFile(filepath.Join(p.localCfg.WorkDir, instanceId, localInstanceFile))
+	if err != nil {
+		return err
+	}
+	var instance localInstance
+	if err = json.Unmarshal(content, &instance); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", instance.PrivateIp+":0")
+	if err != nil {
+		log.Error.Println("Unable to bind to the loopback address of the local node: ", err)
+		return err
+	}
+	listener.Close()
+
+	if p.localCfg.Mode == "docker" {
+		if exec.Command("docker", "image", "inspect", p.dockerImage()).Run() == nil {
+			return nil
+		}
+		log.Info.Println("Pulling docker image ", p.dockerImage())
+		output, err := exec.Command("docker", "pull", p.dockerImage()).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("unable to pull docker image %s: %v: %s", p.dockerImage(), err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+	_, err = os.Stat(filepath.Join(p.osHome, "bin", "opensearch"))
+	return err
+}
+
+// Input:
+//
+//	privateIp (string): loopback address of the local node that needs to be described
+//
+// Caller:
+//
+//	Object of localProvider
+//
+// Description:
+//
+//	Returns the details of the local node along with its state (created, running or stopped).
+//
+// Return:
+//
+//	(InstanceDetails, error): Returns the details of the local node and error if any
+func (p *localProvider) DescribeInstance(privateIp string) (InstanceDetails, error) {
+	var instanceDetails InstanceDetails
+	instance, err := p.findInstance(privateIp)
+	if err != nil {
+		return instanceDetails, err
+	}
+	instanceDetails.InstanceId = instance.InstanceId
+	instanceDetails.PrivateIp = instance.PrivateIp
+	instanceDetails.State = "created"
+	if instance.ContainerId != "" {
+		output, err := exec.Command("docker", "inspect", "-f", "{{.State.Status}}", instance.ContainerId).Output()
+		if err != nil {
+			instanceDetails.State = "stopped"
+		} else {
+			instanceDetails.State = strings.TrimSpace(string(output))
+		}
+	} else if instance.Pid != 0 {
+		if p.isNodeProcess(instance) {
+			instanceDetails.State = "running"
+		} else {
+			instanceDetails.State = "stopped"
+		}
+	}
+	return instanceDetails, nil
+}
+
+// Input:
+//
+//	privateIp (string): loopback address of the local node that needs to be terminated
+//
+// Caller:
+//
+//	Object of localProvider
+//
+// Description:
+//
+//	Stops the local node if it is running and removes the node directory.
+//
+// Return:
+//
+//	(error): Returns error if any while terminating the local node
+func (p *localProvider) TerminateInstance(privateIp string) error {
+	instance, err := p.findInstance(privateIp)
+	if err != nil {
+		return err
+	}
+	log.Info.Println("Terminating local node with name: ", instance.InstanceId)
+	if err = p.stopNode(instance); err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(p.localCfg.WorkDir, instance.InstanceId))
+}
+
+// Input:
+//
+//	instance (localInstance): Details of the local node that needs to be started
+//	seedHosts ([]string): Ip addresses of the current nodes of the cluster
+//
+// Caller:
+//
+//	Object of localProvider
+//
+// Description:
+//
+//	Starts OpenSearch on the local node so that it joins the cluster using the seed hosts.
+//	In process mode the configuration of os_home is copied into the node directory and the node specific settings are overridden.
+//	In docker mode the settings are passed as environment variables to a container running in the host network.
+//
+// Return:
+//
+//	(error): Returns error if any while starting the local node
+func (p *localProvider) startNode(instance localInstance, seedHosts []string) error {
+	nodeDir := filepath.Join(p.localCfg.WorkDir, instance.InstanceId)
+	settings := map[string]string{
+		"cluster.name":         p.clusterName,
+		"node.name":            instance.InstanceId,
+		"network.host":         instance.PrivateIp,
+		"http.port":            "9200",
+		"transport.port":       "9300",
+		"discovery.seed_hosts": strings.Join(seedHosts, ","),
+	}
+	for key, value := range p.localCfg.Settings {
+		settings[key] = value
+	}
+
+	if p.localCfg.Mode == "docker" {
+		args := []string{"run", "-d", "--name", instance.InstanceId, "--network", "host", "-e", "OPENSEARCH_JAVA_OPTS=" + p.javaOpts()}
+		for key, value := range settings {
+			args = append(args, "-e", key+"="+value)
+		}
+		args = append(args, p.dockerImage())
+		output, err := exec.Command("docker", args...).Output()
+		if err != nil {
+			return fmt.Errorf("unable to start docker container for %s: %v", instance.InstanceId, err)
+		}
+		instance.ContainerId = strings.TrimSpace(string(output))
+		return p.saveInstance(instance)
+	}
+
+	confDir := filepath.Join(nodeDir, "config")
+	if err := copyDir(filepath.Join(p.osHome, "config"), confDir); err != nil {
+		log.Error.Println("Unable to copy the OpenSearch configuration: ", err)
+		return err
+	}
+	settings["path.data"] = filepath.Join(nodeDir, "data")
+	settings["path.logs"] = filepath.Join(nodeDir, "logs")
+	if err := writeLocalNodeSettings(filepath.Join(confDir, "opensearch.yml"), settings); err != nil {
+		log.Error.Println("Unable to write the OpenSearch configuration: ", err)
+		return err
+	}
+
+	pidFile := filepath.Join(nodeDir, "opensearch.pid")
+	cmd := exec.Command(filepath.Join(p.osHome, "bin", "opensearch"), "-d", "-p", pidFile)
+	cmd.Env = append(os.Environ(), "OPENSEARCH_PATH_CONF="+confDir, "OPENSEARCH_JAVA_OPTS="+p.javaOpts())
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("unable to start opensearch for %s: %v: %s", instance.InstanceId, err, strings.TrimSpace(string(output)))
+	}
+	// The pid file is written by OpenSearch once the process is daemonized
+	for i := 0; i < 30; i++ {
+		content, err := os.ReadFile(pidFile)
+		if err == nil {
+			pid, convErr := strconv.Atoi(strings.TrimSpace(string(content)))
+			if convErr == nil {
+				instance.Pid = pid
+				return p.saveInstance(instance)
+			}
+		}
+		time.Sleep(1 * time.Second)
+	}
+	return fmt.Errorf("opensearch for %s did not write the pid file", instance.InstanceId)
+}
+
+// Input:
+//
+//	instance (localInstance): Details of the local node that needs to be stopped
+//
+// Caller:
+//
+//	Object of localProvider
+//
+// Description:
+//
+//	Stops the container in docker mode. In process mode the process is terminated gracefully
+//	and killed if it is still running after a minute. The process is signalled only if it is still the OpenSearch of the node,
+//	as the PID may belong to another process once the node stopped, and the node is then recorded as stopped.
+//
+// Return:
+//
+//	(error): Returns error if any while stopping the local node
+func (p *localProvider) stopNode(instance localInstance) error {
+	if instance.ContainerId != "" {
+		output, err := exec.Command("docker", "rm", "-f", instance.ContainerId).CombinedOutput()
+		if err != nil && !strings.Contains(string(output), "No such container") {
+			return fmt.Errorf("unable to remove docker container for %s: %v: %s", instance.InstanceId, err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+	if instance.Pid == 0 {
+		return nil
+	}
+	if err := p.killNode(instance); err != nil {
+		return err
+	}
+	instance.Pid = 0
+	return p.saveInstance(instance)
+}
+
+// Input:
+//
+//	instance (localInstance): Details of the local node that needs to be stopped
+//
+// Caller:
+//
+//	Object of localProvider
+//
+// Description:
+//
+//	Terminates the OpenSearch process of the node gracefully and kills it if it is still running after a minute.
+//
+// Return:
+//
+//	(error): Returns error if any while stopping the process
+func (p *localProvider) killNode(instance localInstance) error {
+	if !p.isNodeProcess(instance) {
+		return nil
+	}
+	if err := syscall.Kill(instance.Pid, syscall.SIGTERM); err != nil {
+		if errors.Is(err, syscall.ESRCH) {
+			return nil
+		}
+		return err
+	}
+	for i := 0; i < 60; i++ {
+		if !p.isNodeProcess(instance) {
+			return nil
+		}
+		time.Sleep(1 * time.Second)
+	}
+	log.Warn.Println("Local node did not stop gracefully, killing the process: ", instance.Pid)
+	if err := syscall.Kill(instance.Pid, syscall.SIGKILL); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return err
+	}
+	return nil
+}
+
+// Input:
+//
+//	instance (localInstance): Details of the local node
+//
+// Caller:
+//
+//	Object of localProvider
+//
+// Description:
+//
+//	Checks if the PID of the node is running the OpenSearch of the node, whose command line has the configuration in the node directory
+//	(-Dopensearch.path.conf).
+//
+// Return:
+//
+//	(bool): Returns true if the OpenSearch of the node is running
+func (p *localProvider) isNodeProcess(instance localInstance) bool {
+	cmdline, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(instance.Pid), "cmdline"))
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(cmdline), filepath.Join(p.localCfg.WorkDir, instance.InstanceId))
+}
+
+// Input:
+//
+//	clusterCfg (config.ClusterDetails): Cluster Level config details
+//	newNodeIp (string): loopback address of the new local node
+//
+// Description:
+//
+//	Local configurator used in place of the scale_up ansible playbook when cloud_type is LOCAL.
+//	Configures OpenSearch on the new local node with the current nodes as seed hosts and starts it.
+//
+// Return:
+//
+//	(error): Returns error if any while configuring the new node
+func configureLocalNode(clusterCfg config.ClusterDetails, newNodeIp string) error {
+	provider := newLocalProvider(clusterCfg)
+	instance, err := provider.findInstance(newNodeIp)
+	if err != nil {
+		return err
+	}
+	var seedHosts []string
+	for _, nodeIdInfo := range utils.GetNodes() {
+		seedHosts = append(seedHosts, nodeIdInfo.(map[string]string)["hostIp"])
+	}
+	log.Info.Println("Starting local node ", instance.InstanceId, " with seed hosts ", seedHosts)
+	return provider.startNode(instance, seedHosts)
+}
+
+// Input:
+//
+//	clusterCfg (config.ClusterDetails): Cluster Level config details
+//	removeNodeIps ([]string): loopback addresses of the local nodes to be removed
+//	removeNodeNames ([]string): Names of the nodes to be removed
+//
+// Description:
+//
+//	Local configurator used in place of the scale_down ansible playbook when cloud_type is LOCAL.
+//	Excludes the nodes from allocation, waits for the documents to move out of the nodes, stops the nodes
+//	and clears the exclusion. The wait stops once the provision needs to stop, Ex: the shards can not be moved and the phase timed out.
+//
+// Return:
+//
+//	(error): Returns error if any while removing the nodes or why the provision needs to stop
+func removeLocalNodes(ctx context.Context, clusterCfg config.ClusterDetails, removeNodeIps, removeNodeNames []string) error {
+	provider := newLocalProvider(clusterCfg)
+	var instances []localInstance
+	for _, removeNodeIp := range removeNodeIps {
+		instance, err := provider.findInstance(removeNodeIp)
+		if err != nil {
+			return err
+		}
+		instances = append(instances, instance)
+	}
+
+	if err := setAllocationExclude(strings.Join(removeNodeIps, ",")); err != nil {
+		return err
+	}
+
+	for _, removeNodeName := range removeNodeNames {
+		for {
+			docsCount, err := nodeDocsCount(removeNodeName)
+			if err != nil {
+				return err
+			}
+			if docsCount == 0 {
+				break
+			}
+			log.Info.Println("Still moving shards across the cluster. Documents left on ", removeNodeName, ": ", docsCount)
+			if err := sleepUnlessStopped(ctx, 5*time.Second); err != nil {
+				return err
+			}
+		}
+	}
+	// The nodes are not stopped once the provision needs to stop, as the rollback clears the allocation excludes
+	if err := stopCause(ctx); err != nil {
+		return err
+	}
+
+	for _, instance := range instances {
+		if err := provider.stopNode(instance); err != nil {
+			return err
+		}
+	}
+	return setAllocationExclude("")
+}
+
+// Input:
+//
+//	ip (string): Ip address to be excluded from allocation. An empty string clears the exclusion.
+//
+// Description:
+//
+//	Updates the transient cluster setting cluster.routing.allocation.exclude._ip
+//
+// Return:
+//
+//	(error): Returns error if any while updating the setting
+func setAllocationExclude(ip string) error {
+	resp, err := osutils.PutClusterSettings(context.Background(), fmt.Sprintf(`{"transient": {"cluster.routing.allocation.exclude._ip": "%s"}}`, ip))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.IsError() {
+		return fmt.Errorf("unable to update the allocation exclusion: %s", resp.String())
+	}
+	return nil
+}
+
+// Input:
+//
+//	nodeName (string): Name of the node
+//
+// Description:
+//
+//	Fetches the number of documents present on the node.
+//
+// Return:
+//
+//	(int64, error): Returns the document count and error if any
+func nodeDocsCount(nodeName string) (int64, error) {
+	resp, err := osutils.GetNodeStats(context.Background(), []string{nodeName}, []string{"indices"})
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	var nodeStats struct {
+		Nodes map[string]struct {
+			Indices struct {
+				Docs struct {
+					Count int64 `json:"count"`
+				} `json:"docs"`
+			} `json:"indices"`
+		} `json:"nodes"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&nodeStats); err != nil {
+		return 0, err
+	}
+	for _, node := range nodeStats.Nodes {
+		return node.Indices.Docs.Count, nil
+	}
+	return 0, fmt.Errorf("node %s not found in the cluster", nodeName)
+}
+
+// Input:
+//
+//	fileName (string): Path of the opensearch.yml of the local node
+//	settings (map[string]string): Node specific settings
+//
+// Description:
+//
+//	Overrides the node specific settings in opensearch.yml. Settings that only apply while bootstrapping
+//	a new cluster or to a single node cluster are removed as the node joins an existing cluster.
+//
+// Return:
+//
+//	(error): Returns error if any
+func writeLocalNodeSettings(fileName string, settings map[string]string) error {
+	current := make(map[string]interface{})
+	content, err := os.ReadFile(fileName)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err = yaml.Unmarshal(content, &current); err != nil {
+		return err
+	}
+	for _, key := range []string{"discovery.type", "cluster.initial_master_nodes", "cluster.initial_cluster_manager_nodes"} {
+		delete(current, key)
+	}
+	for key, value := range settings {
+		current[key] = value
+	}
+	content, err = yaml.Marshal(current)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, content, 0644)
+}
+
+// Input:
+//
+//	src (string): Source directory
+//	dst (string): Destination directory
+//
+// Description:
+//
+//	Copies the directory recursively preserving the file permissions.
+//
+// Return:
+//
+//	(error): Returns error if any
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, relPath)
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm())
+		}
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(target, os.O_RDWR|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		_, err = io.Copy(out, in)
+		return err
+	})
+}
@@ -0,0 +1,226 @@
+package provision
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/maplelabs/opensearch-scaling-manager/config"
+)
+
+// ErrProvisionAborted is returned when the provision in progress is aborted by an operator.
+var ErrProvisionAborted = errors.New("the provision was aborted by an operator")
+
+// This struct contains a scale requested by an operator, which is provisioned by the leader.
+type ManualScaleRequest struct {
+	// Operation indicates the operation requested. i.e., scale_up/scale_down
+	Operation string
+	// NumNodes indicates the number of nodes to be added or removed
+	NumNodes int
+	// Reason indicates why the operator requested the scale
+	Reason string
+	// RequestedBy indicates the operator and the node from which the scale was requested
+	RequestedBy string
+	// RequestedTime indicates when the scale was requested
+	RequestedTime int64
+}
+
+// Inputs:
+//
+//	reason (string): Why the recommendations are paused
+//
+// Description:
+//
+//	Pauses the recommendations on all the nodes. The provision in progress and the scales requested by an operator are not affected.
+//
+// Return:
+//
+//	(error): Returns error if the state could not be updated
+func PauseRecommendations(reason string) error {
+	err := modifyState(false, func() bool {
+		state.Paused = true
+		state.PauseReason = reason
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	log.Info.Println(fmt.Sprintf("The recommendations are paused by operator@%s: %s", getActor(), reason))
+	return nil
+}
+
+// Inputs:
+//
+// Description:
+//
+//	Resumes the recommendations paused by PauseRecommendations.
+//
+// Return:
+//
+//	(error): Returns error if the recommendations are not paused or the state could not be updated
+func ResumeRecommendations() error {
+	var notPaused bool
+	err := modifyState(false, func() bool {
+		if !state.Paused {
+			notPaused = true
+			return false
+		}
+		state.Paused = false
+		state.PauseReason = ""
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if notPaused {
+		return fmt.Errorf("the recommendations are not paused")
+	}
+	log.Info.Println(fmt.Sprintf("The recommendations are resumed by operator@%s", getActor()))
+	return nil
+}
+
+// Inputs:
+//
+// Description:
+//
+//	Requests the provision in progress to be aborted. The leader stops the provision and rolls it back as a failed provision.
+//	A scale down terminating the instances of the removed nodes is aborted only once they are terminated, as it can not be rolled back.
+//
+// Return:
+//
+//	(error): Returns error if there is no provision in progress or the state could not be updated
+func RequestAbort() error {
+	var abortErr error
+	err := modifyState(false, func() bool {
+		operation := state.CurrentState.Operation()
+		if operation == "" || state.CurrentState == failedState(operation) {
+			abortErr = fmt.Errorf("the state is %s, there is no provision in progress to abort", state.CurrentState)
+			return false
+		}
+		if state.AbortRequested {
+			return false
+		}
+		state.AbortRequested = true
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if abortErr != nil {
+		return abortErr
+	}
+	log.Info.Println(fmt.Sprintf("The abort of the provision in the state %s is requested by operator@%s", state.CurrentState, getActor()))
+	return nil
+}
+
+// Inputs:
+//
+//	clusterCfg (config.ClusterDetails): Cluster Level config details
+//	usrCfg (config.UserConfig): User defined config for application behavior
+//	operation (string): The operation requested (scale_up or scale_down)
+//	numNodes (int): Number of nodes to be added or removed
+//	reason (string): Why the scale is requested
+//
+// Description:
+//
+//	Requests the leader to provision a scale, which is checked against the max and min nodes of the cluster.
+//	The scale is provisioned even if the recommendations are paused or in their cooldown.
+//	The scale is rejected in dry run mode, as it would only be recorded as a dry run rather than provisioned.
+//
+// Return:
+//
+//	(error): Returns error if the scale can not be provisioned or the state could not be updated
+func RequestScale(clusterCfg config.ClusterDetails, usrCfg config.UserConfig, operation string, numNodes int, reason string) error {
+	if usrCfg.DryRun {
+		return fmt.Errorf("dry_run is enabled in the config, the scale would only be recorded as a dry run. Disable dry_run to scale the cluster")
+	}
+	if operation != "scale_up" && operation != "scale_down" {
+		return fmt.Errorf("invalid operation %s, it can be scale_up or scale_down", operation)
+	}
+	if numNodes <= 0 {
+		return fmt.Errorf("the number of nodes needs to be greater than 0")
+	}
+	if err := validateNumNodes(operation, numNodes, getCurrentNumNodes(usrCfg), clusterCfg); err != nil {
+		return err
+	}
+	request := &ManualScaleRequest{
+		Operation:     operation,
+		NumNodes:      numNodes,
+		Reason:        reason,
+		RequestedBy:   "operator@" + getActor(),
+		RequestedTime: time.Now().UnixMilli(),
+	}
+	var requestErr error
+	err := modifyState(false, func() bool {
+		if state.CurrentState != StateNormal {
+			requestErr = fmt.Errorf("the state is %s, a provision is already in progress", state.CurrentState)
+			return false
+		}
+		if state.ManualScale != nil {
+			requestErr = fmt.Errorf("the %s of %d node(s) requested by %s is not provisioned yet", state.ManualScale.Operation, state.ManualScale.NumNodes, state.ManualScale.RequestedBy)
+			return false
+		}
+		state.ManualScale = request
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return requestErr
+}
+
+// Inputs:
+//
+//	clusterCfg (config.ClusterDetails): Cluster Level config details
+//	usrCfg (config.UserConfig): User defined config for application behavior
+//
+// Description:
+//
+//	Provisions the scale requested by an operator through the same checks as the recommendations, Ex: the max and min nodes,
+//	which are checked again as the cluster may have changed since the request.
+//	The request is removed from the state before it is provisioned, so that it is provisioned only once.
+//
+// Return:
+//
+//	(bool): Returns true if a scale was requested
+func TriggerManualScale(clusterCfg config.ClusterDetails, usrCfg config.UserConfig, t *time.Time) bool {
+	var request *ManualScaleRequest
+	err := modifyState(true, func() bool {
+		if state.CurrentState != StateNormal || state.ManualScale == nil {
+			return false
+		}
+		request = state.ManualScale
+		state.ManualScale = nil
+		return true
+	})
+	if err != nil || request == nil {
+		return false
+	}
+	taskName := fmt.Sprintf("%s_by_%d", request.Operation, request.NumNodes)
+	if usrCfg.DryRun {
+		log.Warn.Println(fmt.Sprintf("dry_run was enabled after the %s was requested by %s, it is only recorded as a dry run", taskName, request.RequestedBy))
+	}
+	log.Info.Println(fmt.Sprintf("Provisioning the %s requested by %s: %s", taskName, request.RequestedBy, request.Reason))
+	provisionTask(clusterCfg, usrCfg, t, taskName, request.Operation, request.NumNodes, fmt.Sprintf("manual scale by %s: %s", request.RequestedBy, request.Reason))
+	return true
+}
+
+// Input:
+//
+// Caller:
+//
+//	Object of type State
+//
+// Description:
+//
+//	Checks if an operator requested the provision to be aborted and it can be aborted in the current state.
+//
+// Return:
+//
+//	(error): Returns ErrProvisionAborted if the provision needs to be aborted
+func (s *State) checkAbort() error {
+	if !s.AbortRequested || s.CurrentState == StateScaleDownOnCluster {
+		return nil
+	}
+	return ErrProvisionAborted
+}
@@ -18,7 +18,10 @@ import (
 	utils "github.com/maplelabs/opensearch-scaling-manager/utilities"
 	"net/http"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/maplelabs/opensearch-scaling-manager/logger"
@@ -51,6 +54,7 @@ func init() {
 // Description:
 //
 //	TriggerProvision will call scale in/out the cluster based on the operation.
+//	The failed phases are retried and the failed provision is rolled back by ContinueProvision.
 //	ToDo:
 //	        Think about the scenario where event based scaling needs to be performed.
 //	        Morning need to scale up and evening need to scale down.
@@ -61,92 +65,67 @@ func init() {
 func TriggerProvision(clusterCfg config.ClusterDetails, usrCfg config.UserConfig, numNodes int, t *time.Time, operation, RulesResponsible string) {
 	state.GetCurrentState()
 	if operation == "scale_up" {
-		state.PreviousState = state.CurrentState
-		state.CurrentState = "provisioning_scaleup"
 		state.NumNodes = numNodes
 		state.RemainingNodes = numNodes
 		state.RuleTriggered = "scale_up"
 		state.RulesResponsible = RulesResponsible
-		state.UpdateState()
-		isScaledUp, err := ScaleOut(clusterCfg, usrCfg, t)
-		if isScaledUp {
-			log.Info.Println("Scaleup successful")
-			PushToOs("Success", err)
-		} else {
-			log.Error.Println(err)
-			state.GetCurrentState()
-			// Add a retry mechanism
-			state.PreviousState = state.CurrentState
-			state.CurrentState = "provisioning_scaleup_failed"
-			state.UpdateState()
-			PushToOs("Failed", err)
+		if err := state.Transition(StateProvisioningScaleUp); err != nil {
+			log.Warn.Println("The scale up can not be provisioned: ", err)
+			return
 		}
-		// Set the state back to normal to continue further
-		SetStateBackToNormal()
+		ContinueProvision(clusterCfg, usrCfg, t)
 	} else if operation == "scale_down" {
-		state.PreviousState = state.CurrentState
-		state.CurrentState = "provisioning_scaledown"
 		state.NumNodes = numNodes
 		state.RemainingNodes = numNodes
 		state.RuleTriggered = "scale_down"
 		state.RulesResponsible = RulesResponsible
-		state.UpdateState()
-		isScaledDown, err := ScaleIn(clusterCfg, usrCfg, t)
-		if isScaledDown {
-			log.Info.Println("Scaledown successful")
-			PushToOs("Success", err)
-		} else {
-			log.Error.Println(err)
-			state.GetCurrentState()
-			// Add a retry mechanism
-			state.PreviousState = state.CurrentState
-			state.CurrentState = "provisioning_scaledown_failed"
-			state.UpdateState()
-			PushToOs("Failed", err)
+		if err := state.Transition(StateProvisioningScaleDown); err != nil {
+			log.Warn.Println("The scale down can not be provisioned: ", err)
+			return
 		}
-		// Set the state back to normal to continue further
-		SetStateBackToNormal()
+		ContinueProvision(clusterCfg, usrCfg, t)
 	}
 }
 
 // Input:
 //
+//	ctx (context.Context): Context of the provision, which is done once the provision is timed out
 //	clusterCfg (config.ClusterDetails): Cluster Level config details
 //	usrCfg (config.UserConfig): User defined config for applicatio behavior
 //
 // Description:
 //
 //	ScaleOut will scale out the cluster with the number of nodes.
-//	This function will invoke commands to create a VM based on cloud type.
-//	Then it will configure the opensearch on newly created nodes.
+//	This function will invoke commands to create the VMs in parallel based on cloud type.
+//	Then it will configure the opensearch on all the newly created nodes in a single run.
+//	Every step persists its progress in the state so that a new master can resume the scale out.
 //
 // Return:
 //
 //	(bool): Return the status of scale out of the nodes.
-func ScaleOut(clusterCfg config.ClusterDetails, usrCfg config.UserConfig, t *time.Time) (bool, error) {
+func ScaleOut(ctx context.Context, clusterCfg config.ClusterDetails, usrCfg config.UserConfig, t *time.Time) (bool, error) {
 	// Read the current state of scaleup process and proceed with next step
 	// If no stage was already set. The function returns an empty string. Then, start the scaleup process
 	state.GetCurrentState()
 	crypto.GetDecryptedCloudCreds(&clusterCfg.CloudCredentials)
 	crypto.GetDecryptedOsCreds(&clusterCfg.OsCredentials)
-	var newNodeIp, newInstanceId string
+	var newNodeIps, newInstanceIds []string
 	simFlag := usrCfg.MonitorWithSimulator
 	monitorWithLogs := usrCfg.MonitorWithLogs
 	isAccelerated := usrCfg.IsAccelerated
 
 	switch state.CurrentState {
-	case "provisioning_scaleup":
+	case StateProvisioningScaleUp:
 		log.Info.Println("Starting scaleUp process")
 		if simFlag && isAccelerated {
 			fakeSleep(t)
 		}
-		state.PreviousState = state.CurrentState
-		state.CurrentState = "start_scaleup_process"
-		state.ProvisionStartTime = time.Now().UnixMilli()
-		state.UpdateState()
+		if err := state.transitionUnlessStopped(ctx, StateStartScaleUp); err != nil {
+			return false, err
+		}
 		fallthrough
 		// Spin new VMs based on number of nodes and cloud type
-	case "start_scaleup_process":
+	case StateStartScaleUp:
 		if monitorWithLogs {
 			log.Info.Println("Spin new vms based on the cloud type")
 			time.Sleep(time.Duration(usrCfg.RecommendationPollingInterval) * time.Second)
@@ -159,25 +138,26 @@ func ScaleOut(clusterCfg config.ClusterDetails, usrCfg config.UserConfig, t *tim
 				fakeSleep(t)
 			}
 		} else {
-			var err error
-			newNodeIp, newInstanceId, err = SpinNewVm(clusterCfg.LaunchTemplateId, clusterCfg.LaunchTemplateVersion, clusterCfg.CloudCredentials)
+			provider, err := GetCloudProvider(clusterCfg)
 			if err != nil {
 				return false, err
 			}
+			err = spinNewVms(provider)
+			if err != nil {
+				return false, err
+			}
+		}
+		log.Info.Println("Spinned new nodes: ", state.NodeIps)
+		if err := state.transitionUnlessStopped(ctx, StateScaleUpSpinVm); err != nil {
+			return false, err
 		}
-		log.Info.Println("Spinned a new node: ", newNodeIp)
-		state.NodeIp = newNodeIp
-		state.InstanceId = newInstanceId
-		state.PreviousState = state.CurrentState
-		state.CurrentState = "scaleup_triggered_spin_vm"
-		state.UpdateState()
 		fallthrough
-	// Add the newly added VM to the list of VMs
-	// Configure OS on newly created VM
-	case "scaleup_triggered_spin_vm":
+	// Add the newly added VMs to the list of VMs
+	// Configure OS on newly created VMs
+	case StateScaleUpSpinVm:
 		state.GetCurrentState()
-		newNodeIp = state.NodeIp
-		newInstanceId = state.InstanceId
+		newNodeIps = state.NodeIps
+		newInstanceIds = state.InstanceIds
 		if monitorWithLogs {
 			log.Info.Println("Adding the spinned nodes into the list of vms")
 			time.Sleep(time.Duration(usrCfg.RecommendationPollingInterval) * time.Second)
@@ -193,19 +173,37 @@ func ScaleOut(clusterCfg config.ClusterDetails, usrCfg config.UserConfig, t *tim
 			if simFlag && isAccelerated {
 				fakeSleep(t)
 			}
-		} else {
-			statusErr := InstanceStatusCheck(newInstanceId, clusterCfg.CloudCredentials)
+		} else if clusterCfg.CloudType == "LOCAL" {
+			provider, err := GetCloudProvider(clusterCfg)
+			if err != nil {
+				return false, err
+			}
+			statusErr := waitUntilReady(provider, newInstanceIds)
 			if statusErr != nil {
-				log.Error.Println("Instance status is still not okay.. Terminating the instance")
-				terminateErr := TerminateInstance(newNodeIp, clusterCfg.CloudCredentials)
-				if terminateErr != nil {
-					log.Fatal.Println(terminateErr)
+				log.Error.Println("Local nodes cannot be started")
+				return false, statusErr
+			}
+			log.Info.Println("Configuring Opensearch on new local nodes...")
+			for _, newNodeIp := range newNodeIps {
+				configureErr := configureLocalNode(clusterCfg, newNodeIp)
+				if configureErr != nil {
+					log.Warn.Println("The configuration of the local node ", newNodeIp, " failed")
+					return false, configureErr
 				}
+			}
+		} else {
+			provider, err := GetCloudProvider(clusterCfg)
+			if err != nil {
+				return false, err
+			}
+			statusErr := waitUntilReady(provider, newInstanceIds)
+			if statusErr != nil {
+				log.Error.Println("Instance status is still not okay")
 				return false, statusErr
 			}
 
-			// Install scaling manager on new node
-			log.Info.Println("Installing scaling manager on new node")
+			// Install scaling manager on new nodes
+			log.Info.Println("Installing scaling manager on new nodes")
 			hostsFile := "ansible_scripts/install_hosts"
 			fr, fErr := os.OpenFile(hostsFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
 			if fErr != nil {
@@ -215,16 +213,18 @@ func ScaleOut(clusterCfg config.ClusterDetails, usrCfg config.UserConfig, t *tim
 			defer fr.Close()
 			newDataWriter := bufio.NewWriter(fr)
 			newDataWriter.WriteString("[new_node]\n")
-			newDataWriter.WriteString("node-" + strings.ReplaceAll(newNodeIp, ".", "-") + " ansible_user=" + clusterCfg.SshUser + " roles=master,data,ingest ansible_private_host=" + newNodeIp + " ansible_ssh_private_key_file=" + clusterCfg.CloudCredentials.PemFilePath + "\n")
+			for _, newNodeIp := range newNodeIps {
+				newDataWriter.WriteString("node-" + strings.ReplaceAll(newNodeIp, ".", "-") + " ansible_user=" + clusterCfg.SshUser + " roles=master,data,ingest ansible_private_host=" + newNodeIp + " ansible_ssh_private_key_file=" + clusterCfg.CloudCredentials.PemFilePath + "\n")
+			}
 			newDataWriter.Flush()
 			ansiblerr := ansibleutils.UpdateWithTags(hostsFile, clusterCfg, []string{"add_host", "install"})
 			if ansiblerr != nil {
 				log.Error.Println(ansiblerr)
-				log.Error.Println("Node scaled up but unable to install scaling manager on new node. Please check ansible logs for more details. (logs/playbook.log)")
+				log.Error.Println("Nodes scaled up but unable to install scaling manager on new nodes. Please check ansible logs for more details. (logs/playbook.log)")
 			}
 
-			// Configure opensearch on new node
-			log.Info.Println("Configuring Opensearch on new node...")
+			// Configure opensearch on new nodes
+			log.Info.Println("Configuring Opensearch on new nodes...")
 			hostsFileName := "ansible_scripts/hosts"
 			username := clusterCfg.SshUser
 			f, err := os.OpenFile(hostsFileName, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
@@ -243,75 +243,80 @@ func ScaleOut(clusterCfg config.ClusterDetails, usrCfg config.UserConfig, t *tim
 				}
 			}
 			dataWriter.WriteString("[new_node]\n")
-			dataWriter.WriteString("node-" + strings.ReplaceAll(newNodeIp, ".", "-") + " ansible_user=" + username + " roles=master,data,ingest ansible_private_host=" + newNodeIp + " ansible_ssh_private_key_file=" + clusterCfg.CloudCredentials.PemFilePath + "\n")
+			for _, newNodeIp := range newNodeIps {
+				dataWriter.WriteString("node-" + strings.ReplaceAll(newNodeIp, ".", "-") + " ansible_user=" + username + " roles=master,data,ingest ansible_private_host=" + newNodeIp + " ansible_ssh_private_key_file=" + clusterCfg.CloudCredentials.PemFilePath + "\n")
+			}
 			dataWriter.Flush()
 			ansibleErr := ansibleutils.CallAnsible(username, hostsFileName, clusterCfg, "scale_up")
 			if ansibleErr != nil {
-				if newNodeIp != "" {
-					log.Warn.Println("Terminating the instance as the ansible script failed.")
-					terminateErr := TerminateInstance(newNodeIp, clusterCfg.CloudCredentials)
-					if terminateErr != nil {
-						log.Fatal.Println(terminateErr)
-					}
-				}
+				log.Warn.Println("The ansible script to configure the new nodes failed")
 				return false, ansibleErr
 			}
 		}
-		state.PreviousState = state.CurrentState
-		state.CurrentState = "provisioning_scaleup_configured"
-		state.UpdateState()
+		if err := state.transitionUnlessStopped(ctx, StateScaleUpConfigured); err != nil {
+			return false, err
+		}
 		fallthrough
-	case "provisioning_scaleup_configured":
+	case StateScaleUpConfigured:
 		state.GetCurrentState()
-		newNodeIp = state.NodeIp
-		// Check if node has joined the cluster
-		log.Info.Println("Waiting for new node to join the cluster...")
-		var joined bool
-		// Wait for 10 minutes in the interval of 5 seconds for the node to join the cluster
-		for i := 0; i < 120; i++ {
-			nodesInfo := utils.GetNodes()
-			for _, nodeIdInfo := range nodesInfo {
-				if nodeIdInfo.(map[string]string)["hostIp"] == newNodeIp {
-					joined = true
-					break
+		newNodeIps = state.NodeIps
+		// Check if nodes have joined the cluster
+		log.Info.Println("Waiting for new nodes to join the cluster...")
+		// Wait in the interval of 5 seconds for the nodes to join the cluster until the phase is timed out
+		for {
+			joinedIps := make(map[string]bool)
+			for _, nodeIdInfo := range utils.GetNodes() {
+				joinedIps[nodeIdInfo.(map[string]string)["hostIp"]] = true
+			}
+			remainingNodes := 0
+			for _, newNodeIp := range newNodeIps {
+				if !joinedIps[newNodeIp] {
+					remainingNodes++
 				}
 			}
-			if joined {
+			if remainingNodes != state.RemainingNodes {
+				log.Info.Println(state.RemainingNodes-remainingNodes, " new node(s) joined the cluster")
+				state.RemainingNodes = remainingNodes
+				state.UpdateState()
+			}
+			if remainingNodes == 0 {
 				break
 			}
-			log.Info.Println("Waiting for new node to join the cluster...")
-			time.Sleep(5 * time.Second)
+			log.Info.Println("Waiting for ", remainingNodes, " new node(s) to join the cluster...")
+			if err := sleepUnlessStopped(ctx, 5*time.Second); err != nil {
+				return false, fmt.Errorf("%d of the %d new nodes don't seem to have joined the cluster. Please login into new nodes and check for opensearch logs for more details: %w", state.RemainingNodes, len(newNodeIps), err)
+			}
 		}
 
-		if !joined {
-			errMsg := "The new node doesn't seem to have joined the cluster. Please login into new node and check for opensearch logs for more details."
-			return joined, errors.New(errMsg)
-		}
+		// Start scaling manager on new nodes
+		// Local nodes share the host and the scaling manager already running on it
+		if clusterCfg.CloudType != "LOCAL" && len(newNodeIps) > 0 {
+			hostsFileName := "ansible_scripts/install_hosts"
+			f, err := os.OpenFile(hostsFileName, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+			if err != nil {
+				log.Fatal.Println(err)
+				return false, err
+			}
+			defer f.Close()
+			dataWriter := bufio.NewWriter(f)
+			dataWriter.WriteString("[new_node]\n")
+			for _, newNodeIp := range newNodeIps {
+				dataWriter.WriteString("node-" + strings.ReplaceAll(newNodeIp, ".", "-") + " ansible_user=" + clusterCfg.SshUser + " roles=master,data,ingest ansible_private_host=" + newNodeIp + " ansible_ssh_private_key_file=" + clusterCfg.CloudCredentials.PemFilePath + "\n")
+			}
+			dataWriter.Flush()
 
-		// Start scaling manager on new node
-		hostsFileName := "ansible_scripts/install_hosts"
-		f, err := os.OpenFile(hostsFileName, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
-		if err != nil {
-			log.Fatal.Println(err)
-			return false, err
+			ansibleErr := ansibleutils.UpdateWithTags(hostsFileName, clusterCfg, []string{"update_config", "update_pem", "update_secret", "start"})
+			if ansibleErr != nil {
+				log.Error.Println(ansibleErr)
+				log.Error.Println("Nodes scaled up but unable to start scaling manager on new nodes. Please check ansible logs for more details. (logs/playbook.log)")
+			}
 		}
-		defer f.Close()
-		dataWriter := bufio.NewWriter(f)
-		dataWriter.WriteString("[new_node]\n")
-		dataWriter.WriteString("node-" + strings.ReplaceAll(newNodeIp, ".", "-") + " ansible_user=" + clusterCfg.SshUser + " roles=master,data,ingest ansible_private_host=" + newNodeIp + " ansible_ssh_private_key_file=" + clusterCfg.CloudCredentials.PemFilePath + "\n")
-		dataWriter.Flush()
-
-		ansibleErr := ansibleutils.UpdateWithTags(hostsFileName, clusterCfg, []string{"update_config", "update_pem", "update_secret", "start"})
-		if ansibleErr != nil {
-			log.Error.Println(ansibleErr)
-			log.Error.Println("Node scaled up but unable to start scaling manager on new node. Please check ansible logs for more details. (logs/playbook.log)")
+		if err := state.transitionUnlessStopped(ctx, StateScaleUpCompleted); err != nil {
+			return false, err
 		}
-		state.PreviousState = state.CurrentState
-		state.CurrentState = "provisioning_scaleup_completed"
-		state.UpdateState()
 		fallthrough
 	// Check cluster status after the configuration
-	case "provisioning_scaleup_completed":
+	case StateScaleUpCompleted:
 		if simFlag {
 			SimulateSharRebalancing("scaleOut", state.NumNodes, isAccelerated)
 		}
@@ -319,46 +324,49 @@ func ScaleOut(clusterCfg config.ClusterDetails, usrCfg config.UserConfig, t *tim
 		if simFlag && isAccelerated {
 			fakeSleep(t)
 		}
-		CheckClusterHealth(usrCfg, t)
+		if err := CheckClusterHealth(ctx, usrCfg, t); err != nil {
+			return false, err
+		}
 	}
 	return true, nil
 }
 
 // Input:
 //
+//	ctx (context.Context): Context of the provision, which is done once the provision is timed out
 //	clusterCfg (config.ClusterDetails): Cluster Level config details
 //	usrCfg (config.UserConfig): User defined config for application behavior
 //
 // Description:
 //
 //	ScaleIn will scale in the cluster with the number of nodes.
-//	This function will invoke commands to remove a node from opensearch cluster.
+//	This function will invoke commands to remove the nodes from opensearch cluster in a single run.
+//	Every step persists its progress in the state so that a new master can resume the scale in.
 //
 // Return:
 //
 //	(bool): Return the status of scale in of the nodes.
-func ScaleIn(clusterCfg config.ClusterDetails, usrCfg config.UserConfig, t *time.Time) (bool, error) {
+func ScaleIn(ctx context.Context, clusterCfg config.ClusterDetails, usrCfg config.UserConfig, t *time.Time) (bool, error) {
 	// Read the current state of scaledown process and proceed with next step
 	// If no stage was already set. The function returns an empty string. Then, start the scaledown process
 	crypto.GetDecryptedCloudCreds(&clusterCfg.CloudCredentials)
 	crypto.GetDecryptedOsCreds(&clusterCfg.OsCredentials)
 	state.GetCurrentState()
-	var removeNodeIp, removeNodeName string
+	var removeNodeIps, removeNodeNames []string
 	var nodes map[string]interface{}
 	monitorWithLogs := usrCfg.MonitorWithLogs
 	simFlag := usrCfg.MonitorWithSimulator
 	isAccelerated := usrCfg.IsAccelerated
-	if state.CurrentState == "provisioning_scaledown" {
+	if state.CurrentState == StateProvisioningScaleDown {
 		log.Info.Println("Staring scaleDown process")
-		state.PreviousState = state.CurrentState
-		state.CurrentState = "start_scaledown_process"
-		state.ProvisionStartTime = time.Now().UnixMilli()
-		state.UpdateState()
+		if err := state.transitionUnlessStopped(ctx, StateStartScaleDown); err != nil {
+			return false, err
+		}
 	}
-	// Identify the node which can be removed from the cluster.
+	// Identify the nodes which can be removed from the cluster.
 	switch state.CurrentState {
-	case "start_scaledown_process":
-		log.Info.Println("Identify the node to remove from the cluster and store the node_ip")
+	case StateStartScaleDown:
+		log.Info.Println("Identify the nodes to remove from the cluster and store the node_ips")
 		if monitorWithLogs {
 			time.Sleep(time.Duration(usrCfg.RecommendationPollingInterval) * time.Second)
 			if simFlag && isAccelerated {
@@ -367,25 +375,36 @@ func ScaleIn(clusterCfg config.ClusterDetails, usrCfg config.UserConfig, t *time
 		} else {
 			nodes = utils.GetNodes()
 			for nodeId, nodeIdInfo := range nodes {
-				if !(utils.CheckIfMaster(context.Background(), nodeId)) {
-					removeNodeIp = nodeIdInfo.(map[string]string)["hostIp"]
-					removeNodeName = nodeIdInfo.(map[string]string)["name"]
+				if len(removeNodeIps) == state.NumNodes {
 					break
 				}
+				// Only the nodes started by the local provider can be removed when cloud_type is LOCAL
+				if clusterCfg.CloudType == "LOCAL" {
+					if _, err := newLocalProvider(clusterCfg).findInstance(nodeIdInfo.(map[string]string)["hostIp"]); err != nil {
+						continue
+					}
+				}
+				if !(utils.CheckIfMaster(context.Background(), nodeId)) {
+					removeNodeIps = append(removeNodeIps, nodeIdInfo.(map[string]string)["hostIp"])
+					removeNodeNames = append(removeNodeNames, nodeIdInfo.(map[string]string)["name"])
+				}
 			}
+			if len(removeNodeIps) < state.NumNodes {
+				return false, fmt.Errorf("only %d of the %d nodes to be removed could be identified", len(removeNodeIps), state.NumNodes)
+			}
+		}
+		state.NodeIps = removeNodeIps
+		state.NodeNames = removeNodeNames
+		log.Info.Println("Nodes identified for removal: ", removeNodeNames, removeNodeIps)
+		if err := state.transitionUnlessStopped(ctx, StateScaleDownNodeIdentified); err != nil {
+			return false, err
 		}
-		state.NodeIp = removeNodeIp
-		state.NodeName = removeNodeName
-		log.Info.Println("Node identified for removal: ", removeNodeName, removeNodeIp)
-		state.PreviousState = state.CurrentState
-		state.CurrentState = "scaledown_node_identified"
-		state.UpdateState()
 		fallthrough
-	// Configure OS to tell master node that the present node is going to be removed
-	case "scaledown_node_identified":
+	// Configure OS to tell master node that the present nodes are going to be removed
+	case StateScaleDownNodeIdentified:
 		state.GetCurrentState()
-		removeNodeIp = state.NodeIp
-		removeNodeName = state.NodeName
+		removeNodeIps = state.NodeIps
+		removeNodeNames = state.NodeNames
 		if monitorWithLogs {
 			log.Info.Println("Configure ES to remove the node ip from cluster")
 			time.Sleep(time.Duration(usrCfg.RecommendationPollingInterval) * time.Second)
@@ -397,8 +416,22 @@ func ScaleIn(clusterCfg config.ClusterDetails, usrCfg config.UserConfig, t *time
 			if simFlag && isAccelerated {
 				fakeSleep(t)
 			}
+		} else if clusterCfg.CloudType == "LOCAL" {
+			log.Info.Println("Removing local nodes ***********************************:", removeNodeNames)
+			removeErr := removeLocalNodes(ctx, clusterCfg, removeNodeIps, removeNodeNames)
+			if removeErr != nil {
+				return false, removeErr
+			}
 		} else {
-			log.Info.Println("Configuring to remove the node from cluster through ansible")
+			log.Info.Println("Configuring to remove the nodes from cluster through ansible")
+			// The nodes are not fetched yet when the scale down is resumed from this state
+			if nodes == nil {
+				nodes = utils.GetNodes()
+			}
+			isRemoveNode := make(map[string]bool)
+			for _, removeNodeIp := range removeNodeIps {
+				isRemoveNode[removeNodeIp] = true
+			}
 			hostsFileName := "ansible_scripts/hosts"
 			username := clusterCfg.SshUser
 			f, err := os.OpenFile(hostsFileName, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
@@ -410,7 +443,7 @@ func ScaleIn(clusterCfg config.ClusterDetails, usrCfg config.UserConfig, t *time
 			dataWriter := bufio.NewWriter(f)
 			dataWriter.WriteString("[current_nodes]\n")
 			for _, nodeIdInfo := range nodes {
-				if nodeIdInfo.(map[string]string)["hostIp"] != removeNodeIp {
+				if !isRemoveNode[nodeIdInfo.(map[string]string)["hostIp"]] {
 					_, writeErr := dataWriter.WriteString(nodeIdInfo.(map[string]string)["name"] + " ansible_user=" + username + " roles=master,data,ingest ansible_private_host=" + nodeIdInfo.(map[string]string)["hostIp"] + " ansible_ssh_private_key_file=" + clusterCfg.CloudCredentials.PemFilePath + "\n")
 					if writeErr != nil {
 						log.Error.Println("Error writing the node data into hosts file", writeErr)
@@ -418,39 +451,56 @@ func ScaleIn(clusterCfg config.ClusterDetails, usrCfg config.UserConfig, t *time
 				}
 			}
 			dataWriter.WriteString("[remove_node]\n")
-			dataWriter.WriteString(removeNodeName + " ansible_user=" + username + " roles=master,data,ingest ansible_private_host=" + removeNodeIp + " ansible_ssh_private_key_file=" + clusterCfg.CloudCredentials.PemFilePath + "\n")
+			for i, removeNodeIp := range removeNodeIps {
+				dataWriter.WriteString(removeNodeNames[i] + " ansible_user=" + username + " roles=master,data,ingest ansible_private_host=" + removeNodeIp + " ansible_ssh_private_key_file=" + clusterCfg.CloudCredentials.PemFilePath + "\n")
+			}
 			dataWriter.Flush()
-			log.Info.Println("Removing node ***********************************:", removeNodeName)
+			log.Info.Println("Removing nodes ***********************************:", removeNodeNames)
 			ansibleErr := ansibleutils.CallAnsible(username, hostsFileName, clusterCfg, "scale_down")
 			if ansibleErr != nil {
 				return false, ansibleErr
 			}
 		}
-		state.PreviousState = state.CurrentState
-		state.CurrentState = "provisioned_scaledown_on_cluster"
-		state.UpdateState()
+		if err := state.transitionUnlessStopped(ctx, StateScaleDownOnCluster); err != nil {
+			return false, err
+		}
 		fallthrough
-	case "provisioned_scaledown_on_cluster":
+	case StateScaleDownOnCluster:
 		state.GetCurrentState()
-		removeNodeIp = state.NodeIp
-		log.Info.Println("Terminating the instance")
-		terminateErr := TerminateInstance(removeNodeIp, clusterCfg.CloudCredentials)
-		if terminateErr != nil {
-			log.Fatal.Println(terminateErr)
-			return false, terminateErr
+		removeNodeIps = state.NodeIps
+		log.Info.Println("Terminating the instances")
+		provider, err := GetCloudProvider(clusterCfg)
+		if err != nil {
+			return false, err
+		}
+		for i, removeNodeIp := range removeNodeIps {
+			// The instances before this were already terminated before a master failover
+			if i < state.NumNodes-state.RemainingNodes {
+				continue
+			}
+			terminateErr := provider.TerminateInstance(removeNodeIp)
+			if terminateErr != nil {
+				log.Error.Println(terminateErr)
+				return false, terminateErr
+			}
+			state.RemainingNodes--
+			state.UpdateState()
+		}
+		state.RemainingNodes = 0
+		if err := state.transitionUnlessStopped(ctx, StateScaleDownCompleted); err != nil {
+			return false, err
 		}
-		state.PreviousState = state.CurrentState
-		state.CurrentState = "provisioning_scaledown_completed"
-		state.UpdateState()
 		fallthrough
 	// Wait for cluster to be in stable state(Shard rebalance)
 	// Shut down the node
-	case "provisioning_scaledown_completed":
+	case StateScaleDownCompleted:
 		if simFlag {
 			SimulateSharRebalancing("scaleIn", state.NumNodes, isAccelerated)
 		}
 		log.Info.Println("Wait for the cluster to become healthy and then proceed")
-		CheckClusterHealth(usrCfg, t)
+		if err := CheckClusterHealth(ctx, usrCfg, t); err != nil {
+			return false, err
+		}
 		if simFlag && isAccelerated {
 			fakeSleep(t)
 		}
@@ -460,16 +510,118 @@ func ScaleIn(clusterCfg config.ClusterDetails, usrCfg config.UserConfig, t *time
 
 // Input:
 //
+//	provider (CloudProvider): Cloud provider used to spin the VMs
+//
+// Description:
+//
+//	Spins the VMs required for the current scale up in parallel. Every VM is recorded in the state as soon as it is created,
+//	so that only the remaining VMs are spinned when the scale up is retried or resumed after a master failover.
+//	The VMs created are terminated by the rollback if the scale up fails.
+//
+// Return:
+//
+//	(error): Returns error if any of the VMs could not be created
+func spinNewVms(provider CloudProvider) error {
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	var spinErr error
+
+	numNodes := state.NumNodes - len(state.NodeIps)
+	for i := 0; i < numNodes; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			newNodeIp, newInstanceId, err := provider.SpinNewVm()
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				spinErr = err
+				return
+			}
+			state.NodeIps = append(state.NodeIps, newNodeIp)
+			state.InstanceIds = append(state.InstanceIds, newInstanceId)
+			state.UpdateState()
+		}()
+	}
+	wg.Wait()
+
+	if spinErr != nil {
+		log.Error.Println("Unable to spin all the new nodes, spinned ", len(state.NodeIps), " of ", state.NumNodes)
+		return spinErr
+	}
+	return nil
+}
+
+// Input:
+//
+//	provider (CloudProvider): Cloud provider used to spin the VMs
+//	instanceIds ([]string): Instance IDs of the VMs
+//
+// Description:
+//
+//	Waits in parallel until all the VMs are ready to be configured.
+//
+// Return:
+//
+//	(error): Returns error if any of the VMs is not ready
+func waitUntilReady(provider CloudProvider, instanceIds []string) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(instanceIds))
+	for i, instanceId := range instanceIds {
+		wg.Add(1)
+		go func(i int, instanceId string) {
+			defer wg.Done()
+			errs[i] = provider.WaitUntilReady(instanceId)
+		}(i, instanceId)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Input:
+//
+//	provider (CloudProvider): Cloud provider used to spin the VMs
+//	nodeIps ([]string): Ip addresses of the VMs to be terminated
+//
+// Description:
+//
+//	Terminates all the VMs. Failures are logged and the remaining VMs are still terminated.
+//
+// Return:
+//
+//	([]string): Returns the Ip addresses of the VMs which could not be terminated
+func terminateInstances(provider CloudProvider, nodeIps []string) []string {
+	var failedIps []string
+	for _, nodeIp := range nodeIps {
+		terminateErr := provider.TerminateInstance(nodeIp)
+		if terminateErr != nil {
+			log.Error.Println("Unable to terminate the instance ", nodeIp, ": ", terminateErr)
+			failedIps = append(failedIps, nodeIp)
+		}
+	}
+	return failedIps
+}
+
+// Input:
+//
+//	ctx (context.Context): Context of the provision, which is done once the provision is timed out
 //	usrCfg (config.UserConfig): User defined config for application behavior
 //
 // Description:
 //
 //	CheckClusterHealth will check the current cluster health and also check if there are any relocating
 //	shards. If the cluster status is green and there are no relocating shard then we will update the status
-//	to provisioned_successfully. Else, we will wait for 3 minutes and perform this check again for 3 times.
+//	to provisioned_successfully. Else, we will wait for the polling interval and perform this check again until the phase is timed out.
 //
 // Return:
-func CheckClusterHealth(usrCfg config.UserConfig, t *time.Time) {
+//
+//	(error): Returns error if the cluster did not become healthy before the provision was timed out or the state could not be updated
+func CheckClusterHealth(ctx context.Context, usrCfg config.UserConfig, t *time.Time) error {
 	var timedOut bool
 	simFlag := usrCfg.MonitorWithSimulator
 	isAccelerated := usrCfg.IsAccelerated
@@ -489,20 +641,17 @@ func CheckClusterHealth(usrCfg config.UserConfig, t *time.Time) {
 			_, timedOut = cluster.GetClusterCurrent(true)
 		}
 		if !timedOut {
-			state.PreviousState = state.CurrentState
-			if strings.Contains(state.PreviousState, "scaleup") {
-				state.CurrentState = "provisioned_scaleup_successfully"
-			} else {
-				state.CurrentState = "provisioned_scaledown_successfully"
-			}
-			state.UpdateState()
-			break
-		} else {
-			log.Info.Println("Waiting for cluster to rebalance.......")
-			time.Sleep(time.Duration(usrCfg.RecommendationPollingInterval) * time.Second)
-			if simFlag && isAccelerated {
-				fakeSleep(t)
+			if state.CurrentState.Operation() == "scale_up" {
+				return state.transitionUnlessStopped(ctx, StateScaleUpSuccessful)
 			}
+			return state.transitionUnlessStopped(ctx, StateScaleDownSuccessful)
+		}
+		log.Info.Println("Waiting for cluster to rebalance.......")
+		if err := sleepUnlessStopped(ctx, time.Duration(usrCfg.RecommendationPollingInterval)*time.Second); err != nil {
+			return fmt.Errorf("the cluster did not rebalance: %w", err)
+		}
+		if simFlag && isAccelerated {
+			fakeSleep(t)
 		}
 	}
 }
@@ -576,23 +725,41 @@ func fakeSleep(t *time.Time) {
 //
 // Description:
 //
-//	Sets the CurrentState to normal, updates the other fields with default and updates the opensearch document with the same
+//	Sets the CurrentState to normal, which resets the other fields with default and updates the opensearch document with the same
+//	The time of the provision is recorded as the last scale up or scale down time which starts the cooldown of the tasks
+//	The provision is marked as failed first if it did not reach a successful or failed state.
+//	A provision which needs attention is set back to normal only when it is acknowledged by AcknowledgeAttention.
 //
 // Return:
 func SetStateBackToNormal() {
-	state.LastProvisionedTime = time.Now().UnixMilli()
-	state.ProvisionStartTime = 0
-	state.PreviousState = state.CurrentState
-	state.CurrentState = "normal"
-	state.RuleTriggered = ""
-	state.RemainingNodes = 0
-	state.NodeIp = ""
-	state.InstanceId = ""
-	state.NodeName = ""
-	state.UpdateState()
+	state.GetCurrentState()
+	if state.CurrentState == StateNormal {
+		return
+	} else if state.CurrentState == StateNeedsAttention {
+		log.Warn.Println("The provision needs attention and needs to be acknowledged: ", state.Remark)
+		return
+	}
+	if !state.CurrentState.CanTransition(StateNormal) {
+		SetStateFailed()
+	}
+	if err := state.Transition(StateNormal); err != nil {
+		return
+	}
 	log.Info.Println("State set back to normal")
 }
 
+// Inputs:
+//
+// Description:
+//
+//	Marks the provision in progress as failed.
+//
+// Return:
+func SetStateFailed() {
+	state.GetCurrentState()
+	state.Transition(failedState(state.CurrentState.Operation()))
+}
+
 // Inputs:
 //
 //	status (string): Status of the Provisioning
@@ -612,6 +779,10 @@ func PushToOs(status string, err error) {
 	provisionState["Status"] = status
 	if err != nil {
 		provisionState["FailureReason"] = err.Error()
+		var timeoutErr *TimeoutError
+		if errors.As(err, &timeoutErr) {
+			provisionState["StuckPhase"] = timeoutErr.Phase
+		}
 	}
 	provisionState["RulesResponsible"] = state.RulesResponsible
 	provisionState["TimeTaken"] = fmt.Sprint((time.UnixMilli(provisionState["ProvisionEndTime"].(int64))).Sub(time.UnixMilli(provisionState["ProvisionStartTime"].(int64))))
@@ -633,3 +804,54 @@ func PushToOs(status string, err error) {
 	defer indexResponse.Body.Close()
 	log.Debug.Println("Update resp: ", indexResponse)
 }
+
+// Inputs:
+//
+//	task (string): The task that would have been provisioned. i.e., scale_up_by_1
+//	rulesResponsible (string): The rules responsible for the recommendation of the task
+//	currentNodes (int): Number of nodes currently in the cluster
+//
+// Description:
+//
+//	Adds a document to Opensearch representing the provisioning that would have taken place when running in dry run mode.
+//	The state is not changed, so the evaluation of the tasks continues as if nothing was provisioned.
+//
+// Return:
+func PushDryRunToOs(task string, rulesResponsible string, currentNodes int) {
+	scaleRegex := regexp.MustCompile(`(scale_up|scale_down)_by_([0-9]+)`)
+	subMatch := scaleRegex.FindStringSubmatch(task)
+	if subMatch == nil {
+		log.Error.Println("Invalid task for the dry run: ", task)
+		return
+	}
+	numNodes, _ := strconv.Atoi(subMatch[2])
+	targetNodes := currentNodes + numNodes
+	if subMatch[1] == "scale_down" {
+		targetNodes = currentNodes - numNodes
+	}
+	log.Info.Println(fmt.Sprintf("Dry run: The %s would have been provisioned to change the nodes from %d to %d", task, currentNodes, targetNodes))
+
+	dryRunStats := make(map[string]interface{}, 0)
+	dryRunStats["TaskName"] = task
+	dryRunStats["RuleTriggered"] = subMatch[1]
+	dryRunStats["RulesResponsible"] = rulesResponsible
+	dryRunStats["CurrentNodes"] = currentNodes
+	dryRunStats["TargetNodes"] = targetNodes
+	dryRunStats["StatTag"] = "DryRunStats"
+	dryRunStats["_documentType"] = "DryRunStats"
+	dryRunStats["Timestamp"] = time.Now().UnixMilli()
+
+	doc, err := json.Marshal(dryRunStats)
+	if err != nil {
+		log.Error.Println("json.Marshal ERROR: ", err)
+		return
+	}
+
+	indexResponse, err := osutils.IndexMetrics(context.Background(), doc)
+	if err != nil {
+		log.Error.Println("Failed to insert dry run stats document: ", err)
+		return
+	}
+	defer indexResponse.Body.Close()
+	log.Debug.Println("Update resp: ", indexResponse)
+}
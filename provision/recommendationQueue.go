@@ -0,0 +1,232 @@
+package provision
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// The default priority of the scale_up tasks. A scale up is preferred over a scale down by default,
+// as an undersized cluster affects the users while an oversized cluster only costs more.
+const defaultScaleUpPriority = 2
+
+// The default priority of the scale_down tasks.
+const defaultScaleDownPriority = 1
+
+// This struct contains a recommendation made by the recommendation engine which is waiting to be provisioned.
+type Recommendation struct {
+	// TaskName indicates the task recommended. i.e., scale_up_by_1
+	TaskName string
+	// Operation indicates the operation of the task. i.e., scale_up/scale_down
+	Operation string
+	// NumNodes indicates the number of nodes to be added or removed
+	NumNodes int
+	// RulesResponsible indicates the rules responsible for the latest recommendation of the task
+	RulesResponsible string
+	// Priority indicates the priority of the task. The recommendation with the highest priority is provisioned first
+	Priority int
+	// CreatedTime indicates when the task was recommended for the first time
+	CreatedTime int64
+	// UpdatedTime indicates when the task was recommended for the last time
+	UpdatedTime int64
+	// Count indicates the number of times the task has been recommended while it was in the queue
+	Count int
+}
+
+// This is the queue of the recommendations which is persisted in the state document,
+// so that a new master sees the recommendations of the previous master.
+type RecommendationQueue []Recommendation
+
+// Input:
+//
+//	taskName (string): The task recommended. i.e., scale_up_by_1
+//	rulesResponsible (string): The rules responsible for the recommendation
+//	priority (int): The priority of the task. The default priority of the operation is used if it is zero
+//
+// Description:
+//
+//	Creates a recommendation for the task.
+//
+// Return:
+//
+//	(Recommendation, error): Returns the recommendation and error if the task name is not valid
+func NewRecommendation(taskName string, rulesResponsible string, priority int) (Recommendation, error) {
+	scaleRegex := regexp.MustCompile(`^(scale_up|scale_down)_by_([0-9]+)$`)
+	subMatch := scaleRegex.FindStringSubmatch(taskName)
+	if subMatch == nil {
+		return Recommendation{}, fmt.Errorf("invalid task name %s for a recommendation", taskName)
+	}
+	numNodes, _ := strconv.Atoi(subMatch[2])
+	if priority == 0 {
+		priority = defaultScaleDownPriority
+		if subMatch[1] == "scale_up" {
+			priority = defaultScaleUpPriority
+		}
+	}
+	now := time.Now().UnixMilli()
+	return Recommendation{
+		TaskName:         taskName,
+		Operation:        subMatch[1],
+		NumNodes:         numNodes,
+		RulesResponsible: rulesResponsible,
+		Priority:         priority,
+		CreatedTime:      now,
+		UpdatedTime:      now,
+		Count:            1,
+	}, nil
+}
+
+// Input:
+//
+//	recommendation (Recommendation): The recommendation to be added to the queue
+//
+// Caller:
+//
+//	Object of RecommendationQueue
+//
+// Description:
+//
+//	Adds the recommendation to the queue. If the task is already present in the queue, the recommendations are merged
+//	by keeping the creation time of the existing recommendation and the rules responsible and priority of the new one.
+//
+// Return:
+func (q *RecommendationQueue) push(recommendation Recommendation) {
+	for i, queued := range *q {
+		if queued.TaskName == recommendation.TaskName {
+			recommendation.CreatedTime = queued.CreatedTime
+			recommendation.Count = queued.Count + 1
+			(*q)[i] = recommendation
+			return
+		}
+	}
+	*q = append(*q, recommendation)
+}
+
+// Input:
+//
+//	expiry (time.Duration): The time after which a recommendation that was not recommended again is stale
+//
+// Caller:
+//
+//	Object of RecommendationQueue
+//
+// Description:
+//
+//	Removes the recommendations which were not recommended again within the expiry.
+//
+// Return:
+func (q *RecommendationQueue) removeExpired(expiry time.Duration) {
+	var recommendations RecommendationQueue
+	for _, recommendation := range *q {
+		if time.Since(time.UnixMilli(recommendation.UpdatedTime)) > expiry {
+			log.Info.Println("Removing the expired recommendation: ", recommendation.TaskName)
+			continue
+		}
+		recommendations = append(recommendations, recommendation)
+	}
+	*q = recommendations
+}
+
+// Input:
+//
+//	conflictPolicy (string): The policy used when both scale_up and scale_down are recommended. These can be:
+//	        priority: The recommendation with the highest priority is provisioned.
+//	        discard: All the recommendations are discarded, until the recommendations agree on the operation.
+//
+// Caller:
+//
+//	Object of RecommendationQueue
+//
+// Description:
+//
+//	Orders the recommendations by priority, then by operation (scale_up first), number of nodes and the time they were last recommended.
+//	Returns the first recommendation and removes it from the queue along with the recommendations in conflict with it.
+//	The other recommendations of the same operation are kept in the queue until they are provisioned or expire.
+//	If there is a conflict and the policy is discard, no recommendation is returned and the queue is emptied.
+//
+// Return:
+//
+//	(Recommendation, bool): Returns the recommendation to be provisioned and false if there is none
+func (q *RecommendationQueue) pop(conflictPolicy string) (Recommendation, bool) {
+	recommendations := *q
+	if len(recommendations) == 0 {
+		return Recommendation{}, false
+	}
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		if recommendations[i].Priority != recommendations[j].Priority {
+			return recommendations[i].Priority > recommendations[j].Priority
+		}
+		if recommendations[i].Operation != recommendations[j].Operation {
+			return recommendations[i].Operation == "scale_up"
+		}
+		if recommendations[i].NumNodes != recommendations[j].NumNodes {
+			return recommendations[i].NumNodes > recommendations[j].NumNodes
+		}
+		return recommendations[i].UpdatedTime > recommendations[j].UpdatedTime
+	})
+
+	selected := recommendations[0]
+	var remaining RecommendationQueue
+	for _, recommendation := range recommendations[1:] {
+		if recommendation.Operation == selected.Operation {
+			remaining = append(remaining, recommendation)
+			continue
+		}
+		if conflictPolicy == "discard" {
+			log.Warn.Println(fmt.Sprintf("Discarding all the recommendations as both %s and %s are recommended", selected.TaskName, recommendation.TaskName))
+			*q = nil
+			return Recommendation{}, false
+		}
+		log.Warn.Println(fmt.Sprintf("Discarding the recommendation %s as it conflicts with %s which has a higher priority", recommendation.TaskName, selected.TaskName))
+	}
+	*q = remaining
+	return selected, true
+}
+
+// Input:
+//
+//	recommendation (Recommendation): The recommendation to be added to the queue
+//
+// Description:
+//
+//	Adds the recommendation to the queue persisted in the state.
+//
+// Return:
+func PushRecommendation(recommendation Recommendation) {
+	modifyState(true, func() bool {
+		state.RecommendationQueue.push(recommendation)
+		return true
+	})
+}
+
+// Input:
+//
+//	conflictPolicy (string): The policy used when both scale_up and scale_down are recommended
+//	expiry (time.Duration): The time after which a recommendation that was not recommended again is stale
+//
+// Description:
+//
+//	Removes the expired recommendations from the queue persisted in the state and returns the recommendation to be provisioned.
+//
+// Return:
+//
+//	(Recommendation, bool): Returns the recommendation to be provisioned and false if there is none
+func PopRecommendation(conflictPolicy string, expiry time.Duration) (Recommendation, bool) {
+	var recommendation Recommendation
+	var ok bool
+	err := modifyState(true, func() bool {
+		if len(state.RecommendationQueue) == 0 {
+			return false
+		}
+		state.RecommendationQueue.removeExpired(expiry)
+		recommendation, ok = state.RecommendationQueue.pop(conflictPolicy)
+		return true
+	})
+	// The recommendation is provisioned only if it was removed from the queue, so that it is not provisioned by another node as well
+	if err != nil {
+		return Recommendation{}, false
+	}
+	return recommendation, ok
+}
@@ -0,0 +1,142 @@
+package provision
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestRecommendation(t *testing.T, taskName string, priority int, updatedTime int64) Recommendation {
+	recommendation, err := NewRecommendation(taskName, "rules", priority)
+	if err != nil {
+		t.Fatalf("failed to create the recommendation %s: %v", taskName, err)
+	}
+	recommendation.CreatedTime = updatedTime
+	recommendation.UpdatedTime = updatedTime
+	return recommendation
+}
+
+func TestNewRecommendation(t *testing.T) {
+	tests := []struct {
+		taskName         string
+		priority         int
+		operation        string
+		numNodes         int
+		expectedPriority int
+		isValid          bool
+	}{
+		{"scale_up_by_2", 0, "scale_up", 2, defaultScaleUpPriority, true},
+		{"scale_down_by_1", 0, "scale_down", 1, defaultScaleDownPriority, true},
+		{"scale_down_by_3", 5, "scale_down", 3, 5, true},
+		{"scale_up", 0, "", 0, 0, false},
+	}
+	for _, test := range tests {
+		recommendation, err := NewRecommendation(test.taskName, "rules", test.priority)
+		if (err == nil) != test.isValid {
+			t.Errorf("%s: expected the validity %v got the error %v", test.taskName, test.isValid, err)
+			continue
+		}
+		if test.isValid && (recommendation.Operation != test.operation || recommendation.NumNodes != test.numNodes || recommendation.Priority != test.expectedPriority) {
+			t.Errorf("%s: expected %s of %d nodes with the priority %d got %+v", test.taskName, test.operation, test.numNodes, test.expectedPriority, recommendation)
+		}
+	}
+}
+
+func TestRecommendationQueuePush(t *testing.T) {
+	var q RecommendationQueue
+	first := newTestRecommendation(t, "scale_up_by_1", 0, 1000)
+	q.push(first)
+	q.push(newTestRecommendation(t, "scale_down_by_1", 0, 1500))
+	again := newTestRecommendation(t, "scale_up_by_1", 3, 2000)
+	again.RulesResponsible = "new rules"
+	q.push(again)
+
+	if len(q) != 2 {
+		t.Fatalf("expected the recommendations of the same task to be merged got %d recommendations", len(q))
+	}
+	merged := q[0]
+	if merged.CreatedTime != first.CreatedTime || merged.UpdatedTime != again.UpdatedTime || merged.Count != 2 ||
+		merged.Priority != 3 || merged.RulesResponsible != "new rules" {
+		t.Errorf("expected the creation time of the first and the rest of the latest recommendation got %+v", merged)
+	}
+}
+
+func TestRecommendationQueuePop(t *testing.T) {
+	tests := []struct {
+		name           string
+		tasks          []string
+		priorities     []int
+		conflictPolicy string
+		expected       string
+		ok             bool
+		remaining      []string
+	}{
+		{"empty", nil, nil, "priority", "", false, nil},
+		{"scale up first by default", []string{"scale_down_by_1", "scale_up_by_1"}, []int{0, 0}, "priority", "scale_up_by_1", true, nil},
+		{"higher priority first", []string{"scale_up_by_1", "scale_down_by_1"}, []int{1, 4}, "priority", "scale_down_by_1", true, nil},
+		{"scale up before scale down on the same priority", []string{"scale_down_by_2", "scale_up_by_1"}, []int{2, 2}, "priority", "scale_up_by_1", true, nil},
+		{"more nodes first", []string{"scale_up_by_1", "scale_up_by_3", "scale_up_by_2"}, []int{0, 0, 0}, "priority", "scale_up_by_3", true, []string{"scale_up_by_2", "scale_up_by_1"}},
+		{"only the conflicts are removed", []string{"scale_up_by_1", "scale_down_by_1", "scale_up_by_2"}, []int{0, 0, 0}, "priority", "scale_up_by_2", true, []string{"scale_up_by_1"}},
+		{"conflict discarded", []string{"scale_up_by_1", "scale_down_by_1", "scale_up_by_2"}, []int{0, 0, 0}, "discard", "", false, nil},
+		{"no conflict with discard", []string{"scale_up_by_1", "scale_up_by_2"}, []int{0, 0}, "discard", "scale_up_by_2", true, []string{"scale_up_by_1"}},
+	}
+	for _, test := range tests {
+		var q RecommendationQueue
+		for i, taskName := range test.tasks {
+			q.push(newTestRecommendation(t, taskName, test.priorities[i], int64(1000+i)))
+		}
+		recommendation, ok := q.pop(test.conflictPolicy)
+		if ok != test.ok || recommendation.TaskName != test.expected {
+			t.Errorf("%s: expected %q (%v) got %q (%v)", test.name, test.expected, test.ok, recommendation.TaskName, ok)
+		}
+		// The recommendations of the same operation are kept until they are provisioned or expire
+		var remaining []string
+		for _, queued := range q {
+			remaining = append(remaining, queued.TaskName)
+		}
+		if !reflect.DeepEqual(remaining, test.remaining) {
+			t.Errorf("%s: expected %v to remain in the queue got %v", test.name, test.remaining, remaining)
+		}
+	}
+}
+
+func TestRecommendationQueueKeepsCountAcrossPops(t *testing.T) {
+	var q RecommendationQueue
+	q.push(newTestRecommendation(t, "scale_up_by_2", 0, 1000))
+	q.push(newTestRecommendation(t, "scale_up_by_1", 0, 1000))
+	if recommendation, _ := q.pop("priority"); recommendation.TaskName != "scale_up_by_2" {
+		t.Fatalf("expected scale_up_by_2 to be popped first got %s", recommendation.TaskName)
+	}
+	q.push(newTestRecommendation(t, "scale_up_by_1", 0, 2000))
+	recommendation, ok := q.pop("priority")
+	if !ok || recommendation.TaskName != "scale_up_by_1" || recommendation.Count != 2 || recommendation.CreatedTime != 1000 {
+		t.Errorf("expected scale_up_by_1 merged with the entry kept in the queue got %+v", recommendation)
+	}
+	if len(q) != 0 {
+		t.Errorf("expected the queue to be empty got %+v", q)
+	}
+}
+
+func TestRecommendationQueuePopLatestOnTie(t *testing.T) {
+	var q RecommendationQueue
+	q.push(newTestRecommendation(t, "scale_up_by_1", 0, 1000))
+	q.push(Recommendation{TaskName: "scale_up_by_1_copy", Operation: "scale_up", NumNodes: 1, Priority: defaultScaleUpPriority, UpdatedTime: 2000})
+	if recommendation, _ := q.pop("priority"); recommendation.TaskName != "scale_up_by_1_copy" {
+		t.Errorf("expected the latest recommendation on a tie got %s", recommendation.TaskName)
+	}
+}
+
+func TestRecommendationQueueRemoveExpired(t *testing.T) {
+	now := time.Now()
+	var q RecommendationQueue
+	q.push(newTestRecommendation(t, "scale_up_by_1", 0, now.Add(-10*time.Minute).UnixMilli()))
+	q.push(newTestRecommendation(t, "scale_down_by_1", 0, now.Add(-time.Minute).UnixMilli()))
+	q.removeExpired(5 * time.Minute)
+	if len(q) != 1 || q[0].TaskName != "scale_down_by_1" {
+		t.Errorf("expected only the recommendation updated within the expiry got %+v", q)
+	}
+	q.removeExpired(30 * time.Second)
+	if len(q) != 0 {
+		t.Errorf("expected all the recommendations to be expired got %+v", q)
+	}
+}
@@ -0,0 +1,397 @@
+package provision
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/maplelabs/opensearch-scaling-manager/config"
+	"github.com/maplelabs/opensearch-scaling-manager/crypto"
+	osutils "github.com/maplelabs/opensearch-scaling-manager/opensearchUtils"
+	utils "github.com/maplelabs/opensearch-scaling-manager/utilities"
+)
+
+// The default time before the first retry of a failed phase and the maximum time between the retries.
+const (
+	defaultRetryBackoff    = 30 * time.Second
+	defaultMaxRetryBackoff = 600 * time.Second
+)
+
+// The ansible inventories which are rewritten by the provision. They are backed up before the provision and restored on a rollback.
+var hostsFiles = []string{"ansible_scripts/hosts", "ansible_scripts/install_hosts"}
+
+// Input:
+//
+//	clusterCfg (config.ClusterDetails): Cluster Level config details
+//	usrCfg (config.UserConfig): User defined config for application behavior
+//
+// Description:
+//
+//	Runs the provision in progress from the current state until it is completed.
+//	A failed phase is retried as configured in provision_retry. If the phase still fails, the provision is marked as failed
+//	and rolled back. If the rollback fails, the state needs attention and no provision is made until it is acknowledged.
+//	A provision stuck in a phase for longer than its deadline in provision_timeout is timed out by the watchdog,
+//	which fails it with the phase in which it was stuck. A provision aborted by an operator is stopped and rolled back in the same way.
+//	If the state was updated by another node, the provision is left to the other node.
+//
+// Return:
+func ContinueProvision(clusterCfg config.ClusterDetails, usrCfg config.UserConfig, t *time.Time) {
+	state.GetCurrentState()
+	operation := state.CurrentState.Operation()
+	if operation == "" {
+		return
+	}
+	if !startProvision() {
+		log.Warn.Println(fmt.Sprintf("The %s is not continued as a provision is already running or the scaling manager is stopping", operation))
+		return
+	}
+	defer endProvision()
+	notifyPhase(state.CurrentState)
+	backupHostsFiles()
+
+	var isProvisioned bool
+	var err error
+	if state.CurrentState == failedState(operation) {
+		// The provision failed before it was rolled back
+		err = errors.New(state.Remark)
+	} else if abortErr := state.checkAbort(); abortErr != nil {
+		err = abortErr
+	} else {
+		provision := ScaleOut
+		if operation == "scale_down" {
+			provision = ScaleIn
+		}
+		isProvisioned, err = runWithWatchdog(usrCfg, func(ctx context.Context) (bool, error) {
+			return runWithRetries(ctx, usrCfg.ProvisionRetry, func() (bool, error) {
+				return provision(ctx, clusterCfg, usrCfg, t)
+			})
+		})
+	}
+	if errors.Is(err, ErrStateConflict) {
+		log.Warn.Println(fmt.Sprintf("The %s is taken over by another node", operation))
+		return
+	}
+	if isProvisioned {
+		log.Info.Println(fmt.Sprintf("The %s is successful", operation))
+		PushToOs("Success", err)
+		removeHostsBackups()
+		// Set the state back to normal to continue further
+		SetStateBackToNormal()
+		return
+	}
+
+	log.Error.Println(fmt.Sprintf("The %s failed: %s", operation, err))
+	state.GetCurrentState()
+	phase := state.CurrentState
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) {
+		phase = timeoutErr.Phase
+	}
+	if state.CurrentState == failedState(operation) {
+		phase = state.PreviousState
+	} else {
+		// The reason is kept in the state, so that the rollback can be resumed by another node
+		state.Remark = fmt.Sprintf("The %s failed in the phase %s: %s", operation, phase, err)
+		if transitionErr := state.Transition(failedState(operation)); transitionErr != nil {
+			return
+		}
+	}
+	if rollbackErr := rollback(clusterCfg, usrCfg, operation, phase); rollbackErr != nil {
+		log.Error.Println(fmt.Sprintf("The %s could not be rolled back: %s", operation, rollbackErr))
+		SetStateNeedsAttention(fmt.Sprintf("The %s failed in the phase %s: %s. It could not be rolled back: %s", operation, phase, err, rollbackErr))
+		PushToOs("NeedsAttention", fmt.Errorf("%s. It could not be rolled back: %s", err, rollbackErr))
+		return
+	}
+	status := "Failed"
+	if errors.Is(err, ErrProvisionAborted) {
+		status = "Aborted"
+	}
+	PushToOs(status, err)
+	removeHostsBackups()
+	// Set the state back to normal to continue further
+	SetStateBackToNormal()
+}
+
+// Input:
+//
+//	ctx (context.Context): Context of the provision, which is done once the provision is timed out
+//	retryCfg (config.RetryConfig): The retries of the phases
+//	run (func() (bool, error)): Runs the provision from the current state
+//
+// Description:
+//
+//	Runs the provision and runs it again from the state in which it failed, until the retries of the phase are exhausted.
+//	The retries are counted in the state, so that a provision resumed by another node does not retry more than configured.
+//	The time between the retries is doubled for every retry. A provision which is timed out is not retried.
+//
+// Return:
+//
+//	(bool, error): Returns whether the provision is completed and the error of the last run
+func runWithRetries(ctx context.Context, retryCfg config.RetryConfig, run func() (bool, error)) (bool, error) {
+	for {
+		isProvisioned, err := run()
+		if isProvisioned || errors.Is(err, ErrStateConflict) {
+			return isProvisioned, err
+		}
+		if stopErr := stopCause(ctx); stopErr != nil {
+			return false, stopErr
+		}
+		state.GetCurrentState()
+		maxRetries, backoff := getRetryPolicy(retryCfg, state.CurrentState, state.Retries)
+		if state.Retries >= maxRetries {
+			return false, err
+		}
+		state.Retries++
+		if updateErr := state.UpdateState(); updateErr != nil {
+			return false, updateErr
+		}
+		log.Warn.Println(fmt.Sprintf("The phase %s failed, retrying %d of %d in %s: %s", state.CurrentState, state.Retries, maxRetries, backoff, err))
+		if stopErr := sleepUnlessStopped(ctx, backoff); stopErr != nil {
+			return false, stopErr
+		}
+	}
+}
+
+// Input:
+//
+//	retryCfg (config.RetryConfig): The retries of the phases
+//	phase (ProvisionState): The state of the provision in the failed phase
+//	retries (int): The number of times the phase was retried
+//
+// Description:
+//
+//	Returns the number of retries of the phase and the time before the next retry.
+//
+// Return:
+//
+//	(int, time.Duration): Returns the number of retries and the backoff
+func getRetryPolicy(retryCfg config.RetryConfig, phase ProvisionState, retries int) (int, time.Duration) {
+	maxRetries := retryCfg.MaxRetries
+	backoff := time.Duration(retryCfg.Backoff) * time.Second
+	if phaseCfg, ok := retryCfg.Phases[string(phase)]; ok {
+		maxRetries = phaseCfg.MaxRetries
+		backoff = time.Duration(phaseCfg.Backoff) * time.Second
+	}
+	if backoff == 0 {
+		backoff = defaultRetryBackoff
+	}
+	maxBackoff := time.Duration(retryCfg.MaxBackoff) * time.Second
+	if maxBackoff == 0 {
+		maxBackoff = defaultMaxRetryBackoff
+	}
+	for i := 0; i < retries && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return maxRetries, backoff
+}
+
+// Input:
+//
+//	clusterCfg (config.ClusterDetails): Cluster Level config details
+//	usrCfg (config.UserConfig): User defined config for application behavior
+//	operation (string): The operation of the provision (scale_up or scale_down)
+//	phase (ProvisionState): The state of the provision in the failed phase
+//
+// Description:
+//
+//	Undoes the changes made by the failed provision, so that the cluster is as it was before the provision:
+//	  * The instances spinned by a scale up are terminated.
+//	  * The allocation excludes set by a scale down are cleared, if the nodes are still in the cluster.
+//	  * The ansible inventories are restored.
+//	A scale down can not be rolled back once the nodes are removed from the cluster, and a scale up once the cluster is rebalancing.
+//	Once the instances are spinned or terminated, only the rebalancing of the cluster can time out, which needs no rollback.
+//
+// Return:
+//
+//	(error): Returns error if the provision could not be rolled back
+func rollback(clusterCfg config.ClusterDetails, usrCfg config.UserConfig, operation string, phase ProvisionState) error {
+	switch phase {
+	case StateProvisioningScaleUp, StateStartScaleUp, StateScaleUpSpinVm, StateScaleUpConfigured:
+		if len(state.NodeIps) > 0 && !usrCfg.MonitorWithLogs {
+			log.Info.Println("Terminating the instances spinned by the failed scale up: ", state.NodeIps)
+			// The credentials of the config are encrypted
+			crypto.GetDecryptedCloudCreds(&clusterCfg.CloudCredentials)
+			crypto.GetDecryptedOsCreds(&clusterCfg.OsCredentials)
+			provider, err := GetCloudProvider(clusterCfg)
+			if err != nil {
+				return err
+			}
+			// Only the instances which could not be terminated are kept in the state for the operator
+			failedIps := terminateInstances(provider, state.NodeIps)
+			state.NodeIps = failedIps
+			if err := state.UpdateState(); err != nil {
+				return fmt.Errorf("the instances are terminated but the state could not be updated: %w", err)
+			}
+			if len(failedIps) > 0 {
+				return fmt.Errorf("unable to terminate the instances %v", failedIps)
+			}
+		}
+	case StateProvisioningScaleDown, StateStartScaleDown:
+	case StateScaleDownNodeIdentified:
+		if !usrCfg.MonitorWithLogs {
+			log.Info.Println("Clearing the allocation excludes of the nodes of the failed scale down: ", state.NodeIps)
+			if err := clearAllocationExcludes(); err != nil {
+				return err
+			}
+			if removedIps := getRemovedNodes(state.NodeIps); len(removedIps) > 0 {
+				return fmt.Errorf("the nodes %v are removed from the cluster but their instances are not terminated", removedIps)
+			}
+		}
+	case StateScaleUpCompleted, StateScaleDownCompleted:
+		// The cluster has the new number of nodes, only the shards are not rebalanced yet
+		return nil
+	default:
+		return fmt.Errorf("the %s can not be rolled back after the phase %s", operation, phase)
+	}
+	return restoreHostsFiles()
+}
+
+// Input:
+//
+// Description:
+//
+//	Clears the IPs excluded from the shard allocation to remove the nodes from the cluster, so that the shards can be allocated on the nodes again.
+//
+// Return:
+//
+//	(error): Returns error if the setting could not be updated
+func clearAllocationExcludes() error {
+	resp, err := osutils.PutClusterSettings(context.Background(), `{"transient": {"cluster.routing.allocation.exclude._ip": null}}`)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.IsError() {
+		return fmt.Errorf("unable to clear the allocation excludes: %s", resp.String())
+	}
+	return nil
+}
+
+// Input:
+//
+//	nodeIps ([]string): Ip addresses of the nodes
+//
+// Description:
+//
+//	Returns the nodes which are no longer part of the cluster.
+//
+// Return:
+//
+//	([]string): Returns the Ip addresses of the nodes removed from the cluster
+func getRemovedNodes(nodeIps []string) []string {
+	joinedIps := make(map[string]bool)
+	for _, nodeIdInfo := range utils.GetNodes() {
+		joinedIps[nodeIdInfo.(map[string]string)["hostIp"]] = true
+	}
+	var removedIps []string
+	for _, nodeIp := range nodeIps {
+		if !joinedIps[nodeIp] {
+			removedIps = append(removedIps, nodeIp)
+		}
+	}
+	return removedIps
+}
+
+// Input:
+//
+// Description:
+//
+//	Backs up the ansible inventories before they are rewritten by the provision.
+//	The backup made when the provision started is kept when the provision is resumed.
+//
+// Return:
+func backupHostsFiles() {
+	for _, hostsFile := range hostsFiles {
+		if _, err := os.Stat(hostsFile + ".bak"); err == nil {
+			continue
+		}
+		content, err := os.ReadFile(hostsFile)
+		if errors.Is(err, os.ErrNotExist) {
+			content = nil
+		} else if err != nil {
+			log.Warn.Println("Unable to back up ", hostsFile, ": ", err)
+			continue
+		}
+		if err := os.WriteFile(hostsFile+".bak", content, 0644); err != nil {
+			log.Warn.Println("Unable to back up ", hostsFile, ": ", err)
+		}
+	}
+}
+
+// Input:
+//
+// Description:
+//
+//	Restores the ansible inventories backed up before the provision.
+//
+// Return:
+//
+//	(error): Returns error if any of the inventories could not be restored
+func restoreHostsFiles() error {
+	for _, hostsFile := range hostsFiles {
+		if _, err := os.Stat(hostsFile + ".bak"); err != nil {
+			continue
+		}
+		if err := os.Rename(hostsFile+".bak", hostsFile); err != nil {
+			return fmt.Errorf("unable to restore %s: %w", hostsFile, err)
+		}
+	}
+	return nil
+}
+
+// Input:
+//
+// Description:
+//
+//	Removes the backups of the ansible inventories once the provision is completed or rolled back.
+//
+// Return:
+func removeHostsBackups() {
+	for _, hostsFile := range hostsFiles {
+		if err := os.Remove(hostsFile + ".bak"); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Warn.Println("Unable to remove the backup of ", hostsFile, ": ", err)
+		}
+	}
+}
+
+// Inputs:
+//
+//	reason (string): Why the provision needs attention
+//
+// Description:
+//
+//	Marks the failed provision as needing attention, which blocks the provisions until an operator acknowledges it.
+//	The details of the provision, Ex: the instances which could not be terminated, are kept in the state for the operator.
+//
+// Return:
+func SetStateNeedsAttention(reason string) {
+	state.GetCurrentState()
+	state.Remark = reason
+	if err := state.Transition(StateNeedsAttention); err != nil {
+		return
+	}
+	log.Error.Println("The provision needs attention and no provision will be made until it is acknowledged: ", reason)
+}
+
+// Inputs:
+//
+// Description:
+//
+//	Acknowledges the provision which needs attention once the operator has resolved it, so that the provisions can continue.
+//
+// Return:
+//
+//	(error): Returns error if the state does not need attention or could not be updated
+func AcknowledgeAttention() error {
+	state.GetCurrentState()
+	if state.CurrentState != StateNeedsAttention {
+		return fmt.Errorf("the state is %s, which does not need attention", state.CurrentState)
+	}
+	log.Info.Println("Acknowledged the provision which needed attention: ", state.Remark)
+	return state.transition(StateNormal, "operator@"+getActor(), false)
+}
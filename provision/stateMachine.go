@@ -0,0 +1,312 @@
+package provision
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ProvisionState is a state of the provisioning state machine.
+// The values are persisted in the state document and need to stay the same across the releases.
+type ProvisionState string
+
+const (
+	// StateNormal is the state when the recommendation will be provisioned.
+	StateNormal ProvisionState = "normal"
+
+	// StateProvisioningScaleUp is set once the provision module starts provisioning a scale up.
+	StateProvisioningScaleUp ProvisionState = "provisioning_scaleup"
+	// StateStartScaleUp indicates the start of the scale up process.
+	StateStartScaleUp ProvisionState = "start_scaleup_process"
+	// StateScaleUpSpinVm indicates that the new VMs are spinned.
+	StateScaleUpSpinVm ProvisionState = "scaleup_triggered_spin_vm"
+	// StateScaleUpConfigured indicates that opensearch is configured on the new VMs.
+	StateScaleUpConfigured ProvisionState = "provisioning_scaleup_configured"
+	// StateScaleUpCompleted indicates that the new nodes joined the cluster.
+	StateScaleUpCompleted ProvisionState = "provisioning_scaleup_completed"
+	// StateScaleUpSuccessful indicates that the scale up is completed and the cluster is green.
+	StateScaleUpSuccessful ProvisionState = "provisioned_scaleup_successfully"
+	// StateScaleUpFailed indicates that the scale up failed.
+	StateScaleUpFailed ProvisionState = "provisioning_scaleup_failed"
+
+	// StateProvisioningScaleDown is set once the provision module starts provisioning a scale down.
+	StateProvisioningScaleDown ProvisionState = "provisioning_scaledown"
+	// StateStartScaleDown indicates the start of the scale down process.
+	StateStartScaleDown ProvisionState = "start_scaledown_process"
+	// StateScaleDownNodeIdentified indicates that the nodes to be removed are identified.
+	StateScaleDownNodeIdentified ProvisionState = "scaledown_node_identified"
+	// StateScaleDownOnCluster indicates that the nodes are removed from the cluster.
+	StateScaleDownOnCluster ProvisionState = "provisioned_scaledown_on_cluster"
+	// StateScaleDownCompleted indicates that the instances of the removed nodes are terminated.
+	StateScaleDownCompleted ProvisionState = "provisioning_scaledown_completed"
+	// StateScaleDownSuccessful indicates that the scale down is completed and the cluster is green.
+	StateScaleDownSuccessful ProvisionState = "provisioned_scaledown_successfully"
+	// StateScaleDownFailed indicates that the scale down failed.
+	StateScaleDownFailed ProvisionState = "provisioning_scaledown_failed"
+
+	// StateNeedsAttention indicates that a failed provision could not be rolled back.
+	// No provision is made until an operator acknowledges it.
+	StateNeedsAttention ProvisionState = "needs_attention"
+)
+
+// The allowed transitions from every state. Every in-flight state can fail and every terminal state goes back to normal.
+// A failed state needs attention if the provision could not be rolled back, which goes back to normal only when acknowledged.
+var stateTransitions = map[ProvisionState][]ProvisionState{
+	StateNormal: {StateProvisioningScaleUp, StateProvisioningScaleDown},
+
+	StateProvisioningScaleUp: {StateStartScaleUp, StateScaleUpFailed},
+	StateStartScaleUp:        {StateScaleUpSpinVm, StateScaleUpFailed},
+	StateScaleUpSpinVm:       {StateScaleUpConfigured, StateScaleUpFailed},
+	StateScaleUpConfigured:   {StateScaleUpCompleted, StateScaleUpFailed},
+	StateScaleUpCompleted:    {StateScaleUpSuccessful, StateScaleUpFailed},
+	StateScaleUpSuccessful:   {StateNormal},
+	StateScaleUpFailed:       {StateNormal, StateNeedsAttention},
+
+	StateProvisioningScaleDown:   {StateStartScaleDown, StateScaleDownFailed},
+	StateStartScaleDown:          {StateScaleDownNodeIdentified, StateScaleDownFailed},
+	StateScaleDownNodeIdentified: {StateScaleDownOnCluster, StateScaleDownFailed},
+	StateScaleDownOnCluster:      {StateScaleDownCompleted, StateScaleDownFailed},
+	StateScaleDownCompleted:      {StateScaleDownSuccessful, StateScaleDownFailed},
+	StateScaleDownSuccessful:     {StateNormal},
+	StateScaleDownFailed:         {StateNormal, StateNeedsAttention},
+
+	StateNeedsAttention: {StateNormal},
+}
+
+// The default time after which a provision stuck in a state is timed out by the watchdog, unless configured in provision_timeout.
+// The states without a timeout can be held indefinitely.
+var stateTimeouts = map[ProvisionState]time.Duration{
+	StateProvisioningScaleUp: 5 * time.Minute,
+	StateStartScaleUp:        30 * time.Minute,
+	StateScaleUpSpinVm:       60 * time.Minute,
+	StateScaleUpConfigured:   15 * time.Minute,
+	StateScaleUpCompleted:    60 * time.Minute,
+	StateScaleUpSuccessful:   5 * time.Minute,
+	StateScaleUpFailed:       5 * time.Minute,
+
+	StateProvisioningScaleDown:   5 * time.Minute,
+	StateStartScaleDown:          15 * time.Minute,
+	StateScaleDownNodeIdentified: 120 * time.Minute,
+	StateScaleDownOnCluster:      30 * time.Minute,
+	StateScaleDownCompleted:      60 * time.Minute,
+	StateScaleDownSuccessful:     5 * time.Minute,
+	StateScaleDownFailed:         5 * time.Minute,
+}
+
+// The hooks called on the state before a state is exited and after a state is entered.
+var onExitState = map[ProvisionState]func(s *State){
+	// The provision starts when the state leaves normal
+	StateNormal: func(s *State) {
+		s.ProvisionStartTime = time.Now().UnixMilli()
+	},
+}
+
+var onEnterState = map[ProvisionState]func(s *State){
+	StateNormal: resetProvision,
+}
+
+// The number of transitions retained in the state document.
+const maxStateTransitions = 50
+
+// This struct contains a transition of the state recorded in the state document.
+type StateTransition struct {
+	// From indicates the state before the transition
+	From ProvisionState
+	// To indicates the state after the transition
+	To ProvisionState
+	// Actor indicates the node which made the transition
+	Actor string
+	// Timestamp indicates when the transition was made
+	Timestamp int64
+}
+
+// Input:
+//
+// Caller:
+//
+//	Object of ProvisionState
+//
+// Description:
+//
+//	Returns the operation of the provision in progress in the state.
+//
+// Return:
+//
+//	(string): Returns scale_up, scale_down or empty string for normal and needs_attention
+func (p ProvisionState) Operation() string {
+	if strings.Contains(string(p), "scaleup") {
+		return "scale_up"
+	} else if strings.Contains(string(p), "scaledown") {
+		return "scale_down"
+	}
+	return ""
+}
+
+// Input:
+//
+//	to (ProvisionState): The state to which the transition is checked
+//
+// Caller:
+//
+//	Object of ProvisionState
+//
+// Description:
+//
+//	Checks if the transition to the state is allowed by the state machine.
+//
+// Return:
+//
+//	(bool): Returns true if the transition is allowed
+func (p ProvisionState) CanTransition(to ProvisionState) bool {
+	for _, allowed := range stateTransitions[p] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Input:
+//
+//	operation (string): The operation of the provision (scale_up or scale_down)
+//
+// Description:
+//
+//	Returns the failed state of the operation.
+//
+// Return:
+//
+//	(ProvisionState): Returns the failed state
+func failedState(operation string) ProvisionState {
+	if operation == "scale_up" {
+		return StateScaleUpFailed
+	}
+	return StateScaleDownFailed
+}
+
+// Input:
+//
+//	to (ProvisionState): The state to which the state machine needs to move
+//
+// Caller:
+//
+//	Object of type State
+//
+// Description:
+//
+//	Moves the state machine to the state if the transition is allowed from the current state.
+//	Calls the exit hook of the current state and the entry hook of the new state, records the transition
+//	with the node that made it and updates the opensearch document.
+//	An illegal transition is rejected without updating the document.
+//	If the document was updated by another node since it was read, the transition is rejected
+//	and the state is read again, as the other node owns the state now.
+//
+// Return:
+//
+//	(error): Returns error if the transition is not allowed and ErrStateConflict if the state was updated by another node
+func (s *State) Transition(to ProvisionState) error {
+	return s.transition(to, getActor(), true)
+}
+
+// Input:
+//
+//	to (ProvisionState): The state to which the state machine needs to move
+//	actor (string): The node or the operator making the transition
+//	isFenced (bool): Whether the transition is rejected if the state was updated by a newer leader
+//
+// Caller:
+//
+//	Object of type State
+//
+// Description:
+//
+//	Moves the state machine to the state as described in Transition. The transitions made by an operator are not fenced,
+//	as the operator does not hold the leader lease.
+//
+// Return:
+//
+//	(error): Returns error if the transition is not allowed and ErrStateConflict if the state was updated by another node
+func (s *State) transition(to ProvisionState, actor string, isFenced bool) error {
+	from := s.CurrentState
+	if !from.CanTransition(to) {
+		err := fmt.Errorf("illegal state transition from %s to %s", from, to)
+		log.Error.Println(err)
+		return err
+	}
+	if hook, ok := onExitState[from]; ok {
+		hook(s)
+	}
+	now := time.Now().UnixMilli()
+	s.PreviousState = from
+	s.CurrentState = to
+	s.StateEnteredTime = now
+	s.Retries = 0
+	s.Transitions = append(s.Transitions, StateTransition{
+		From:      from,
+		To:        to,
+		Actor:     actor,
+		Timestamp: now,
+	})
+	if len(s.Transitions) > maxStateTransitions {
+		s.Transitions = s.Transitions[len(s.Transitions)-maxStateTransitions:]
+	}
+	if hook, ok := onEnterState[to]; ok {
+		hook(s)
+	}
+	if err := s.update(isFenced); err != nil {
+		s.GetCurrentState()
+		return err
+	}
+	log.Info.Println(fmt.Sprintf("State changed from %s to %s", from, to))
+	notifyPhase(to)
+	return nil
+}
+
+// Input:
+//
+//	s (*State): The state entering normal
+//
+// Description:
+//
+//	Clears the details of the provision once the state is back to normal.
+//	The time of the provision is recorded as the last scale up or scale down time which starts the cooldown of the tasks.
+//
+// Return:
+func resetProvision(s *State) {
+	s.LastProvisionedTime = time.Now().UnixMilli()
+	// The cooldown of the tasks starts after the provision irrespective of whether it was successful
+	if s.RuleTriggered == "scale_up" {
+		s.LastScaleUpTime = s.LastProvisionedTime
+	} else if s.RuleTriggered == "scale_down" {
+		s.LastScaleDownTime = s.LastProvisionedTime
+	}
+	// The tasks need to be stable for their stabilization window again after a provision
+	s.RecommendedSince = nil
+	s.ProvisionStartTime = 0
+	s.RuleTriggered = ""
+	s.Remark = ""
+	s.RemainingNodes = 0
+	s.NodeIps = nil
+	s.InstanceIds = nil
+	s.NodeNames = nil
+	s.AbortRequested = false
+}
+
+// Input:
+//
+// Description:
+//
+//	Returns the name of the node on which the scaling manager is running, which is recorded as the actor of the transitions.
+//
+// Return:
+//
+//	(string): Returns the host name
+func getActor() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Warn.Println("Unable to get the host name: ", err)
+		return "unknown"
+	}
+	return hostname
+}
@@ -0,0 +1,485 @@
+package provision
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/maplelabs/opensearch-scaling-manager/config"
+	osutils "github.com/maplelabs/opensearch-scaling-manager/opensearchUtils"
+	osapi "github.com/opensearch-project/opensearch-go/opensearchapi"
+)
+
+// ErrStateNotFound is returned by a StateStore when the state was never stored.
+var ErrStateNotFound = errors.New("the state is not found")
+
+// This struct identifies the version of the stored state, so that the state is overwritten only if it was not updated since it was read.
+type StateVersion struct {
+	// SeqNo and PrimaryTerm indicate the sequence number and primary term of the state document
+	SeqNo       int
+	PrimaryTerm int
+	// Exists indicates whether the state was stored. The state is created if it does not exist.
+	Exists bool
+}
+
+// StateStore persists the state of the provision.
+type StateStore interface {
+	// Get reads the state along with its version. It returns ErrStateNotFound if the state was never stored.
+	Get() ([]byte, StateVersion, error)
+	// Put stores the state only if the stored state is of the version and returns the new version.
+	// It returns ErrStateConflict if the state was updated since the version was read.
+	Put(content []byte, version StateVersion) (StateVersion, error)
+}
+
+// Input:
+//
+//	storeCfg (config.StateStoreConfig): The backend of the state and the path of the state file
+//	docId (string): The _id of the state document in Opensearch
+//
+// Description:
+//
+//	Creates the store of the state configured. The default is the Opensearch store.
+//
+// Return:
+//
+//	(StateStore): Returns the store of the state
+func NewStateStore(storeCfg config.StateStoreConfig, docId string) StateStore {
+	filePath := storeCfg.FilePath
+	if filePath == "" {
+		filePath = config.DefaultStateFilePath
+	}
+	switch storeCfg.Backend {
+	case "file":
+		return &fileStateStore{path: filePath}
+	case "mirror":
+		return &mirrorStateStore{
+			primary: &osStateStore{docId: docId},
+			mirror:  &fileStateStore{path: filePath},
+		}
+	default:
+		return &osStateStore{docId: docId}
+	}
+}
+
+// This struct stores the state as a document in the monitor-stats index of Opensearch.
+type osStateStore struct {
+	docId string
+}
+
+// Input:
+//
+// Caller:
+//
+//	Object of osStateStore
+//
+// Description:
+//
+//	Reads the state document from Opensearch along with its sequence number and primary term.
+//
+// Return:
+//
+//	([]byte, StateVersion, error): Returns the state, its version and error if any
+func (o *osStateStore) Get() ([]byte, StateVersion, error) {
+	searchResponse, err := osutils.SearchDoc(context.Background(), o.docId)
+	if err != nil {
+		return nil, StateVersion{}, err
+	}
+	defer searchResponse.Body.Close()
+	log.Debug.Println("Get resp: ", searchResponse)
+	if searchResponse.StatusCode == http.StatusNotFound {
+		return nil, StateVersion{}, ErrStateNotFound
+	}
+	if searchResponse.IsError() {
+		return nil, StateVersion{}, fmt.Errorf("failed to get the state document: %s", searchResponse.String())
+	}
+	var stateDoc struct {
+		Source      json.RawMessage `json:"_source"`
+		SeqNo       int             `json:"_seq_no"`
+		PrimaryTerm int             `json:"_primary_term"`
+	}
+	if err := json.NewDecoder(searchResponse.Body).Decode(&stateDoc); err != nil {
+		return nil, StateVersion{}, err
+	}
+	return stateDoc.Source, StateVersion{SeqNo: stateDoc.SeqNo, PrimaryTerm: stateDoc.PrimaryTerm, Exists: true}, nil
+}
+
+// Input:
+//
+//	content ([]byte): The state to be stored
+//	version (StateVersion): The version of the state when it was read
+//
+// Caller:
+//
+//	Object of osStateStore
+//
+// Description:
+//
+//	Updates the state document only if it was not updated since it was read (if_seq_no/if_primary_term),
+//	or creates it only if it does not exist.
+//
+// Return:
+//
+//	(StateVersion, error): Returns the new version and ErrStateConflict if the document was updated by another node
+func (o *osStateStore) Put(content []byte, version StateVersion) (StateVersion, error) {
+	var updateResponse *osapi.Response
+	var err error
+	if version.Exists {
+		updateResponse, err = osutils.UpdateDocIfVersion(context.Background(), o.docId, string(content), version.SeqNo, version.PrimaryTerm)
+	} else {
+		updateResponse, err = osutils.CreateDoc(context.Background(), o.docId, string(content))
+	}
+	if err != nil {
+		return version, err
+	}
+	defer updateResponse.Body.Close()
+	log.Debug.Println("Update resp: ", updateResponse)
+	if updateResponse.StatusCode == http.StatusConflict {
+		return version, ErrStateConflict
+	}
+	if updateResponse.IsError() {
+		return version, fmt.Errorf("failed to update the state document: %s", updateResponse.String())
+	}
+	var updateDoc struct {
+		SeqNo       int `json:"_seq_no"`
+		PrimaryTerm int `json:"_primary_term"`
+	}
+	if err := json.NewDecoder(updateResponse.Body).Decode(&updateDoc); err != nil {
+		return version, err
+	}
+	return StateVersion{SeqNo: updateDoc.SeqNo, PrimaryTerm: updateDoc.PrimaryTerm, Exists: true}, nil
+}
+
+// This struct stores the state in a local file. The file is replaced atomically, so it is never left partially written.
+type fileStateStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// This struct is the content of the state file.
+type stateFile struct {
+	// Version indicates the version of the state
+	Version StateVersion
+	// Unsynced indicates that the state was updated in the file while the Opensearch store was unavailable
+	Unsynced bool `json:"Unsynced,omitempty"`
+	// Base indicates the version of the state in Opensearch on which the unsynced updates are based
+	Base StateVersion
+	// State indicates the stored state
+	State json.RawMessage
+}
+
+// Input:
+//
+// Caller:
+//
+//	Object of fileStateStore
+//
+// Description:
+//
+//	Reads the state from the file along with its version.
+//
+// Return:
+//
+//	([]byte, StateVersion, error): Returns the state, its version and error if any
+func (f *fileStateStore) Get() ([]byte, StateVersion, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	file, err := f.read()
+	if err != nil {
+		return nil, StateVersion{}, err
+	}
+	return file.State, file.Version, nil
+}
+
+// Input:
+//
+//	content ([]byte): The state to be stored
+//	version (StateVersion): The version of the state when it was read
+//
+// Caller:
+//
+//	Object of fileStateStore
+//
+// Description:
+//
+//	Stores the state in the file only if the file has the version, and increments the sequence number of the version.
+//
+// Return:
+//
+//	(StateVersion, error): Returns the new version and ErrStateConflict if the file was updated since it was read
+func (f *fileStateStore) Put(content []byte, version StateVersion) (StateVersion, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	newVersion, err := f.checkVersion(version)
+	if err != nil {
+		return version, err
+	}
+	if err := f.write(stateFile{Version: newVersion, State: content}); err != nil {
+		return version, err
+	}
+	return newVersion, nil
+}
+
+// Input:
+//
+//	version (StateVersion): The version of the state when it was read
+//
+// Caller:
+//
+//	Object of fileStateStore
+//
+// Description:
+//
+//	Checks that the file has the version and returns the next version.
+//
+// Return:
+//
+//	(StateVersion, error): Returns the next version and ErrStateConflict if the file has another version
+func (f *fileStateStore) checkVersion(version StateVersion) (StateVersion, error) {
+	file, err := f.read()
+	if err == ErrStateNotFound {
+		if version.Exists {
+			return version, ErrStateConflict
+		}
+		return StateVersion{SeqNo: 0, PrimaryTerm: 1, Exists: true}, nil
+	} else if err != nil {
+		return version, err
+	}
+	if !version.Exists || file.Version.SeqNo != version.SeqNo || file.Version.PrimaryTerm != version.PrimaryTerm {
+		return version, ErrStateConflict
+	}
+	return StateVersion{SeqNo: version.SeqNo + 1, PrimaryTerm: version.PrimaryTerm, Exists: true}, nil
+}
+
+// Input:
+//
+// Caller:
+//
+//	Object of fileStateStore
+//
+// Description:
+//
+//	Reads and decodes the state file.
+//
+// Return:
+//
+//	(stateFile, error): Returns the content of the file and ErrStateNotFound if the file does not exist
+func (f *fileStateStore) read() (stateFile, error) {
+	var file stateFile
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return file, ErrStateNotFound
+	} else if err != nil {
+		return file, err
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return file, fmt.Errorf("the state file %s is corrupted: %w", f.path, err)
+	}
+	return file, nil
+}
+
+// Input:
+//
+//	file (stateFile): The content of the state file
+//
+// Caller:
+//
+//	Object of fileStateStore
+//
+// Description:
+//
+//	Writes the content to a temporary file in the same directory, syncs it to the disk and renames it to the state file.
+//	The directory is synced as well, so that the rename is persisted.
+//
+// Return:
+//
+//	(error): Returns error if any
+func (f *fileStateStore) write(file stateFile) error {
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(f.path)
+	tmpFile, err := os.CreateTemp(dir, filepath.Base(f.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpFile.Name(), f.path); err != nil {
+		return err
+	}
+	dirFile, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer dirFile.Close()
+	return dirFile.Sync()
+}
+
+// This struct stores the state in Opensearch and mirrors it to a local file.
+// The file is used while Opensearch is unavailable, so that a provision can continue when the cluster is red.
+type mirrorStateStore struct {
+	primary StateStore
+	mirror  *fileStateStore
+}
+
+// Input:
+//
+// Caller:
+//
+//	Object of mirrorStateStore
+//
+// Description:
+//
+//	Reads the state from Opensearch. If the state was updated in the file while Opensearch was unavailable,
+//	the state of the file is written back to Opensearch, unless Opensearch was updated by another node in the meantime.
+//	If Opensearch is unavailable, the state is read from the file.
+//
+// Return:
+//
+//	([]byte, StateVersion, error): Returns the state, its version and error if any
+func (m *mirrorStateStore) Get() ([]byte, StateVersion, error) {
+	content, version, err := m.primary.Get()
+	if err != nil && err != ErrStateNotFound {
+		log.Warn.Println("Unable to read the state from Opensearch, reading it from ", m.mirror.path, ": ", err)
+		return m.mirror.Get()
+	}
+
+	m.mirror.mutex.Lock()
+	defer m.mirror.mutex.Unlock()
+	file, fileErr := m.mirror.read()
+	if fileErr == nil && file.Unsynced {
+		// The file has the updates made while Opensearch was unavailable
+		newVersion, putErr := m.primary.Put(file.State, file.Base)
+		if errors.Is(putErr, ErrStateConflict) {
+			log.Warn.Println("The state updated while Opensearch was unavailable is discarded as it was updated by another node")
+			if err == nil {
+				m.writeMirror(content, version)
+			}
+			return content, version, err
+		} else if putErr != nil {
+			log.Warn.Println("Unable to write the state back to Opensearch, reading it from ", m.mirror.path, ": ", putErr)
+			return file.State, file.Version, nil
+		}
+		log.Info.Println("The state updated while Opensearch was unavailable is written back to Opensearch")
+		m.writeMirror(file.State, newVersion)
+		return file.State, newVersion, nil
+	}
+	if err == nil && (fileErr != nil || file.Version != version) {
+		m.writeMirror(content, version)
+	}
+	return content, version, err
+}
+
+// Input:
+//
+//	content ([]byte): The state to be stored
+//	version (StateVersion): The version of the state when it was read
+//
+// Caller:
+//
+//	Object of mirrorStateStore
+//
+// Description:
+//
+//	Stores the state in Opensearch and mirrors it to the file. If Opensearch is unavailable, the state is stored only in the file
+//	along with the version of Opensearch it is based on, and it is written back to Opensearch once Opensearch is available.
+//
+// Return:
+//
+//	(StateVersion, error): Returns the new version and ErrStateConflict if the state was updated since it was read
+func (m *mirrorStateStore) Put(content []byte, version StateVersion) (StateVersion, error) {
+	m.mirror.mutex.Lock()
+	defer m.mirror.mutex.Unlock()
+	// If the state was read from the updates in the file, it is based on the version of Opensearch the updates are based on
+	baseVersion := version
+	file, fileErr := m.mirror.read()
+	if fileErr == nil && file.Unsynced && file.Version == version {
+		baseVersion = file.Base
+	}
+
+	newVersion, err := m.primary.Put(content, baseVersion)
+	if err == nil {
+		m.writeMirror(content, newVersion)
+		return newVersion, nil
+	} else if errors.Is(err, ErrStateConflict) {
+		return version, err
+	}
+
+	log.Warn.Println("Unable to write the state to Opensearch, writing it to ", m.mirror.path, ": ", err)
+	newVersion, err = m.mirror.checkVersion(version)
+	if err != nil {
+		return version, err
+	}
+	if err := m.mirror.write(stateFile{Version: newVersion, Unsynced: true, Base: baseVersion, State: content}); err != nil {
+		return version, err
+	}
+	return newVersion, nil
+}
+
+// Input:
+//
+// Caller:
+//
+//	Object of mirrorStateStore
+//
+// Description:
+//
+//	Reads the state like Get, but never writes to Opensearch or to the file. The updates made in the file while Opensearch
+//	was unavailable are newer than the state of Opensearch, so they are returned until they are written back by the leader.
+//
+// Return:
+//
+//	([]byte, StateVersion, error): Returns the state, its version and error if any
+func (m *mirrorStateStore) peek() ([]byte, StateVersion, error) {
+	m.mirror.mutex.Lock()
+	defer m.mirror.mutex.Unlock()
+	file, fileErr := m.mirror.read()
+	if fileErr == nil && file.Unsynced {
+		return file.State, file.Version, nil
+	}
+	content, version, err := m.primary.Get()
+	if err != nil && err != ErrStateNotFound {
+		log.Warn.Println("Unable to read the state from Opensearch, reading it from ", m.mirror.path, ": ", err)
+		if fileErr != nil {
+			return nil, StateVersion{}, fileErr
+		}
+		return file.State, file.Version, nil
+	}
+	return content, version, err
+}
+
+// Input:
+//
+//	content ([]byte): The state stored in Opensearch
+//	version (StateVersion): The version of the state in Opensearch
+//
+// Caller:
+//
+//	Object of mirrorStateStore
+//
+// Description:
+//
+//	Overwrites the file with the state stored in Opensearch. The mutex of the file needs to be held by the caller.
+//
+// Return:
+func (m *mirrorStateStore) writeMirror(content []byte, version StateVersion) {
+	if err := m.mirror.write(stateFile{Version: version, State: content}); err != nil {
+		log.Error.Println("Unable to mirror the state to ", m.mirror.path, ": ", err)
+	}
+}
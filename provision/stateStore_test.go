@@ -0,0 +1,130 @@
+package provision
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// A store which fails every call while it is unavailable, used as the Opensearch store of the mirror store.
+type unavailableStateStore struct {
+	store         StateStore
+	isUnavailable bool
+}
+
+func (u *unavailableStateStore) Get() ([]byte, StateVersion, error) {
+	if u.isUnavailable {
+		return nil, StateVersion{}, errors.New("the store is unavailable")
+	}
+	return u.store.Get()
+}
+
+func (u *unavailableStateStore) Put(content []byte, version StateVersion) (StateVersion, error) {
+	if u.isUnavailable {
+		return version, errors.New("the store is unavailable")
+	}
+	return u.store.Put(content, version)
+}
+
+func TestFileStateStoreNotFound(t *testing.T) {
+	store := &fileStateStore{path: filepath.Join(t.TempDir(), "state.json")}
+	if _, _, err := store.Get(); err != ErrStateNotFound {
+		t.Errorf("expected ErrStateNotFound got %v", err)
+	}
+}
+
+func TestFileStateStorePut(t *testing.T) {
+	store := &fileStateStore{path: filepath.Join(t.TempDir(), "state.json")}
+	version, err := store.Put([]byte(`{"CurrentState":"normal"}`), StateVersion{})
+	if err != nil {
+		t.Fatalf("failed to create the state: %v", err)
+	}
+	version, err = store.Put([]byte(`{"CurrentState":"provisioning_scaleup"}`), version)
+	if err != nil {
+		t.Fatalf("failed to update the state: %v", err)
+	}
+	content, readVersion, err := store.Get()
+	if err != nil {
+		t.Fatalf("failed to get the state: %v", err)
+	}
+	if string(content) != `{"CurrentState":"provisioning_scaleup"}` || readVersion != version {
+		t.Errorf("expected the updated state got %s with version %v", content, readVersion)
+	}
+	files, _ := os.ReadDir(filepath.Dir(store.path))
+	if len(files) != 1 {
+		t.Errorf("expected only the state file got %d files", len(files))
+	}
+}
+
+func TestFileStateStoreConflict(t *testing.T) {
+	store := &fileStateStore{path: filepath.Join(t.TempDir(), "state.json")}
+	version, err := store.Put([]byte(`{"CurrentState":"normal"}`), StateVersion{})
+	if err != nil {
+		t.Fatalf("failed to create the state: %v", err)
+	}
+	if _, err := store.Put([]byte(`{"CurrentState":"normal"}`), StateVersion{}); err != ErrStateConflict {
+		t.Errorf("expected ErrStateConflict on creating an existing state got %v", err)
+	}
+	if _, err := store.Put([]byte(`{"CurrentState":"provisioning_scaleup"}`), version); err != nil {
+		t.Errorf("failed to update the state: %v", err)
+	}
+	if _, err := store.Put([]byte(`{"CurrentState":"provisioning_scaledown"}`), version); err != ErrStateConflict {
+		t.Errorf("expected ErrStateConflict on updating a stale version got %v", err)
+	}
+}
+
+func TestMirrorStateStoreWriteBack(t *testing.T) {
+	dir := t.TempDir()
+	primary := &unavailableStateStore{store: &fileStateStore{path: filepath.Join(dir, "primary.json")}}
+	store := &mirrorStateStore{primary: primary, mirror: &fileStateStore{path: filepath.Join(dir, "mirror.json")}}
+	version, err := store.Put([]byte(`{"CurrentState":"normal"}`), StateVersion{})
+	if err != nil {
+		t.Fatalf("failed to create the state: %v", err)
+	}
+
+	primary.isUnavailable = true
+	version, err = store.Put([]byte(`{"CurrentState":"provisioning_scaleup"}`), version)
+	if err != nil {
+		t.Fatalf("expected the state to be written to the mirror got %v", err)
+	}
+	content, readVersion, err := store.Get()
+	if err != nil || string(content) != `{"CurrentState":"provisioning_scaleup"}` || readVersion != version {
+		t.Errorf("expected the state of the mirror got %s with version %v and error %v", content, readVersion, err)
+	}
+
+	primary.isUnavailable = false
+	content, version, err = store.Get()
+	if err != nil || string(content) != `{"CurrentState":"provisioning_scaleup"}` {
+		t.Fatalf("expected the state of the mirror to be written back got %s and error %v", content, err)
+	}
+	primaryContent, primaryVersion, err := primary.Get()
+	if err != nil || string(primaryContent) != `{"CurrentState":"provisioning_scaleup"}` || primaryVersion != version {
+		t.Errorf("expected the written back state in the primary store got %s with version %v and error %v", primaryContent, primaryVersion, err)
+	}
+}
+
+func TestMirrorStateStoreDiscardsConflictingUpdate(t *testing.T) {
+	dir := t.TempDir()
+	primary := &unavailableStateStore{store: &fileStateStore{path: filepath.Join(dir, "primary.json")}}
+	store := &mirrorStateStore{primary: primary, mirror: &fileStateStore{path: filepath.Join(dir, "mirror.json")}}
+	version, err := store.Put([]byte(`{"CurrentState":"normal"}`), StateVersion{})
+	if err != nil {
+		t.Fatalf("failed to create the state: %v", err)
+	}
+
+	primary.isUnavailable = true
+	if _, err := store.Put([]byte(`{"CurrentState":"provisioning_scaleup"}`), version); err != nil {
+		t.Fatalf("expected the state to be written to the mirror got %v", err)
+	}
+	// Another node updates the primary store in the meantime
+	primary.isUnavailable = false
+	if _, err := primary.Put([]byte(`{"CurrentState":"provisioning_scaledown"}`), version); err != nil {
+		t.Fatalf("failed to update the primary store: %v", err)
+	}
+
+	content, _, err := store.Get()
+	if err != nil || string(content) != `{"CurrentState":"provisioning_scaledown"}` {
+		t.Errorf("expected the state of the primary store got %s and error %v", content, err)
+	}
+}
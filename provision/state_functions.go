@@ -1,31 +1,27 @@
 package provision
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	osutils "github.com/maplelabs/opensearch-scaling-manager/opensearchUtils"
+	"github.com/maplelabs/opensearch-scaling-manager/config"
+	"github.com/maplelabs/opensearch-scaling-manager/leader"
 	utils "github.com/maplelabs/opensearch-scaling-manager/utilities"
 	"time"
 )
 
 // This struct contains the State of the opensearch scaling manager
-// States can be of following types: (May change in real implementation with new stages identified)
-// At any point, the state should have either "scaleup/scaledown" to identify the current operation happening
-//
-//   - normal : This is the state when the recommnedation will be provisioned.
-//   - provisioning_scaleup/provisioning_scaledown : Once the provision module will start provisioning it will set this state.
-//   - start_scaleup_process/start_scaledown_process : Indicates start of scaleup/scaledown process
-//   - scaleup_triggered_spin_vm: Indicates trigger for spinning new vms while scaleup
-//   - scaledown_node_identified: A state to identify node identification to scaledown
-//   - provisioning_scaleup_completed/provisioning_scaledown_completed : Once the provision is completed then this state will be state.
-//   - provisioning_scaleup_failed/provisioning_scaledown_failed: If the provision is failed then this state will be set.
-//   - provisioned_scaleup_successfully/provisioned_scaledown_successfully: If the provision is completed and cluster state is green then this state will be set.
+// The states and the transitions allowed between them are declared in the state machine (stateMachine.go).
+// The state needs to be changed only using Transition, which rejects the illegal transitions.
 type State struct {
 	// CurrentState indicate the current state of the scaling manager
-	CurrentState string
+	CurrentState ProvisionState
 	// PreviousState indicates the previous state of the scaling manager
-	PreviousState string
+	PreviousState ProvisionState
+	// Time when the current state was entered
+	StateEnteredTime int64
+	// The last transitions of the state along with the node which made them
+	Transitions []StateTransition
 	// Remark indicates the additional remarks for the state of the scaling manager
 	Remark string
 	// Last Provisioned time is when the last successful provision was completed
@@ -46,28 +42,78 @@ type State struct {
 	_documentType string
 	// Timestamp
 	Timestamp int64
-	// Node Ip storage
-	NodeIp string
-	// Node Name
-	NodeName string
-	// Instance ID
-	InstanceId string
+	// Ip addresses of the nodes being added/removed
+	NodeIps []string
+	// Names of the nodes being removed
+	NodeNames []string
+	// Instance IDs of the nodes being added
+	InstanceIds []string
+	// Time when the last scale up was completed, successfully or not
+	LastScaleUpTime int64
+	// Time when the last scale down was completed, successfully or not
+	LastScaleDownTime int64
+	// Time since which every task is continuously recommended, used for the stabilization window of the task
+	RecommendedSince map[string]int64
+	// Recommendations waiting to be provisioned
+	RecommendationQueue RecommendationQueue
+	// Fencing token of the leader lease held by the node which last updated the state
+	FencingToken int64
+	// Number of times the phase of the current state was retried
+	Retries int
+	// Paused indicates that the recommendations are suspended by an operator on all the nodes
+	Paused bool
+	// PauseReason indicates why the recommendations are paused
+	PauseReason string
+	// AbortRequested indicates that an operator requested the provision in progress to be aborted
+	AbortRequested bool
+	// ManualScale indicates the scale requested by an operator which is waiting to be provisioned
+	ManualScale *ManualScaleRequest
+	// Version of the stored state when it was last read or updated
+	version StateVersion
 }
 
+// ErrStateConflict is returned when the state document was updated by another node since it was read.
+var ErrStateConflict = errors.New("the state was updated by another node")
+
+// ErrStaleLeader is returned when the state was updated by a leader with a newer lease than this node.
+// It wraps ErrStateConflict, as the node needs to back off in the same way.
+var ErrStaleLeader = fmt.Errorf("%w with a newer leader lease", ErrStateConflict)
+
+// The number of times a modification of the state is retried when the state was updated by another node.
+const maxStateUpdateRetries = 3
+
 var state = new(State)
 
 // A global variable which stores the document ID of the State document that will to stored and fetched frm Opensearch
 var docId string
 
+// A global variable which stores the store in which the state is persisted
+var stateStore StateStore
+
 // Input:
 //
 // Description:
 //
 //	Creates a unique document ID for maintaining the state of the provisioning system and updates the global variable
+//	The state is persisted in Opensearch unless another store is initialized with InitializeStateStore.
 //
 // Return:
 func InitializeDocId() {
 	docId = fmt.Sprint(utils.Hash(utils.GetClusterId()))
+	stateStore = &osStateStore{docId: docId}
+}
+
+// Input:
+//
+//	storeCfg (config.StateStoreConfig): The backend of the state and the path of the state file
+//
+// Description:
+//
+//	Initializes the store in which the state is persisted. InitializeDocId needs to be called before.
+//
+// Return:
+func InitializeStateStore(storeCfg config.StateStoreConfig) {
+	stateStore = NewStateStore(storeCfg, docId)
 }
 
 // Input:
@@ -77,70 +123,157 @@ func InitializeDocId() {
 //
 // Description:
 //      GetCurrentState will update the state variable pointer such that it is in sync with the updated values.
-//      Reads the document from Opensearch and updates the Struct
+//      Reads the state from the state store and updates the Struct
+//      The version of the state is recorded, so that UpdateState overwrites only this version.
+//      If the state does not exist, it is created with the normal state, unless another node created it first.
 //
 // Return:
 
 func (s *State) GetCurrentState() {
-	// Get the document.
+	// Get the state.
 
-	searchResponse, err := osutils.SearchDoc(context.Background(), docId)
-	if err != nil {
-		log.Panic.Println("failed to search document: ", err)
-		panic(err)
-	}
-	defer searchResponse.Body.Close()
-	var stateInterface map[string]interface{}
-	log.Debug.Println("Get resp: ", searchResponse)
-	if searchResponse.Status() == "404 Not Found" {
+	content, version, err := stateStore.Get()
+	if err == ErrStateNotFound {
 		//Setting the initial state
-		s.CurrentState = "normal"
+		s.CurrentState = StateNormal
 		s._documentType = "State"
 		s.StatTag = "State"
-		s.UpdateState()
+		s.version = StateVersion{}
+		// The state is created rather than updated, so it is not fenced by the token of a state read earlier
+		s.FencingToken = 0
+		if errors.Is(s.UpdateState(), ErrStateConflict) {
+			// Another node created the state in the meantime
+			s.GetCurrentState()
+		}
 		return
-	}
-	jsonErr := json.NewDecoder(searchResponse.Body).Decode(&stateInterface)
-	if jsonErr != nil {
-		log.Panic.Println("Unable to decode the response into interface: ", jsonErr)
-		panic(jsonErr)
-	}
-	// convert map to json
-	jsonString, errr := json.Marshal(stateInterface["_source"].(map[string]interface{}))
-	if errr != nil {
-		log.Panic.Println("Unable to unmarshal interface: ", errr)
-		panic(errr)
+	} else if err != nil {
+		log.Panic.Println("failed to get the state: ", err)
+		panic(err)
 	}
 
 	// convert json to struct
-	json.Unmarshal(jsonString, s)
+	if err := json.Unmarshal(content, s); err != nil {
+		log.Panic.Println("Unable to unmarshal the state: ", err)
+		panic(err)
+	}
+	s.version = version
 }
 
 // Input:
 //
 // Description:
 //
-//      Updates the opensearch document with the values in state Struct pointer.
+//      Reads the state from the state store without ever writing it, Ex: for the status command.
+//      Unlike GetCurrentState, the state is not created when it does not exist, the leader lease is not touched
+//      and the errors are returned rather than panicking.
 //
 // Return:
+//      (State, bool, error): Returns the state, false if the state was never stored and error if any
 
-func (s *State) UpdateState() {
-	// Update the document.
+func ReadState() (State, bool, error) {
+	var s State
+	get := stateStore.Get
+	if mirror, ok := stateStore.(*mirrorStateStore); ok {
+		get = mirror.peek
+	}
+	content, version, err := get()
+	if err == ErrStateNotFound {
+		return s, false, nil
+	} else if err != nil {
+		return s, false, err
+	}
+	if err := json.Unmarshal(content, &s); err != nil {
+		return s, false, fmt.Errorf("unable to unmarshal the state: %w", err)
+	}
+	s.version = version
+	return s, true, nil
+}
+
+// Input:
+//
+// Description:
+//
+//      Updates the state store with the values in state Struct pointer.
+//      The state is updated only if it was not updated by another node since it was read by GetCurrentState.
+//      If it was, the update is rejected and the caller needs to read the state again before deciding to retry,
+//      so that two nodes can not both move the state out of normal and own a provision.
+//      The update carries the fencing token of the leader lease held by this node and it is rejected if the state
+//      was last updated with a newer token, so that a node which lost the lease does not overwrite the new leader.
+//
+// Return:
+//      (error): Returns ErrStateConflict if the state was updated by another node and ErrStaleLeader if it was updated by a newer leader
+
+func (s *State) UpdateState() error {
+	return s.update(true)
+}
+
+// Input:
+//      isFenced (bool): Whether the update is rejected if the state was updated by a newer leader
+//
+// Caller:
+//      Object of type State
+//
+// Description:
+//      Updates the state store as described in UpdateState. An update which is not fenced, e.g. by an operator,
+//      keeps the fencing token of the state, so that the leader can continue to update the state.
+//
+// Return:
+//      (error): Returns ErrStateConflict if the state was updated by another node and ErrStaleLeader if it was updated by a newer leader
+
+func (s *State) update(isFenced bool) error {
+	// Update the state.
 
 	s.Timestamp = time.Now().UnixMilli()
+	if isFenced {
+		fencingToken := leader.FencingToken()
+		if fencingToken < s.FencingToken {
+			log.Warn.Println(fmt.Sprintf("The state was not updated as it was updated with the fencing token %d which is newer than %d", s.FencingToken, fencingToken))
+			return ErrStaleLeader
+		}
+		s.FencingToken = fencingToken
+	}
 
-	state, err := json.Marshal(s)
+	content, err := json.Marshal(s)
 	if err != nil {
 		log.Panic.Println("json.Marshal ERROR: ", err)
 		panic(err)
 	}
-	content := string(state)
 
-	updateResponse, err := osutils.UpdateDoc(context.Background(), docId, content)
-	if err != nil {
-		log.Panic.Println("failed to update document: ", err)
+	version, err := stateStore.Put(content, s.version)
+	if errors.Is(err, ErrStateConflict) {
+		log.Warn.Println("The state was not updated as it was updated by another node")
+		return err
+	} else if err != nil {
+		log.Panic.Println("failed to update the state: ", err)
 		panic(err)
 	}
-	defer updateResponse.Body.Close()
-	log.Debug.Println("Update resp: ", updateResponse)
+	s.version = version
+	return nil
+}
+
+// Input:
+//      isFenced (bool): Whether the update is rejected if the state was updated by a newer leader, which is false for the operators
+//      modify (func() bool): Modifies the state and returns false if the state does not need to be updated
+//
+// Description:
+//      Reads the state, modifies it and updates it. If the state was updated by another node in the meantime,
+//      the state is read and modified again, so that the modification is not lost nor overwrites the other update.
+//
+// Return:
+//      (error): Returns ErrStateConflict if the state could not be updated after the retries and ErrStaleLeader if it was updated by a newer leader
+
+func modifyState(isFenced bool, modify func() bool) error {
+	for i := 0; i < maxStateUpdateRetries; i++ {
+		state.GetCurrentState()
+		if !modify() {
+			return nil
+		}
+		err := state.update(isFenced)
+		// The update of a node which lost the leader lease is rejected however many times it is retried
+		if !errors.Is(err, ErrStateConflict) || errors.Is(err, ErrStaleLeader) {
+			return err
+		}
+	}
+	log.Error.Println("Unable to update the state after ", maxStateUpdateRetries, " retries")
+	return ErrStateConflict
 }
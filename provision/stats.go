@@ -0,0 +1,75 @@
+package provision
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	osutils "github.com/maplelabs/opensearch-scaling-manager/opensearchUtils"
+)
+
+// This struct contains the status of a provision indexed by PushToOs with the StatTag ProvisionStats.
+type ProvisionStats struct {
+	// RuleTriggered indicates the operation provisioned. i.e., scale_up/scale_down
+	RuleTriggered string
+	// RulesResponsible indicates the rules responsible for the provision
+	RulesResponsible string
+	// NumNodes indicates the number of nodes added or removed
+	NumNodes int
+	// Status indicates the result of the provision. i.e., Success, Failed, Aborted, NeedsAttention
+	Status string
+	// FailureReason indicates why the provision failed
+	FailureReason string `json:",omitempty"`
+	// StuckPhase indicates the phase in which a timed out provision was stuck
+	StuckPhase string `json:",omitempty"`
+	// ProvisionStartTime indicates when the provision started
+	ProvisionStartTime int64
+	// ProvisionEndTime indicates when the provision ended
+	ProvisionEndTime int64
+	// TimeTaken indicates the duration of the provision
+	TimeTaken string
+}
+
+// Input:
+//
+//	count (int): Number of documents to be fetched
+//
+// Description:
+//
+//	Fetches the latest ProvisionStats documents from Opensearch.
+//
+// Return:
+//
+//	([]ProvisionStats, error): Returns the provisions, latest first, and error if they could not be fetched
+func GetProvisionStats(count int) ([]ProvisionStats, error) {
+	query := `{
+          "size": ` + strconv.Itoa(count) + `,
+          "sort": [{"Timestamp": {"order": "desc"}}],
+          "query": {"match": {"StatTag": "ProvisionStats"}}
+        }`
+	searchResp, err := osutils.SearchQuery(context.Background(), []byte(query))
+	if err != nil {
+		return nil, err
+	}
+	defer searchResp.Body.Close()
+	if searchResp.IsError() {
+		return nil, fmt.Errorf("unable to fetch the provision stats: %s", searchResp.String())
+	}
+
+	var result struct {
+		Hits struct {
+			Hits []struct {
+				Source ProvisionStats `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(searchResp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	provisionStats := make([]ProvisionStats, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		provisionStats = append(provisionStats, hit.Source)
+	}
+	return provisionStats, nil
+}
@@ -1,115 +1,93 @@
 package provision
 
 import (
-	"context"
-	"encoding/json"
+	"fmt"
 	"regexp"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/maplelabs/opensearch-scaling-manager/cluster"
 	"github.com/maplelabs/opensearch-scaling-manager/cluster_sim"
 	"github.com/maplelabs/opensearch-scaling-manager/config"
-	osutils "github.com/maplelabs/opensearch-scaling-manager/opensearchUtils"
 	utils "github.com/maplelabs/opensearch-scaling-manager/utilities"
 )
 
 // Input:
 //
-//	recommendationQueue ([]map[string]string): Recommendations provided by the recommendation engine in the form of an array of strings
 //	clusterCfg (config.ClusterDetails): Cluster Level config details
 //	usrCfg (config.UserConfig): User defined config for applicatio behavior
 //
 // Description:
 //
 //	GetRecommendation will fetch the recommendation from recommendation queue.
+//	The expired recommendations are removed and the conflicts are resolved using the conflict policy.
 //	It will call the Provisioner with all the user defined configs.
 //	Triggers the provisioning
 //
 // Return:
-func GetRecommendation(recommendationQueue []map[string]string, clusterCfg config.ClusterDetails, usrCfg config.UserConfig, t *time.Time) {
-	var clusterCurrent cluster.ClusterDynamic
-	scaleRegexString := `(scale_up|scale_down)_by_([0-9]+)`
-	scaleRegex := regexp.MustCompile(scaleRegexString)
-	if len(recommendationQueue) > 0 {
-		if usrCfg.MonitorWithSimulator {
-			clusterCurrent = cluster_sim.GetClusterCurrent(usrCfg.IsAccelerated)
-		} else {
-			clusterCurrent, _ = cluster.GetClusterCurrent(false)
-		}
-
-		state.GetCurrentState()
-		if state.CurrentState == "normal" {
-			var subMatch []string
-			var task string
-			for task, _ = range recommendationQueue[0] {
-				subMatch = scaleRegex.FindStringSubmatch(task)
-			}
-
-			numNodes, _ := strconv.Atoi(subMatch[2])
-			operation := subMatch[1]
-
-			// Call scale down provisioning only when the cluster status is green. No recommended to scale down when cluster is in yellow or red state
-			if operation == "scale_down" && clusterCurrent.ClusterStatus != "green" {
-				log.Warn.Println("Recommendation can not be provisioned as open search cluster is unhealthy for a scale_down. \n Discarding this recommendation")
-				return
-			}
-
-			ruleResponsible := recommendationQueue[0][task]
-			numNodesProceed := checkNumNodesCondition(operation, clusterCfg, usrCfg)
-			if !numNodesProceed {
-				return
-			}
-			previousProvisionProceed := comparePreviousProvision(ruleResponsible, operation)
-			if !previousProvisionProceed {
-				return
-			}
+func GetRecommendation(clusterCfg config.ClusterDetails, usrCfg config.UserConfig, t *time.Time) {
+	state.GetCurrentState()
+	if state.CurrentState != StateNormal {
+		log.Warn.Println("Recommendation can not be provisioned as open search cluster is already in provisioning phase.")
+		return
+	}
 
-			TriggerProvision(clusterCfg, usrCfg, numNodes, t, operation, ruleResponsible)
-		} else {
-			log.Warn.Println("Recommendation can not be provisioned as open search cluster is already in provisioning phase.")
-		}
+	expiry := time.Duration(usrCfg.RecommendationExpiry) * time.Second
+	if expiry == 0 {
+		expiry = 2 * time.Duration(usrCfg.RecommendationPollingInterval) * time.Second
 	}
+	recommendation, ok := PopRecommendation(usrCfg.ConflictPolicy, expiry)
+	if !ok {
+		return
+	}
+	log.Info.Println(fmt.Sprintf("Provisioning the recommendation %s, recommended %d times since %s", recommendation.TaskName, recommendation.Count, time.UnixMilli(recommendation.CreatedTime)))
+	provisionTask(clusterCfg, usrCfg, t, recommendation.TaskName, recommendation.Operation, recommendation.NumNodes, recommendation.RulesResponsible)
 }
 
 // Input:
 //
+//	clusterCfg (config.ClusterDetails): Cluster Level config details
+//	usrCfg (config.UserConfig): User defined config for application behavior
+//	taskName (string): The task to be provisioned. i.e., scale_up_by_1
+//	operation (string): The operation of the task (scale_up or scale_down)
+//	numNodes (int): Number of nodes to be added or removed
+//	rulesResponsible (string): The rules responsible for the task
+//
 // Description:
 //
-//	Generates the query string to get the latest document of successful Provision
+//	Triggers the provisioning of the task, unless it is a scale down while the cluster is not green or
+//	it takes the cluster beyond the max or min nodes. In dry run mode, the provision is only recorded.
 //
 // Return:
-//
-//	(string): Returns the query string that can be given as an OS query api parameter.
-func getLatestProvisionQuery() string {
-	return `{
-                  "size": 1,
-                  "sort": {
-                    "Timestamp": "desc"
-                  },
-                  "query": {
-                    "bool": {
-                      "must": [
-                        {
-                          "match": {
-                            "StatTag": "ProvisionStats"
-                          }
-                        },
-                        {
-                          "match": {
-                            "Status": "Success"
-                          }
-                        }
-                      ]
-                    }
-                  }
-                }`
+func provisionTask(clusterCfg config.ClusterDetails, usrCfg config.UserConfig, t *time.Time, taskName, operation string, numNodes int, rulesResponsible string) {
+	var clusterCurrent cluster.ClusterDynamic
+	if usrCfg.MonitorWithSimulator {
+		clusterCurrent = cluster_sim.GetClusterCurrent(usrCfg.IsAccelerated)
+	} else {
+		clusterCurrent, _ = cluster.GetClusterCurrent(false)
+	}
+
+	// Call scale down provisioning only when the cluster status is green. No recommended to scale down when cluster is in yellow or red state
+	if operation == "scale_down" && clusterCurrent.ClusterStatus != "green" {
+		log.Warn.Println("The ", taskName, " can not be provisioned as open search cluster is unhealthy for a scale_down. \n Discarding this task")
+		return
+	}
+
+	numNodesProceed := checkNumNodesCondition(operation, numNodes, clusterCfg, usrCfg)
+	if !numNodesProceed {
+		return
+	}
+	if usrCfg.DryRun {
+		PushDryRunToOs(taskName, rulesResponsible, getCurrentNumNodes(usrCfg))
+		return
+	}
+	TriggerProvision(clusterCfg, usrCfg, numNodes, t, operation, rulesResponsible)
 }
 
 // Input:
 //
 //	operation (string): The operation recommended (scale_up or scale_down)
+//	numNodes (int): Number of nodes to be added or removed
 //	clusterCfg (config.ClusterDetails): User defined configuration which contains the max and min nodes specified for the cluster
 //
 // Description:
@@ -120,110 +98,128 @@ func getLatestProvisionQuery() string {
 // Return:
 //
 //	(bool): Returns a bool value to decide to proceed with provisioning or drop the recommendation
-func checkNumNodesCondition(operation string, clusterCfg config.ClusterDetails, usrCfg config.UserConfig) bool {
-	var numNodes int
-	if usrCfg.MonitorWithSimulator {
-		clusterDynamic := cluster_sim.GetClusterCurrent(usrCfg.IsAccelerated)
-		numNodes = clusterDynamic.NumNodes
-	} else {
-		numNodes = len(utils.GetNodes())
+func checkNumNodesCondition(operation string, numNodes int, clusterCfg config.ClusterDetails, usrCfg config.UserConfig) bool {
+	if err := validateNumNodes(operation, numNodes, getCurrentNumNodes(usrCfg), clusterCfg); err != nil {
+		log.Warn.Println(err)
+		return false
 	}
+	return true
+}
+
+// Input:
+//
+//	operation (string): The operation (scale_up or scale_down)
+//	numNodes (int): Number of nodes to be added or removed
+//	currentNodes (int): Number of nodes currently in the cluster
+//	clusterCfg (config.ClusterDetails): User defined configuration which contains the max and min nodes specified for the cluster
+//
+// Description:
+//
+//	Checks that the operation does not take the cluster beyond the max nodes or below the min nodes.
+//
+// Return:
+//
+//	(error): Returns error if the operation can not be provisioned
+func validateNumNodes(operation string, numNodes int, currentNodes int, clusterCfg config.ClusterDetails) error {
 	switch operation {
 	case "scale_up":
-		if numNodes+1 > clusterCfg.MaxNodesAllowed {
-			log.Warn.Println("Cannot scale up as the maximum number of nodes for this cluster specified is reached.\n If we need the scale up to take place anyway, consider increasing the max nodes in config.yaml")
-			return false
+		if currentNodes+numNodes > clusterCfg.MaxNodesAllowed {
+			return fmt.Errorf("Cannot scale up as the maximum number of nodes for this cluster specified is reached.\n If we need the scale up to take place anyway, consider increasing the max nodes in config.yaml")
 		}
 	case "scale_down":
-		if numNodes-1 < clusterCfg.MinNodesAllowed {
-			log.Warn.Println("Cannot scale down as the minimum number of nodes for this cluster specified is reached.\n If you need the scale down to take place anyway, consider decreasing the min nodes in config.yaml")
-			return false
+		if currentNodes-numNodes < clusterCfg.MinNodesAllowed {
+			return fmt.Errorf("Cannot scale down as the minimum number of nodes for this cluster specified is reached.\n If you need the scale down to take place anyway, consider decreasing the min nodes in config.yaml")
 		}
 	}
-	return true
+	return nil
 }
 
 // Input:
 //
-//	ruleResponsible (string): The rule responsible for recommendation with delimiters. The last value would contain the decision period of the rule
-//	operation (string): The operation recommended (scale_up or scale_down)
+//	usrCfg (config.UserConfig): User defined config for application behavior
 //
 // Description:
 //
-//	Compares if the the largest decision period of the rules responsible for recommendation overlaps with the previous Provision
-//	Returns false if the above condition is met, as no provision should take place in this case. Return true otherwise
+//	Returns the number of nodes currently in the cluster, or in the simulated cluster when monitoring with the simulator.
 //
 // Return:
 //
-//	(bool): Returns a bool value to decide to proceed with provisioning or drop the recommendation
-func comparePreviousProvision(ruleResponsible string, operation string) bool {
-	// Split the rules if more than one rule is responsible for recommendation
-	splitRules := strings.Split(ruleResponsible, "_and_")
-	var largestDecisionPeriod int
-
-	// Find the largest decision period among the rules responsiblle
-	for _, rule := range splitRules {
-		if decisionPeriod, err := strconv.Atoi(rule[strings.LastIndex(rule, "-")+1:]); err == nil && decisionPeriod > largestDecisionPeriod {
-			largestDecisionPeriod = decisionPeriod
-		} else if err != nil {
-			log.Error.Println("Invalid decision period:", err)
-			return false
-		}
-	}
-
-	// Get the latest document of successful provision happened
-	resp, err := osutils.SearchQuery(context.Background(), []byte(getLatestProvisionQuery()))
-	if err != nil {
-		log.Error.Println("Error querying the last provision document frm Opensearch", err)
-		return false
-	}
-	defer resp.Body.Close()
-
-	var respInterface map[string]interface{}
-
-	decodeErr := json.NewDecoder(resp.Body).Decode(&respInterface)
-	if decodeErr != nil {
-		log.Error.Println("decode Error: ", decodeErr)
-		return false
+//	(int): Returns the number of nodes in the cluster
+func getCurrentNumNodes(usrCfg config.UserConfig) int {
+	if usrCfg.MonitorWithSimulator {
+		clusterDynamic := cluster_sim.GetClusterCurrent(usrCfg.IsAccelerated)
+		return clusterDynamic.NumNodes
 	}
+	return len(utils.GetNodes())
+}
 
-	respHits := respInterface["hits"].(map[string]interface{})["hits"].([]interface{})
-
-	var lastProvisionTime time.Time
-
-	// Get the last successful provision time
-	for _, doc := range respHits {
-		provisionEndTime := doc.(map[string]interface{})["_source"].(map[string]interface{})["ProvisionEndTime"].(float64)
-		lastProvisionTime = time.UnixMilli(int64(provisionEndTime))
+// Input:
+//
+//	operation (string): The operation recommended (scale_up or scale_down)
+//	cooldownAfterScaleUp (time.Duration): The time after the last scale up during which the operation is not provisioned
+//	cooldownAfterScaleDown (time.Duration): The time after the last scale down during which the operation is not provisioned
+//
+// Description:
+//
+//	Checks if the operation is in the cooldown of the last scale up or scale down recorded in the state.
+//	The cooldown applies after failed provisions as well, so that a failing provision is not retried in a loop.
+//
+// Return:
+//
+//	(bool): Returns true if the recommendation needs to be dropped as it is in the cooldown
+func IsInCooldown(operation string, cooldownAfterScaleUp time.Duration, cooldownAfterScaleDown time.Duration) bool {
+	state.GetCurrentState()
+	now := time.Now()
+	remainingAfterScaleUp := time.UnixMilli(state.LastScaleUpTime).Add(cooldownAfterScaleUp).Sub(now)
+	remainingAfterScaleDown := time.UnixMilli(state.LastScaleDownTime).Add(cooldownAfterScaleDown).Sub(now)
+	if state.LastScaleUpTime > 0 && remainingAfterScaleUp > 0 {
+		log.Warn.Println("The ", operation, " is in the cooldown after the last scale up. The remaining cooldown is ", remainingAfterScaleUp)
+		return true
 	}
-
-	duration, dErr := time.ParseDuration(strconv.Itoa(largestDecisionPeriod) + "m")
-	if dErr != nil {
-		log.Error.Println("Error converting string to time.Duration", dErr)
-		return false
+	if state.LastScaleDownTime > 0 && remainingAfterScaleDown > 0 {
+		log.Warn.Println("The ", operation, " is in the cooldown after the last scale down. The remaining cooldown is ", remainingAfterScaleDown)
+		return true
 	}
+	return false
+}
 
-	// If the last provision has occured in the range of the largest decision period and now. Discard the current recommendation
-	diff := time.Now().Sub(lastProvisionTime)
-	if diff < duration {
-		log.Warn.Println("During the current recommendation's decision time, there was already a successful provision. Therefore, discarding this recommendation until next polling interval.")
-		// Warning message for huge decision periods.
-		switch operation {
-		case "scale_up":
-			if duration > time.Duration(5)*time.Hour {
-				log.Warn.Println("The current wait time until next provision if recommended is ", duration-diff)
-				log.Warn.Println("If you believe the delay is too long, please consider reducing the decision period of your rule.")
-			}
-		case "scale_down":
-			if duration > time.Duration(12)*time.Hour {
-				log.Warn.Println("The current wait time until next provision if recommended is ", duration-diff)
-				log.Warn.Println("If you believe the delay is too long, please consider reducing the decision period of your rule.")
+// Input:
+//
+//	recommendedTasks (map[string]bool): Whether every task evaluated is recommended in the current evaluation
+//
+// Description:
+//
+//	Updates the time since which every task is continuously recommended in the state.
+//	The time is recorded when a task is recommended for the first time and removed as soon as the task is not recommended.
+//
+// Return:
+//
+//	(map[string]time.Time): Returns the time since which every recommended task is continuously recommended
+func UpdateRecommendedSince(recommendedTasks map[string]bool) map[string]time.Time {
+	var recommendedSince map[string]time.Time
+	modifyState(true, func() bool {
+		if state.RecommendedSince == nil {
+			state.RecommendedSince = make(map[string]int64)
+		}
+		recommendedSince = make(map[string]time.Time)
+		isUpdated := false
+		for task, isRecommended := range recommendedTasks {
+			since, ok := state.RecommendedSince[task]
+			if isRecommended {
+				if !ok {
+					since = time.Now().UnixMilli()
+					state.RecommendedSince[task] = since
+					isUpdated = true
+				}
+				recommendedSince[task] = time.UnixMilli(since)
+			} else if ok {
+				delete(state.RecommendedSince, task)
+				isUpdated = true
 			}
 		}
-		return false
-	}
-	return true
-
+		return isUpdated
+	})
+	return recommendedSince
 }
 
 // Input:
@@ -247,10 +243,14 @@ func comparePreviousProvision(ruleResponsible string, operation string) bool {
 func TriggerCron(t *time.Time, clusterCfg config.ClusterDetails, userCfg config.UserConfig, ruleResponsible, task string) {
 
 	state.GetCurrentState()
-	if state.CurrentState != "normal" {
+	if state.CurrentState != StateNormal {
 		log.Warn.Println("Provision is already in progress, Event based scaling will be discarded")
 		return
 	}
+	if state.Paused {
+		log.Warn.Println("The recommendations are paused, Event based scaling will be discarded: ", state.PauseReason)
+		return
+	}
 
 	scaleRegexString := `(scale_up|scale_down)_by_([0-9]+)`
 	scaleRegex := regexp.MustCompile(scaleRegexString)
@@ -258,12 +258,20 @@ func TriggerCron(t *time.Time, clusterCfg config.ClusterDetails, userCfg config.
 	var subMatch []string
 
 	subMatch = scaleRegex.FindStringSubmatch(task)
+	if subMatch == nil {
+		log.Error.Println("The event based scaling is discarded as the task ", task, " is not a scale_up_by_N or scale_down_by_N task")
+		return
+	}
 	numNodes, _ := strconv.Atoi(subMatch[2])
 	operation := subMatch[1]
 
-	numNodesProceed := checkNumNodesCondition(operation, clusterCfg, userCfg)
+	numNodesProceed := checkNumNodesCondition(operation, numNodes, clusterCfg, userCfg)
 
 	if numNodesProceed {
+		if userCfg.DryRun {
+			PushDryRunToOs(task, ruleResponsible, getCurrentNumNodes(userCfg))
+			return
+		}
 		log.Info.Println("The ", task, " is triggered as event based scaling and will be provisioned.")
 		TriggerProvision(clusterCfg, userCfg, numNodes, t, operation, ruleResponsible)
 	}
@@ -0,0 +1,357 @@
+package provision
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/maplelabs/opensearch-scaling-manager/config"
+)
+
+// The interval at which the watchdog checks the deadlines of the provision in progress.
+const watchdogInterval = 15 * time.Second
+
+// The interval at which a cancelled provision which has not stopped yet is reported, Ex: when an ansible script hangs.
+const stopWaitWarning = time.Minute
+
+// ErrProvisionTimedOut is returned when the provision missed the deadline of a phase or of the provision.
+var ErrProvisionTimedOut = errors.New("the provision timed out")
+
+// This struct contains the deadline missed by a stuck provision.
+type TimeoutError struct {
+	// Phase indicates the state in which the provision was stuck
+	Phase ProvisionState
+	// Timeout indicates the deadline which was missed
+	Timeout time.Duration
+	// Elapsed indicates the time spent in the phase or in the provision if IsTotal is set
+	Elapsed time.Duration
+	// IsTotal indicates that the deadline of the provision was missed rather than the deadline of the phase
+	IsTotal bool
+}
+
+func (e *TimeoutError) Error() string {
+	if e.IsTotal {
+		return fmt.Sprintf("%s after %s in the phase %s, the provision is running for %s", ErrProvisionTimedOut, e.Timeout, e.Phase, e.Elapsed.Round(time.Second))
+	}
+	return fmt.Sprintf("%s after %s in the phase %s", ErrProvisionTimedOut, e.Elapsed.Round(time.Second), e.Phase)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return ErrProvisionTimedOut
+}
+
+// The watchdog of a provision, which cancels the context of the provision when it is timed out.
+type watchdog struct {
+	mutex  sync.Mutex
+	err    error
+	cancel context.CancelFunc
+}
+
+type watchdogKey struct{}
+
+// The provision running on this node. The process waits for it to complete or to reach a phase from which another node can resume it before exiting.
+var (
+	provisionMutex   sync.Mutex
+	provisionChanged = sync.NewCond(&provisionMutex)
+	isProvisioning   bool
+	isStopping       bool
+	runningPhase     ProvisionState
+)
+
+// Input:
+//
+//	usrCfg (config.UserConfig): User defined config for application behavior
+//	run (func(ctx context.Context) (bool, error)): Runs the provision, which stops once the context is done
+//
+// Description:
+//
+//	Runs the provision while a watchdog checks the deadlines of the provision and whether it is aborted every watchdogInterval.
+//	Once a deadline is missed or the provision is aborted, the context of the provision is cancelled and the provision is waited for to stop,
+//	which it does before entering the next state. The provision is never abandoned while it runs, Ex: while a call to the cloud or an ansible
+//	script hangs, as it would keep updating the state and the instances while it is rolled back or while the next provision runs.
+//	The deadlines are not enforced when the simulator is accelerated, as the time is faked.
+//
+// Return:
+//
+//	(bool, error): Returns whether the provision is completed and the error of the provision, the TimeoutError if it timed out or ErrProvisionAborted
+func runWithWatchdog(usrCfg config.UserConfig, run func(ctx context.Context) (bool, error)) (bool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := &watchdog{cancel: cancel}
+	ctx = context.WithValue(ctx, watchdogKey{}, w)
+	go w.watch(ctx, usrCfg.ProvisionTimeout, !(usrCfg.MonitorWithSimulator && usrCfg.IsAccelerated))
+
+	type result struct {
+		isProvisioned bool
+		err           error
+	}
+	done := make(chan result, 1)
+	go func() {
+		isProvisioned, err := run(ctx)
+		done <- result{isProvisioned, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.isProvisioned, r.err
+	case <-ctx.Done():
+	}
+	waitStart := time.Now()
+	for {
+		select {
+		case r := <-done:
+			if r.isProvisioned {
+				return true, nil
+			}
+			return false, stopCause(ctx)
+		case <-time.After(stopWaitWarning):
+			log.Error.Println(fmt.Sprintf("The provision has not stopped %s after it was cancelled (%s), it is rolled back once the call it is waiting for returns. Please check the ansible logs (logs/playbook.log) if it does not stop.", time.Since(waitStart).Round(time.Second), stopCause(ctx)))
+		}
+	}
+}
+
+// Input:
+//
+//	ctx (context.Context): Context of the provision
+//	timeoutCfg (config.TimeoutConfig): The deadlines of the provision
+//	enforceDeadlines (bool): Whether the deadlines are checked, besides the abort
+//
+// Caller:
+//
+//	Object of type watchdog
+//
+// Description:
+//
+//	Checks the deadlines of the state every watchdogInterval until the context is done, and cancels it once a deadline is missed
+//	or an operator requested the provision to be aborted.
+//	The state is read from the store rather than shared with the provision, which keeps updating it.
+//
+// Return:
+func (w *watchdog) watch(ctx context.Context, timeoutCfg config.TimeoutConfig, enforceDeadlines bool) {
+	ticker := time.NewTicker(watchdogInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		current := new(State)
+		current.GetCurrentState()
+		err := current.checkAbort()
+		if err == nil && enforceDeadlines {
+			err = current.checkDeadline(timeoutCfg, time.Now())
+		}
+		if err != nil {
+			log.Error.Println(err)
+			w.mutex.Lock()
+			w.err = err
+			w.mutex.Unlock()
+			w.cancel()
+			return
+		}
+	}
+}
+
+// Input:
+//
+//	ctx (context.Context): Context of the provision
+//
+// Description:
+//
+//	Returns why the provision needs to stop.
+//
+// Return:
+//
+//	(error): Returns the TimeoutError or ErrProvisionAborted if the watchdog stopped the provision, the error of the context if it is otherwise done and nil if the provision can continue
+func stopCause(ctx context.Context) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	if w, ok := ctx.Value(watchdogKey{}).(*watchdog); ok {
+		w.mutex.Lock()
+		defer w.mutex.Unlock()
+		if w.err != nil {
+			return w.err
+		}
+	}
+	return ctx.Err()
+}
+
+// Input:
+//
+//	ctx (context.Context): Context of the provision
+//	d (time.Duration): Time to sleep
+//
+// Description:
+//
+//	Sleeps for the duration unless the provision needs to stop in the meantime.
+//
+// Return:
+//
+//	(error): Returns why the provision needs to stop if it was woken up before the duration
+func sleepUnlessStopped(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return stopCause(ctx)
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Input:
+//
+//	timeoutCfg (config.TimeoutConfig): The deadlines of the provision
+//	now (time.Time): The time at which the deadlines are checked
+//
+// Caller:
+//
+//	Object of type State
+//
+// Description:
+//
+//	Checks if the provision has been in the current state for longer than the timeout of the state,
+//	using the StateEnteredTime, or running for longer than the total timeout, using the ProvisionStartTime.
+//	The time spent in the retries of a phase is counted towards the timeout of the phase.
+//
+// Return:
+//
+//	(error): Returns the TimeoutError if a deadline is missed
+func (s *State) checkDeadline(timeoutCfg config.TimeoutConfig, now time.Time) error {
+	operation := s.CurrentState.Operation()
+	if operation == "" || s.CurrentState == failedState(operation) {
+		return nil
+	}
+	if timeoutCfg.Total > 0 && s.ProvisionStartTime != 0 {
+		timeout := time.Duration(timeoutCfg.Total) * time.Second
+		if elapsed := now.Sub(time.UnixMilli(s.ProvisionStartTime)); elapsed > timeout {
+			return &TimeoutError{Phase: s.CurrentState, Timeout: timeout, Elapsed: elapsed, IsTotal: true}
+		}
+	}
+	timeout, ok := getStateTimeout(timeoutCfg, s.CurrentState)
+	if !ok || s.StateEnteredTime == 0 {
+		return nil
+	}
+	if elapsed := now.Sub(time.UnixMilli(s.StateEnteredTime)); elapsed > timeout {
+		return &TimeoutError{Phase: s.CurrentState, Timeout: timeout, Elapsed: elapsed}
+	}
+	return nil
+}
+
+// Input:
+//
+//	timeoutCfg (config.TimeoutConfig): The deadlines of the provision
+//	phase (ProvisionState): The state of the provision in the phase
+//
+// Description:
+//
+//	Returns the timeout of the phase, which is the configured one or the default of the state.
+//
+// Return:
+//
+//	(time.Duration, bool): Returns the timeout and false if the phase has no timeout
+func getStateTimeout(timeoutCfg config.TimeoutConfig, phase ProvisionState) (time.Duration, bool) {
+	if timeout, ok := timeoutCfg.Phases[string(phase)]; ok && timeout > 0 {
+		return time.Duration(timeout) * time.Second, true
+	}
+	timeout, ok := stateTimeouts[phase]
+	return timeout, ok
+}
+
+// Input:
+//
+// Description:
+//
+//	Marks the start of a provision on this node. A provision is not started while another one is running on this node
+//	or once the process is stopping.
+//
+// Return:
+//
+//	(bool): Returns true if the provision can be started
+func startProvision() bool {
+	provisionMutex.Lock()
+	defer provisionMutex.Unlock()
+	if isProvisioning || isStopping {
+		return false
+	}
+	isProvisioning = true
+	return true
+}
+
+// Input:
+//
+// Description:
+//
+//	Marks the end of the provision running on this node.
+//
+// Return:
+func endProvision() {
+	provisionMutex.Lock()
+	defer provisionMutex.Unlock()
+	isProvisioning = false
+	runningPhase = ""
+	provisionChanged.Broadcast()
+}
+
+// Input:
+//
+//	phase (ProvisionState): The state entered by the provision
+//
+// Description:
+//
+//	Records the phase of the provision running on this node.
+//
+// Return:
+func notifyPhase(phase ProvisionState) {
+	provisionMutex.Lock()
+	defer provisionMutex.Unlock()
+	if isProvisioning {
+		runningPhase = phase
+		provisionChanged.Broadcast()
+	}
+}
+
+// Input:
+//
+// Description:
+//
+//	Waits until the provision running on this node is completed, or it is waiting for the cluster to rebalance which can be
+//	resumed by another node, and stops any further provision on this node. The wait is bounded by the deadlines of the provision.
+//
+// Return:
+func StopProvisions() {
+	provisionMutex.Lock()
+	defer provisionMutex.Unlock()
+	isStopping = true
+	for isProvisioning && runningPhase != StateScaleUpCompleted && runningPhase != StateScaleDownCompleted {
+		provisionChanged.Wait()
+	}
+}
+
+// Input:
+//
+//	ctx (context.Context): Context of the provision
+//	to (ProvisionState): The state to move to
+//
+// Caller:
+//
+//	Object of type State
+//
+// Description:
+//
+//	Moves the provision to the next state unless it needs to stop, so that a provision which was timed out and abandoned
+//	does not move the state once the call it was stuck in returns.
+//
+// Return:
+//
+//	(error): Returns why the provision needs to stop or the error of the transition
+func (s *State) transitionUnlessStopped(ctx context.Context, to ProvisionState) error {
+	if err := stopCause(ctx); err != nil {
+		return err
+	}
+	return s.Transition(to)
+}
@@ -0,0 +1,72 @@
+package provision
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maplelabs/opensearch-scaling-manager/config"
+)
+
+func TestCheckDeadline(t *testing.T) {
+	now := time.Now()
+	s := State{
+		CurrentState:       StateScaleUpConfigured,
+		StateEnteredTime:   now.Add(-20 * time.Minute).UnixMilli(),
+		ProvisionStartTime: now.Add(-time.Hour).UnixMilli(),
+	}
+	var timeoutErr *TimeoutError
+	if err := s.checkDeadline(config.TimeoutConfig{}, now); !errors.As(err, &timeoutErr) || timeoutErr.Phase != StateScaleUpConfigured || timeoutErr.IsTotal {
+		t.Errorf("expected the phase to be timed out by its default timeout got %v", err)
+	}
+	if err := s.checkDeadline(config.TimeoutConfig{Phases: map[string]int{string(StateScaleUpConfigured): 1800}}, now); err != nil {
+		t.Errorf("expected the configured timeout of the phase to be used got %v", err)
+	}
+	timeoutCfg := config.TimeoutConfig{Total: 1800, Phases: map[string]int{string(StateScaleUpConfigured): 1800}}
+	if err := s.checkDeadline(timeoutCfg, now); !errors.As(err, &timeoutErr) || !timeoutErr.IsTotal {
+		t.Errorf("expected the provision to be timed out got %v", err)
+	}
+	s.CurrentState = StateScaleUpFailed
+	if err := s.checkDeadline(timeoutCfg, now); err != nil {
+		t.Errorf("expected the failed provision not to be timed out got %v", err)
+	}
+	s.CurrentState = StateNormal
+	if err := s.checkDeadline(timeoutCfg, now); err != nil {
+		t.Errorf("expected the state without a provision not to be timed out got %v", err)
+	}
+}
+
+func TestGetStateTimeout(t *testing.T) {
+	timeoutCfg := config.TimeoutConfig{Phases: map[string]int{string(StateScaleUpSpinVm): 600, string(StateScaleDownOnCluster): 0}}
+	tests := []struct {
+		phase   ProvisionState
+		timeout time.Duration
+		ok      bool
+	}{
+		{StateScaleUpSpinVm, 10 * time.Minute, true},
+		{StateScaleUpConfigured, 15 * time.Minute, true},
+		// A timeout of 0 falls back to the default of the state
+		{StateScaleDownOnCluster, 30 * time.Minute, true},
+		{StateNormal, 0, false},
+	}
+	for _, test := range tests {
+		timeout, ok := getStateTimeout(timeoutCfg, test.phase)
+		if timeout != test.timeout || ok != test.ok {
+			t.Errorf("%s: expected the timeout %s (%v) got %s (%v)", test.phase, test.timeout, test.ok, timeout, ok)
+		}
+	}
+}
+
+func TestSleepUnlessStopped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &watchdog{cancel: cancel, err: ErrProvisionAborted}
+	ctx = context.WithValue(ctx, watchdogKey{}, w)
+	if err := sleepUnlessStopped(ctx, time.Millisecond); err != nil {
+		t.Errorf("expected the sleep to complete got %v", err)
+	}
+	cancel()
+	if err := sleepUnlessStopped(ctx, time.Hour); err != ErrProvisionAborted {
+		t.Errorf("expected the cause of the cancellation got %v", err)
+	}
+}
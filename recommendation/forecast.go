@@ -0,0 +1,188 @@
+package recommendation
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/maplelabs/opensearch-scaling-manager/cluster"
+	"github.com/maplelabs/opensearch-scaling-manager/config"
+)
+
+// The minimum interval in seconds of the series used for forecasting.
+const minForecastInterval = 300
+
+// The period in milliseconds of the seasonality of the seasonal model.
+const seasonalPeriod = int64(24 * 60 * 60 * 1000)
+
+// Input:
+//              ctx (context.Context): Request-scoped data that transits processes and APIs.
+//              pollingInterval (int): Time in seconds which is the interval between each metric is pushed into the index.
+//              simFlag (bool): A flag to check if the task needs to collect stats from Opensearch data or simulated data.
+//              r (config.Rule): The rule with stat FORECAST
+//
+// Description:
+//              GetForecast fetches the cluster average of the metric over the last history_days and forecasts the metric
+//              for the next decision_period using the forecast_model of the rule.
+//              This lets the rule recommend a scale up ahead of a predicted breach of the limit.
+//
+// Return:
+//              (cluster.MetricStats, error): Return the statistics of the forecasted values and error if any.
+
+func GetForecast(ctx context.Context, pollingInterval int, simFlag bool, r config.Rule) (cluster.MetricStats, error) {
+	var forecastStats cluster.MetricStats
+	if simFlag {
+		return forecastStats, errors.New("FORECAST stat is not supported with the simulator")
+	}
+
+	interval := minForecastInterval
+	if pollingInterval > interval {
+		interval = pollingInterval
+	}
+	dataPoints, err := cluster.GetClusterHistoricSeries(ctx, r.Metric, r.HistoryDays*24*60, interval)
+	if err != nil {
+		return forecastStats, err
+	}
+	if len(dataPoints) < 2 {
+		return forecastStats, errors.New("Not enough data points")
+	}
+
+	// Forecast for every interval of the decision period after the last data point
+	var futureTimestamps []int64
+	last := dataPoints[len(dataPoints)-1].Timestamp
+	for i := 1; i*interval <= r.DecisionPeriod*60; i++ {
+		futureTimestamps = append(futureTimestamps, last+int64(i*interval)*1000)
+	}
+
+	var forecast []float32
+	switch r.ForecastModel {
+	case "linear":
+		forecast = linearForecast(dataPoints, futureTimestamps)
+	case "seasonal":
+		forecast = seasonalForecast(dataPoints, futureTimestamps, int64(interval)*1000)
+	}
+	if len(forecast) == 0 {
+		return forecastStats, errors.New("Not enough data points")
+	}
+
+	var sum float32
+	forecastStats.Min = forecast[0]
+	forecastStats.Max = forecast[0]
+	for _, value := range forecast {
+		sum += value
+		if value < forecastStats.Min {
+			forecastStats.Min = value
+		}
+		if value > forecastStats.Max {
+			forecastStats.Max = value
+		}
+	}
+	forecastStats.Avg = sum / float32(len(forecast))
+	// The forecast is as good as the history it is calculated from
+	forecastStats.Count = len(dataPoints)
+	log.Debug.Println("Forecast of ", r.Metric, ": ", forecastStats)
+	return forecastStats, nil
+}
+
+// Input:
+//              dataPoints ([]cluster.MetricDataPoint): The history of the metric sorted by time
+//              futureTimestamps ([]int64): The timestamps in milliseconds for which the metric needs to be forecasted
+//
+// Description:
+//              Fits a line over the history using least squares and returns the value of the line at the future timestamps.
+//              Returns nil if the history is not spread over time.
+//
+// Return:
+//              ([]float32): Return the forecasted values.
+
+func linearForecast(dataPoints []cluster.MetricDataPoint, futureTimestamps []int64) []float32 {
+	n := float64(len(dataPoints))
+	if n < 2 {
+		return nil
+	}
+	// Use the time relative to the first data point to keep the sums small
+	origin := dataPoints[0].Timestamp
+	var sumX, sumY, sumXY, sumXX float64
+	for _, dataPoint := range dataPoints {
+		x := float64(dataPoint.Timestamp - origin)
+		y := float64(dataPoint.Value)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return nil
+	}
+	slope := (n*sumXY - sumX*sumY) / denominator
+	intercept := (sumY - slope*sumX) / n
+
+	var forecast []float32
+	for _, timestamp := range futureTimestamps {
+		forecast = append(forecast, float32(intercept+slope*float64(timestamp-origin)))
+	}
+	return forecast
+}
+
+// Input:
+//              dataPoints ([]cluster.MetricDataPoint): The history of the metric sorted by time
+//              futureTimestamps ([]int64): The timestamps in milliseconds for which the metric needs to be forecasted
+//              interval (int64): The interval in milliseconds of the series
+//
+// Description:
+//              Forecasts the metric using a daily seasonal model. The forecast for a timestamp is the average of the values at the
+//              same time of the previous days, adjusted by how much the last hour deviated from the same hour of the previous days.
+//              Returns nil if there is no history for the same time of the previous days.
+//
+// Return:
+//              ([]float32): Return the forecasted values.
+
+func seasonalForecast(dataPoints []cluster.MetricDataPoint, futureTimestamps []int64, interval int64) []float32 {
+	values := make(map[int64]float64)
+	for _, dataPoint := range dataPoints {
+		values[dataPoint.Timestamp/interval] = float64(dataPoint.Value)
+	}
+	first := dataPoints[0].Timestamp
+
+	// Average of the values at the same time of the previous days
+	seasonalValue := func(timestamp int64) (float64, bool) {
+		var sum float64
+		var count int
+		for t := timestamp - seasonalPeriod; t >= first-interval; t -= seasonalPeriod {
+			if value, ok := values[t/interval]; ok {
+				sum += value
+				count++
+			}
+		}
+		if count == 0 {
+			return 0, false
+		}
+		return sum / float64(count), true
+	}
+
+	// Deviation of the last hour from the seasonal values
+	var deviation float64
+	var deviationCount int
+	last := dataPoints[len(dataPoints)-1].Timestamp
+	for _, dataPoint := range dataPoints {
+		if dataPoint.Timestamp < last-60*60*1000 {
+			continue
+		}
+		if value, ok := seasonalValue(dataPoint.Timestamp); ok {
+			deviation += float64(dataPoint.Value) - value
+			deviationCount++
+		}
+	}
+	if deviationCount > 0 {
+		deviation = deviation / float64(deviationCount)
+	}
+
+	var forecast []float32
+	for _, timestamp := range futureTimestamps {
+		if value, ok := seasonalValue(timestamp); ok {
+			forecast = append(forecast, float32(math.Max(value+deviation, 0)))
+		}
+	}
+	return forecast
+}
@@ -0,0 +1,78 @@
+package recommendation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/maplelabs/opensearch-scaling-manager/cluster"
+)
+
+const testHour = int64(60 * 60 * 1000)
+
+// Returns the hourly data points of the days, where values[day][hour] is the value of the hour of the day.
+func newTestSeries(values [][]float32) []cluster.MetricDataPoint {
+	var dataPoints []cluster.MetricDataPoint
+	for day, hours := range values {
+		for hour, value := range hours {
+			dataPoints = append(dataPoints, cluster.MetricDataPoint{Timestamp: (int64(day)*24 + int64(hour)) * testHour, Value: value})
+		}
+	}
+	return dataPoints
+}
+
+// Returns the values of the 24 hours of a day, which are the hour plus the offset unless overridden.
+func newTestDay(offset float32, overrides map[int]float32) []float32 {
+	var hours []float32
+	for hour := 0; hour < 24; hour++ {
+		value := float32(hour) + offset
+		if override, ok := overrides[hour]; ok {
+			value = override
+		}
+		hours = append(hours, value)
+	}
+	return hours
+}
+
+func TestLinearForecast(t *testing.T) {
+	tests := []struct {
+		name       string
+		dataPoints []cluster.MetricDataPoint
+		expected   []float32
+	}{
+		{"rising", []cluster.MetricDataPoint{{Timestamp: 0, Value: 10}, {Timestamp: 60000, Value: 20}, {Timestamp: 120000, Value: 30}}, []float32{40, 50}},
+		{"flat", []cluster.MetricDataPoint{{Timestamp: 0, Value: 25}, {Timestamp: 60000, Value: 25}, {Timestamp: 120000, Value: 25}}, []float32{25, 25}},
+		{"falling", []cluster.MetricDataPoint{{Timestamp: 0, Value: 30}, {Timestamp: 60000, Value: 20}}, []float32{0, -10}},
+		{"least squares", []cluster.MetricDataPoint{{Timestamp: 0, Value: 10}, {Timestamp: 60000, Value: 30}, {Timestamp: 120000, Value: 20}}, []float32{30, 35}},
+		{"single data point", []cluster.MetricDataPoint{{Timestamp: 0, Value: 10}}, nil},
+		{"not spread over time", []cluster.MetricDataPoint{{Timestamp: 60000, Value: 10}, {Timestamp: 60000, Value: 30}}, nil},
+	}
+	for _, test := range tests {
+		forecast := linearForecast(test.dataPoints, []int64{180000, 240000})
+		if !reflect.DeepEqual(forecast, test.expected) {
+			t.Errorf("%s: expected %v got %v", test.name, test.expected, forecast)
+		}
+	}
+}
+
+func TestSeasonalForecast(t *testing.T) {
+	nextDay := 2 * 24 * testHour
+	tests := []struct {
+		name       string
+		dataPoints []cluster.MetricDataPoint
+		expected   []float32
+	}{
+		// The first two hours of the next day are the average of the previous days, 0.5 and 1.5, plus the deviation of the last hour, 5
+		{"average of the previous days with the deviation", newTestSeries([][]float32{newTestDay(0, nil), newTestDay(1, map[int]float32{22: 27, 23: 28})}), []float32{5.5, 6.5}},
+		{"same as the previous days", newTestSeries([][]float32{newTestDay(0, nil), newTestDay(0, nil)}), []float32{0, 1}},
+		// The average of the previous days, 11, with the deviation of the last hour, -42.5, is not below zero
+		{"not below zero", newTestSeries([][]float32{newTestDay(20, map[int]float32{0: 20, 1: 20}), newTestDay(2, map[int]float32{0: 2, 1: 2, 22: 0, 23: 0})}), []float32{0, 0}},
+		// Only the hours 2 to 11 of the first day
+		{"no history of the same time", newTestSeries([][]float32{newTestDay(0, nil)})[2:12], nil},
+	}
+	for _, test := range tests {
+		forecast := seasonalForecast(test.dataPoints, []int64{nextDay, nextDay + testHour}, testHour)
+		if !reflect.DeepEqual(forecast, test.expected) {
+			t.Errorf("%s: expected %v got %v", test.name, test.expected, forecast)
+		}
+	}
+}
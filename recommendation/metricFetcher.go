@@ -0,0 +1,202 @@
+package recommendation
+
+import (
+	"context"
+	"regexp"
+	"sync"
+
+	"github.com/maplelabs/opensearch-scaling-manager/config"
+)
+
+// The maximum number of metrics fetched from Opensearch at the same time in an evaluation cycle.
+const maxConcurrentFetches = 8
+
+// This struct identifies the query made by GetMetrics for a rule, so that the rules which need the same query share its result.
+// The stats calculated from the same aggregation (AVG, MAX, MIN, P90, P95, P99, TREND, RATE) share the key,
+// and the limit and the operation are part of the key only for the stats which depend on them (COUNT, TERM).
+type metricKey struct {
+	Metric         string
+	Stat           string
+	Scope          string
+	DecisionPeriod int
+	Limit          float32
+	TaskOperation  string
+	ForecastModel  string
+	HistoryDays    int
+}
+
+// This struct contains the outcome of GetMetrics for a metricKey.
+type metricResult struct {
+	clusterMetric []byte
+	err           error
+}
+
+// This struct contains the metrics fetched for all the rules of an evaluation cycle along with the context of the cycle.
+type MetricCache struct {
+	ctx     context.Context
+	metrics map[metricKey]metricResult
+}
+
+// Input:
+//              r (config.Rule): The rule for which the metrics are fetched.
+//              taskOperation (string); Recommended operation
+//
+// Description:
+//              Returns the key which identifies the query made by GetMetrics for the rule.
+//
+// Return:
+//              (metricKey): Return the key of the rule.
+
+func getMetricKey(r config.Rule, taskOperation string) metricKey {
+	key := metricKey{
+		Metric:         r.Metric,
+		Stat:           "STATS",
+		DecisionPeriod: r.DecisionPeriod,
+	}
+	switch r.Scope {
+	case "any_node", "all_nodes":
+		key.Scope = "nodes"
+	case "data_nodes":
+		key.Scope = "data_nodes"
+	}
+	switch {
+	case r.Stat == "COUNT" || key.Scope == "" && r.Stat == "TERM":
+		key.Stat = r.Stat
+		key.Limit = r.Limit
+		key.TaskOperation = taskOperation
+	case key.Scope == "" && r.Stat == "FORECAST":
+		key.Stat = r.Stat
+		key.ForecastModel = r.ForecastModel
+		key.HistoryDays = r.HistoryDays
+	}
+	return key
+}
+
+// Input:
+//              group (config.RuleGroup): The rules and nested groups of a task.
+//              taskOperation (string); Operation of the task
+//              rules (map[metricKey]config.Rule): The rules to be fetched, keyed by the query they need.
+//
+// Description:
+//              Adds the rules of the group and its nested groups to the rules to be fetched.
+//
+// Return:
+
+func collectRules(group config.RuleGroup, taskOperation string, rules map[metricKey]config.Rule) {
+	for _, r := range group.Rules {
+		rules[getMetricKey(r, taskOperation)] = r
+	}
+	for _, subGroup := range group.Groups {
+		collectRules(subGroup, taskOperation, rules)
+	}
+}
+
+// Input:
+//              ctx (context.Context): The context of the evaluation cycle, which carries the deadline of the cycle.
+//              pollingInterval (int): Time in seconds which is the interval between each metric is pushed into the index.
+//              simFlag (bool): A flag to check if the task needs to collect stats from Opensearch data or simulated data.
+//              tasks ([]config.Task): The metric based and target_tracking tasks to be evaluated in the cycle.
+//
+// Description:
+//              FetchMetrics fetches the metrics of all the rules of all the tasks concurrently using a pool of maxConcurrentFetches workers.
+//              The rules which need the same query are fetched once. The metrics of every rule are fetched, even if the evaluation
+//              of the task is short-circuited later, so the AND/OR semantics of the evaluation do not change.
+//              A fetch which does not complete before the deadline of the context fails with the error of the context.
+//
+// Return:
+//              (*MetricCache): Return the metrics fetched for every query.
+
+func FetchMetrics(ctx context.Context, pollingInterval int, simFlag, isAccelerated bool, tasks []config.Task) *MetricCache {
+	return fetchMetrics(ctx, tasks, func(ctx context.Context, r config.Rule, taskOperation string) ([]byte, error) {
+		return GetMetrics(ctx, pollingInterval, simFlag, isAccelerated, r, taskOperation)
+	})
+}
+
+// Input:
+//              ctx (context.Context): The context of the evaluation cycle, which carries the deadline of the cycle.
+//              tasks ([]config.Task): The metric based and target_tracking tasks to be evaluated in the cycle.
+//              fetch (func(context.Context, config.Rule, string) ([]byte, error)): Fetches the metrics of a rule for the operation of its task.
+//
+// Description:
+//              Fetches the metrics of the rules of the tasks using fetch, as described in FetchMetrics.
+//
+// Return:
+//              (*MetricCache): Return the metrics fetched for every query.
+
+func fetchMetrics(ctx context.Context, tasks []config.Task, fetch func(ctx context.Context, r config.Rule, taskOperation string) ([]byte, error)) *MetricCache {
+	scaleRegex := regexp.MustCompile(`(scale_up|scale_down)_by_([0-9]+)`)
+	rules := make(map[metricKey]config.Rule)
+	for _, task := range tasks {
+		if task.TaskName == "target_tracking" {
+			for _, r := range task.Rules {
+				r.Stat = "AVG"
+				rules[getMetricKey(r, task.TaskName)] = r
+			}
+			continue
+		}
+		subMatch := scaleRegex.FindStringSubmatch(task.TaskName)
+		if subMatch == nil {
+			continue
+		}
+		collectRules(config.RuleGroup{Rules: task.Rules, Groups: task.Groups}, subMatch[1], rules)
+	}
+
+	metricCache := &MetricCache{
+		ctx:     ctx,
+		metrics: make(map[metricKey]metricResult),
+	}
+	keys := make(chan metricKey)
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < maxConcurrentFetches && i < len(rules); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range keys {
+				var result metricResult
+				if err := ctx.Err(); err != nil {
+					result.err = err
+				} else {
+					result.clusterMetric, result.err = fetch(ctx, rules[key], key.TaskOperation)
+				}
+				mutex.Lock()
+				metricCache.metrics[key] = result
+				mutex.Unlock()
+			}
+		}()
+	}
+	for key := range rules {
+		keys <- key
+	}
+	close(keys)
+	wg.Wait()
+	log.Debug.Println("Fetched the metrics of ", len(rules), " queries for the evaluation cycle")
+	return metricCache
+}
+
+// Input:
+//              pollingInterval (int): Time in seconds which is the interval between each metric is pushed into the index.
+//              simFlag (bool): A flag to check if the task needs to collect stats from Opensearch data or simulated data.
+//              r (config.Rule): The rule for which the metrics are needed.
+//              taskOperation (string); Recommended operation
+//
+// Caller:
+//              Object of MetricCache
+//
+// Description:
+//              Returns the metrics of the rule fetched by FetchMetrics.
+//              If the metrics of the rule were not fetched, or there is no cache, the metrics are fetched using GetMetrics.
+//
+// Return:
+//              ([]byte, error): Return marshal form of the metrics as returned by GetMetrics and error if any
+
+func (c *MetricCache) getMetrics(pollingInterval int, simFlag, isAccelerated bool, r config.Rule, taskOperation string) ([]byte, error) {
+	if c == nil {
+		return GetMetrics(ctx, pollingInterval, simFlag, isAccelerated, r, taskOperation)
+	}
+	key := getMetricKey(r, taskOperation)
+	if result, ok := c.metrics[key]; ok {
+		return result.clusterMetric, result.err
+	}
+	return GetMetrics(c.ctx, pollingInterval, simFlag, isAccelerated, r, taskOperation)
+}
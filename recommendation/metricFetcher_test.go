@@ -0,0 +1,91 @@
+package recommendation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maplelabs/opensearch-scaling-manager/config"
+)
+
+func TestFetchMetricsOncePerQuery(t *testing.T) {
+	tasks := []config.Task{
+		{TaskName: "scale_up_by_1", Operator: "OR", Rules: []config.Rule{
+			{Metric: "CpuUtil", Limit: 80, Stat: "AVG", DecisionPeriod: 60},
+			{Metric: "CpuUtil", Limit: 90, Stat: "MAX", DecisionPeriod: 60},
+			{Metric: "RamUtil", Limit: 80, Stat: "COUNT", DecisionPeriod: 60, Occurrences: 50},
+		}},
+		{TaskName: "scale_up_by_2", Operator: "AND", Groups: []config.RuleGroup{{Operator: "OR", Rules: []config.Rule{
+			{Metric: "CpuUtil", Limit: 95, Stat: "P99", DecisionPeriod: 60},
+			{Metric: "RamUtil", Limit: 80, Stat: "COUNT", DecisionPeriod: 60, Occurrences: 90},
+		}}}},
+		{TaskName: "scale_down_by_1", Operator: "AND", Rules: []config.Rule{
+			{Metric: "CpuUtil", Limit: 20, Stat: "AVG", DecisionPeriod: 60},
+			{Metric: "RamUtil", Limit: 80, Stat: "COUNT", DecisionPeriod: 60, Occurrences: 50},
+		}},
+		{TaskName: "target_tracking", Rules: []config.Rule{{Metric: "CpuUtil", TargetValue: 60, DecisionPeriod: 60}}},
+	}
+
+	var mutex sync.Mutex
+	fetches := make(map[string]int)
+	metrics := fetchMetrics(context.Background(), tasks, func(ctx context.Context, r config.Rule, taskOperation string) ([]byte, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		fetches[fmt.Sprintf("%v", getMetricKey(r, taskOperation))]++
+		return []byte(`{}`), nil
+	})
+
+	// The CpuUtil stats of all the tasks share a query, while the RamUtil count differs by the operation
+	if len(fetches) != 3 || len(metrics.metrics) != 3 {
+		t.Errorf("expected 3 queries got %d fetched and %d cached: %v", len(fetches), len(metrics.metrics), fetches)
+	}
+	for key, count := range fetches {
+		if count != 1 {
+			t.Errorf("expected the query %s to be fetched once got %d", key, count)
+		}
+	}
+}
+
+func TestFetchMetricsDeadline(t *testing.T) {
+	var task = config.Task{TaskName: "scale_up_by_1", Operator: "OR"}
+	for i := 0; i < 3*maxConcurrentFetches; i++ {
+		task.Rules = append(task.Rules, config.Rule{Metric: "CpuUtil", Stat: "AVG", DecisionPeriod: 60 + i})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var mutex sync.Mutex
+	var fetches int
+	start := time.Now()
+	metrics := fetchMetrics(ctx, []config.Task{task}, func(ctx context.Context, r config.Rule, taskOperation string) ([]byte, error) {
+		mutex.Lock()
+		fetches++
+		mutex.Unlock()
+		// The fetch does not complete before the deadline
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Minute):
+			return []byte(`{}`), nil
+		}
+	})
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the fetches to be cancelled at the deadline got %v", elapsed)
+	}
+	// Only the fetches in progress at the deadline are made, the queries still queued fail without being fetched
+	if fetches > maxConcurrentFetches {
+		t.Errorf("expected at most %d fetches got %d", maxConcurrentFetches, fetches)
+	}
+	if len(metrics.metrics) != len(task.Rules) {
+		t.Errorf("expected a result for each of the %d queries got %d", len(task.Rules), len(metrics.metrics))
+	}
+	for key, result := range metrics.metrics {
+		if !errors.Is(result.err, context.DeadlineExceeded) {
+			t.Errorf("expected the query %v to fail with the deadline got %v", key, result.err)
+		}
+	}
+}
@@ -10,8 +10,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	cron "github.com/robfig/cron/v3"
@@ -29,6 +32,14 @@ var ctx = context.Background()
 // A global variable to keep track of cronJob details
 var cronJobList []*cron.Cron
 
+// The EVENT tasks scheduled in cronJobList and the config with which they are provisioned, guarded by cronMutex.
+var (
+	cronMutex      sync.Mutex
+	cronTasks      []config.Task
+	cronClusterCfg config.ClusterDetails
+	cronUserCfg    config.UserConfig
+)
+
 // Input:
 //
 // Description:
@@ -44,6 +55,7 @@ func init() {
 // Inputs:
 //              simFlag (bool): A flag to check if the task needs to be evaluated from Opensearch data or simulated data.
 //              pollingInterval (int): Time in seconds which is the interval between each metric is pushed into the index.
+//              clusterCfg (config.ClusterDetails): Cluster Level config details
 //
 // Caller:
 //              Object of TaskDetails
@@ -51,50 +63,146 @@ func init() {
 // Description:
 //              EvaluateTask will go through all the tasks one by one. and
 //              It check if the task are meeting the criteria based on rules and operator.
-//              If the task is meeting the criteria then it will push the task to recommendation queue.
+//              The target_tracking task is converted to the scale_up_by_N or scale_down_by_N task it recommends.
+//              A task meeting the criteria is recommended only if it has been meeting the criteria for its stabilization window
+//              and it is not in the cooldown of the last scale up or scale down.
+//              If the task is recommended then it will push the task to recommendation queue.
+//              The report of every task and rule evaluated is indexed to Opensearch using PushReportToOs.
+//              The metrics of all the rules are fetched concurrently using FetchMetrics before the tasks are evaluated.
+//              The fetches need to complete within the polling interval, so that a cycle does not run into the next one.
 //
 // Return:
 //              ([]map[string]string): Returns an array of the recommendations.
 
-func EvaluateTask(pollingInterval int, simFlag, isAccelerated bool, t *config.TaskDetails) []map[string]string {
+func EvaluateTask(pollingInterval int, simFlag, isAccelerated bool, t *config.TaskDetails, clusterCfg config.ClusterDetails) []map[string]string {
 	var recommendationArray []map[string]string
 	var isRecommendedTask bool
+	var recommendedTasks []config.Task
+	var configTaskNames []string
+	var recommendations []map[string]string
+	var isRecommendedMap = make(map[string]bool)
+	var taskReports []TaskReport
+	var recommendedTaskReports []int
+
+	cycleCtx, cancel := context.WithTimeout(ctx, time.Duration(pollingInterval)*time.Second)
+	defer cancel()
+	metrics := FetchMetrics(cycleCtx, pollingInterval, simFlag, isAccelerated, t.Tasks)
+
 	for _, v := range t.Tasks {
 		var rulesResponsibleMap = make(map[string]string)
-		isRecommendedTask, rulesResponsibleMap[v.TaskName] = GetNextTask(pollingInterval, simFlag, isAccelerated, v)
-		log.Debug.Println(rulesResponsibleMap)
-		if isRecommendedTask {
-			PushToRecommendationQueue(v)
-			recommendationArray = append(recommendationArray, rulesResponsibleMap)
+		taskReport := TaskReport{TaskName: v.TaskName, Operator: v.Operator, Result: "not_met"}
+		taskName := v.TaskName
+		if v.TaskName == "target_tracking" {
+			var rulesResponsible string
+			taskName, rulesResponsible = GetTargetTrackingTask(pollingInterval, simFlag, isAccelerated, v, clusterCfg, &taskReport, metrics)
+			isRecommendedTask = taskName != ""
+			rulesResponsibleMap[taskName] = rulesResponsible
+			if !isRecommendedTask {
+				log.Debug.Println("The target_tracking task is not recommended as the cluster is at the target")
+			}
 		} else {
-			log.Debug.Println(fmt.Sprintf("The %s task is not recommended as rules are not satisfied", v.TaskName))
+			isRecommendedTask, rulesResponsibleMap[v.TaskName] = GetNextTask(pollingInterval, simFlag, isAccelerated, v, &taskReport, metrics)
+			log.Debug.Println(rulesResponsibleMap)
+			if !isRecommendedTask {
+				log.Debug.Println(fmt.Sprintf("The %s task is not recommended as rules are not satisfied", v.TaskName))
+			}
+		}
+		// The stabilization is tracked for the task in the config, as target_tracking recommends a different task every time
+		isRecommendedMap[v.TaskName] = isRecommendedTask
+		taskReports = append(taskReports, taskReport)
+		if isRecommendedTask {
+			taskReports[len(taskReports)-1].RecommendedTask = taskName
+			taskReports[len(taskReports)-1].RulesResponsible = rulesResponsibleMap[taskName]
+			recommendedTaskReports = append(recommendedTaskReports, len(taskReports)-1)
+			recommendedTask := v
+			recommendedTask.TaskName = taskName
+			recommendedTasks = append(recommendedTasks, recommendedTask)
+			configTaskNames = append(configTaskNames, v.TaskName)
+			recommendations = append(recommendations, rulesResponsibleMap)
 		}
 	}
+
+	recommendedSince := provision.UpdateRecommendedSince(isRecommendedMap)
+	for i, task := range recommendedTasks {
+		stabilizationWindow := time.Duration(task.StabilizationWindow) * time.Minute
+		if stableFor := time.Since(recommendedSince[configTaskNames[i]]); stableFor < stabilizationWindow {
+			log.Info.Println(fmt.Sprintf("The %s task is not recommended as it has been recommended for %s, which is less than the stabilization window of %s", task.TaskName, stableFor.Round(time.Second), stabilizationWindow))
+			taskReports[recommendedTaskReports[i]].Result = "stabilizing"
+			continue
+		}
+		cooldownAfterScaleUp, cooldownAfterScaleDown := getCooldown(task)
+		if provision.IsInCooldown(task.TaskName, cooldownAfterScaleUp, cooldownAfterScaleDown) {
+			taskReports[recommendedTaskReports[i]].Result = "cooldown"
+			continue
+		}
+		taskReports[recommendedTaskReports[i]].Result = "recommended"
+		PushToRecommendationQueue(task, recommendations[i][task.TaskName])
+		recommendationArray = append(recommendationArray, recommendations[i])
+	}
+	PushReportToOs(taskReports)
 	return recommendationArray
 }
 
+// Inputs:
+//              task (config.Task): The task for which the cooldown is needed.
+//
+// Description:
+//              Returns the cooldown after scale up and scale down of the task.
+//              If neither of the cooldowns is specified, the largest decision period of the rules of the task is used for both the cooldowns,
+//              so that a provision does not take place based on the metrics collected before the previous provision.
+//
+// Return:
+//              (time.Duration, time.Duration): Returns the cooldown after scale up and the cooldown after scale down.
+
+func getCooldown(task config.Task) (time.Duration, time.Duration) {
+	if task.CooldownAfterScaleUp > 0 || task.CooldownAfterScaleDown > 0 {
+		return time.Duration(task.CooldownAfterScaleUp) * time.Minute, time.Duration(task.CooldownAfterScaleDown) * time.Minute
+	}
+	largestDecisionPeriod := getLargestDecisionPeriod(config.RuleGroup{Rules: task.Rules, Groups: task.Groups})
+	return time.Duration(largestDecisionPeriod) * time.Minute, time.Duration(largestDecisionPeriod) * time.Minute
+}
+
+// Inputs:
+//              group (config.RuleGroup): The rules and nested groups.
+//
+// Description:
+//              Returns the largest decision period among the rules of the group and its nested groups.
+//
+// Return:
+//              (int): Returns the largest decision period in minutes.
+
+func getLargestDecisionPeriod(group config.RuleGroup) int {
+	var largestDecisionPeriod int
+	for _, rule := range group.Rules {
+		if rule.DecisionPeriod > largestDecisionPeriod {
+			largestDecisionPeriod = rule.DecisionPeriod
+		}
+	}
+	for _, subGroup := range group.Groups {
+		if decisionPeriod := getLargestDecisionPeriod(subGroup); decisionPeriod > largestDecisionPeriod {
+			largestDecisionPeriod = decisionPeriod
+		}
+	}
+	return largestDecisionPeriod
+}
+
 // Inputs:
 //              simFlag (bool): A flag to check if the task needs to collect stats from Opensearch data or simulated data.
 //              pollingInterval (int): Time in seconds which is the interval between each metric is pushed into the index.
+//              report (*TaskReport): The report of the task to which the report of every rule is added.
+//              metrics (*MetricCache): The metrics fetched for the evaluation cycle.
 //
 // Caller: Object of Task
 // Description:
 //
-//              GetNextTask will get the Task and iterate through all the rules inside the task.
-//              Based on the operator it will check if it should iterate through all the rules or not.
-//              It will call GetNextRule while iterating through the rules.
+//              GetNextTask will get the Task and evaluate the rules and the nested rule groups of the task using EvaluateRuleGroup.
 //              Based on the result GetNextTask will check if a task can be recommended or not.
 //
 // Return:
 //
 //              (bool, string): Return if a task can be recommended or not(bool) and string which says the rules responsible for that recommendation.
 
-func GetNextTask(pollingInterval int, simFlag, isAccelerated bool, t config.Task) (bool, string) {
-	var isRecommendedTask bool = true
-	var isRecommendedRule bool
-	var rulesResponsible string
-	var err error
-
+func GetNextTask(pollingInterval int, simFlag, isAccelerated bool, t config.Task, report *TaskReport, metrics *MetricCache) (bool, string) {
 	scaleRegexString := `(scale_up|scale_down)_by_([0-9]+)`
 	scaleRegex := regexp.MustCompile(scaleRegexString)
 
@@ -102,66 +210,159 @@ func GetNextTask(pollingInterval int, simFlag, isAccelerated bool, t config.Task
 
 	taskOperation := subMatch[1]
 
-	var rules []string
-	for _, v := range t.Rules {
-		// Here we can add go routine.
-		// So that all the rules getMetrics will be fetched in concurrent way
-		// There is a possibility that each rule is taking time.
-		// What if in the case of AND the non matching rule is present at the last.
-		// What if in the case of OR the matching rule is present at the last.
-		isRecommendedRule, err = GetNextRule(taskOperation, pollingInterval, simFlag, isAccelerated, v)
+	isRecommendedTask, rules := EvaluateRuleGroup(taskOperation, pollingInterval, simFlag, isAccelerated, config.RuleGroup{
+		Operator: t.Operator,
+		Rules:    t.Rules,
+		Groups:   t.Groups,
+	}, report, metrics)
+	return isRecommendedTask, strings.Join(rules, "_and_")
+}
+
+// Inputs:
+//              taskOperation (string); Recommended operation
+//              pollingInterval (int): Time in seconds which is the interval between each metric is pushed into the index.
+//              simFlag (bool): A flag to check if the task needs to collect stats from Opensearch data or simulated data.
+//              group (config.RuleGroup): The rules and the nested groups to be evaluated along with the operator.
+//              report (*TaskReport): The report of the task to which the report of every rule is added.
+//              metrics (*MetricCache): The metrics fetched for the evaluation cycle.
+//
+// Description:
+//
+//              EvaluateRuleGroup evaluates the rules and then the nested groups of the group recursively.
+//              Based on the operator it will check if it should iterate through all the rules and groups or not.
+//              With AND all the rules and groups need to be met and with OR the first rule or group met is enough.
+//              The sub-tree that fired is hence always a conjunction of the rules which are returned as the rules responsible.
+//              The rules which are not evaluated as the outcome is already decided are added to the report as skipped.
+//
+// Return:
+//
+//              (bool, []string): Return if the group is met or not(bool) and the rules responsible of the sub-tree that fired.
+
+func EvaluateRuleGroup(taskOperation string, pollingInterval int, simFlag, isAccelerated bool, group config.RuleGroup, report *TaskReport, metrics *MetricCache) (bool, []string) {
+	var rulesResponsible []string
+	isRecommended := group.Operator == "AND"
+
+	for i, v := range group.Rules {
+		// The metrics of the rules are fetched concurrently by FetchMetrics before the evaluation,
+		// so the rules are evaluated in order to keep the rules responsible of the sub-tree that fired.
+		ruleReport := newRuleReport(v)
+		isRecommendedRule, nodeIps, err := GetNextRule(taskOperation, pollingInterval, simFlag, isAccelerated, v, &ruleReport, metrics)
 		if err != nil {
 			log.Warn.Println(fmt.Sprintf("%s for the rule: %v", err, v))
 		}
+		report.Rules = append(report.Rules, ruleReport)
+		if group.Operator == "OR" && isRecommendedRule {
+			addSkippedRules(config.RuleGroup{Rules: group.Rules[i+1:], Groups: group.Groups}, report)
+			return true, []string{getRuleResponsible(v, nodeIps)}
+		} else if group.Operator == "AND" && !isRecommendedRule {
+			addSkippedRules(config.RuleGroup{Rules: group.Rules[i+1:], Groups: group.Groups}, report)
+			return false, nil
+		}
 		if isRecommendedRule {
-			if v.Stat == "AVG" {
-				rules = append(rules, fmt.Sprintf("%s-%s-%f-%d", v.Metric, v.Stat, v.Limit, v.DecisionPeriod))
-			} else {
-				rules = append(rules, fmt.Sprintf("%s-%s-%f-%d-%d", v.Metric, v.Stat, v.Limit, v.Occurrences, v.DecisionPeriod))
-			}
-
+			rulesResponsible = append(rulesResponsible, getRuleResponsible(v, nodeIps))
 		}
-		if t.Operator == "OR" && isRecommendedRule ||
-			t.Operator == "AND" && !isRecommendedRule {
-			break
+	}
+
+	for i, subGroup := range group.Groups {
+		isRecommendedGroup, subGroupRules := EvaluateRuleGroup(taskOperation, pollingInterval, simFlag, isAccelerated, subGroup, report, metrics)
+		log.Debug.Println(fmt.Sprintf("Rule group %v met: %t", subGroup, isRecommendedGroup))
+		if group.Operator == "OR" && isRecommendedGroup {
+			addSkippedRules(config.RuleGroup{Groups: group.Groups[i+1:]}, report)
+			return true, subGroupRules
+		} else if group.Operator == "AND" && !isRecommendedGroup {
+			addSkippedRules(config.RuleGroup{Groups: group.Groups[i+1:]}, report)
+			return false, nil
 		}
+		rulesResponsible = append(rulesResponsible, subGroupRules...)
 	}
-	isRecommendedTask = isRecommendedRule
-	if len(rules) > 1 {
-		rulesResponsible = strings.Join(rules, "_and_")
-	} else if len(rules) == 1 {
-		rulesResponsible = rules[0]
+
+	// All the rules and groups are met with AND and none of them is met with OR
+	return isRecommended, rulesResponsible
+}
+
+// Inputs:
+//              r (config.Rule): The rule which is met.
+//              nodeIps ([]string): The IPs of the nodes on which the rule is met, if the scope of the rule is not cluster.
+//
+// Description:
+//
+//              Returns the string which describes the rule responsible for a recommendation.
+//
+// Return:
+//
+//              (string): Return the rule responsible.
+
+func getRuleResponsible(r config.Rule, nodeIps []string) string {
+	var rule string
+	if r.Stat != "COUNT" && r.Stat != "TERM" {
+		rule = fmt.Sprintf("%s-%s-%f", r.Metric, r.Stat, r.Limit)
+	} else {
+		rule = fmt.Sprintf("%s-%s-%f-%d", r.Metric, r.Stat, r.Limit, r.Occurrences)
+	}
+	if isNodeScope(r.Scope) {
+		rule = fmt.Sprintf("%s-%s(%s)", rule, r.Scope, strings.Join(nodeIps, ","))
 	}
-	return isRecommendedTask, rulesResponsible
+	return fmt.Sprintf("%s-%d", rule, r.DecisionPeriod)
 }
 
 // Input:
 //              taskOperation (string); Recommended operation
 //              simFlag (bool): A flag to check if the task needs to collect stats from Opensearch data or simulated data.
 //              pollingInterval (int): Time in seconds which is the interval between each metric is pushed into the index.
+//              ruleReport (*RuleReport): The report of the rule which is populated with the values the rule is evaluated on.
+//              metrics (*MetricCache): The metrics fetched for the evaluation cycle.
 //
 // Caller:
 //              Object of Rule
 //
 // Description:
-//              GetNextRule will fetch the metrics based on the rules MetricName and Stats using GetMetrics
+//              GetNextRule will get the metrics based on the rules MetricName and Stats from the metrics fetched for the cycle
 //              Then it will evaluate if the rule is meeting the criteria or not using EvaluateRule
+//              If the scope of the rule is not cluster, the rule is evaluated on every node using EvaluateNodeRule
 //
 // Return:
-//              (bool, error): Return if a rule is meeting the criteria or not(bool) and error if any
+//              (bool, []string, error): Return if a rule is meeting the criteria or not(bool), the IPs of the nodes responsible and error if any
 
-func GetNextRule(taskOperation string, pollingInterval int, simFlag, isAccelerated bool, r config.Rule) (bool, error) {
-	cluster, err := GetMetrics(pollingInterval, simFlag, isAccelerated, r, taskOperation)
+func GetNextRule(taskOperation string, pollingInterval int, simFlag, isAccelerated bool, r config.Rule, ruleReport *RuleReport, metrics *MetricCache) (bool, []string, error) {
+	var isRecommended bool
+	var nodeIps []string
+	cluster, err := metrics.getMetrics(pollingInterval, simFlag, isAccelerated, r, taskOperation)
 	if err != nil {
-		return false, err
+		ruleReport.Result = "error"
+		ruleReport.Error = err.Error()
+		return false, nodeIps, err
+	}
+	if isNodeScope(r.Scope) {
+		isRecommended, nodeIps = EvaluateNodeRule(cluster, taskOperation, pollingInterval, r)
+		fillNodeRuleReport(ruleReport, cluster, taskOperation, pollingInterval, r, nodeIps)
+	} else {
+		isRecommended = EvaluateRule(cluster, taskOperation, pollingInterval, r)
+		fillRuleReport(ruleReport, cluster, taskOperation, pollingInterval, r)
+	}
+	ruleReport.Result = "fail"
+	if isRecommended {
+		ruleReport.Result = "pass"
 	}
-	isRecommended := EvaluateRule(cluster, taskOperation, pollingInterval, r)
 	log.Debug.Println(r)
 	log.Debug.Println(isRecommended)
-	return isRecommended, nil
+	return isRecommended, nodeIps, nil
+}
+
+// Input:
+//              scope (string): The scope of the rule.
+//
+// Description:
+//              Checks if the rule needs to be evaluated on every node instead of the whole cluster.
+//
+// Return:
+//              (bool): Return true if the rule needs to be evaluated on every node.
+
+func isNodeScope(scope string) bool {
+	return scope == "any_node" || scope == "all_nodes" || scope == "data_nodes"
 }
 
 // Input:
+//              ctx (context.Context): Request-scoped data that transits processes and APIs.
 //              simFlag (bool): A flag to check if the task needs to collect stats from Opensearch data or simulated data.
 //              pollingInterval (int): Time in seconds which is the interval between each metric is pushed into the index.
 //              taskOperation (string); Recommended operation
@@ -171,14 +372,17 @@ func GetNextRule(taskOperation string, pollingInterval int, simFlag, isAccelerat
 //
 // Description:
 //              GetMetrics will be getting the metrics for a metricName based on its stats
-//              If the stat is Avg then it will call GetClusterAvg which will provide MetricViolatedCountCluster struct.
+//              If the stat is Avg, Max, Min, P90, P95, P99, Trend or Rate then it will call GetClusterAvg which will provide MetricStats struct.
+//              If the stat is Forecast then it will call GetForecast which will provide MetricStats struct of the forecasted values.
 //              If the stat is Count or Term then it will call GetClusterCount which will provide MetricViolatedCountCluster struct.
+//              If the scope of the rule is not cluster then it will call GetNodeStats or GetNodeCount which will provide
+//              the list of MetricStatsNode or MetricViolatedCountNode structs.
 //              At last it marshal the structure such that uniform data can be used across multiple methods.
 //
 // Return:
 //              ([]byte, error): Return marshal form of either MetricStatsCluster or MetricViolatedCountCluster struct([]byte) and error if any
 
-func GetMetrics(pollingInterval int, simFlag, isAccelerated bool, r config.Rule, taskOperation string) ([]byte, error) {
+func GetMetrics(ctx context.Context, pollingInterval int, simFlag, isAccelerated bool, r config.Rule, taskOperation string) ([]byte, error) {
 	var clusterStats cluster.MetricStats
 	var clusterCount cluster.MetricViolatedCount
 	var clusterMetric []byte
@@ -186,8 +390,32 @@ func GetMetrics(pollingInterval int, simFlag, isAccelerated bool, r config.Rule,
 	var err error
 	var invalidDatapoints bool
 
-	if r.Stat == "AVG" {
+	if isNodeScope(r.Scope) {
+		var nodeMetrics interface{}
 		if simFlag {
+			return clusterMetric, errors.New("Node level scope is not supported with the simulator")
+		} else if r.Stat == "COUNT" {
+			nodeMetrics, invalidDatapoints, err = cluster.GetNodeCount(ctx, r.Metric, r.DecisionPeriod, pollingInterval, r.Limit, taskOperation, r.Scope == "data_nodes")
+		} else {
+			nodeMetrics, invalidDatapoints, err = cluster.GetNodeStats(ctx, r.Metric, r.DecisionPeriod, pollingInterval, r.Scope == "data_nodes")
+		}
+
+		if err != nil || invalidDatapoints {
+			if invalidDatapoints {
+				err = errors.New("Not enough data points")
+			}
+			return clusterMetric, err
+		}
+		clusterMetric, jsonErr = json.MarshalIndent(nodeMetrics, "", "\t")
+		log.Debug.Println(nodeMetrics)
+		if jsonErr != nil {
+			log.Panic.Println("Error converting struct to json: ", jsonErr)
+			panic(jsonErr)
+		}
+	} else if r.Stat != "COUNT" && r.Stat != "TERM" {
+		if r.Stat == "FORECAST" {
+			clusterStats, err = GetForecast(ctx, pollingInterval, simFlag, r)
+		} else if simFlag {
 			clusterStats, err = cluster_sim.GetClusterAvg(r.Metric, r.DecisionPeriod, isAccelerated)
 		} else {
 			clusterStats, invalidDatapoints, err = cluster.GetClusterAvg(ctx, r.Metric, r.DecisionPeriod, pollingInterval)
@@ -247,20 +475,54 @@ func GetMetrics(pollingInterval int, simFlag, isAccelerated bool, r config.Rule,
 
 func EvaluateRule(clusterMetric []byte, taskOperation string, pollingInterval int, r config.Rule) bool {
 	log.Debug.Println(taskOperation)
-	if r.Stat == "AVG" {
+	if r.Stat == "TREND" || r.Stat == "RATE" {
 		var clusterStats cluster.MetricStats
 		err := json.Unmarshal(clusterMetric, &clusterStats)
 		if err != nil {
 			log.Panic.Println("Error converting struct to json: ", err)
 			panic(err)
 		}
-		if taskOperation == "scale_up" && clusterStats.Avg > r.Limit ||
-			taskOperation == "scale_down" && clusterStats.Avg < r.Limit {
+		change := clusterStats.Rate
+		if r.Stat == "TREND" {
+			change = clusterStats.Rate * float32(r.DecisionPeriod)
+		}
+		// Scale up if the metric is climbing faster than the limit and scale down if it is falling faster than the limit
+		if taskOperation == "scale_up" && change > r.Limit ||
+			taskOperation == "scale_down" && change < -r.Limit {
 			return true
 		} else {
 			return false
 		}
-	} else if r.Stat == "COUNT" || r.Stat == "TERM" {
+	} else if r.Stat == "FORECAST" {
+		var clusterStats cluster.MetricStats
+		err := json.Unmarshal(clusterMetric, &clusterStats)
+		if err != nil {
+			log.Panic.Println("Error converting struct to json: ", err)
+			panic(err)
+		}
+		// Scale up if the limit is predicted to be breached anytime in the next decision period
+		// and scale down only if the metric is predicted to stay below the limit for the whole decision period
+		if taskOperation == "scale_up" && clusterStats.Max > r.Limit ||
+			taskOperation == "scale_down" && clusterStats.Max < r.Limit {
+			return true
+		} else {
+			return false
+		}
+	} else if r.Stat != "COUNT" && r.Stat != "TERM" {
+		var clusterStats cluster.MetricStats
+		err := json.Unmarshal(clusterMetric, &clusterStats)
+		if err != nil {
+			log.Panic.Println("Error converting struct to json: ", err)
+			panic(err)
+		}
+		value := getStatValue(clusterStats, r.Stat)
+		if taskOperation == "scale_up" && value > r.Limit ||
+			taskOperation == "scale_down" && value < r.Limit {
+			return true
+		} else {
+			return false
+		}
+	} else {
 		var clusterStats cluster.MetricViolatedCount
 		err := json.Unmarshal(clusterMetric, &clusterStats)
 		if err != nil {
@@ -289,6 +551,79 @@ func EvaluateRule(clusterMetric []byte, taskOperation string, pollingInterval in
 	return false
 }
 
+// Input:
+//              nodeMetric ([]byte): Marshal list of MetricStatsNode or MetricViolatedCountNode structs based on stats.
+//              taskOperation (string); Task recommended
+//              pollingInterval (int): Time in seconds which is the interval between each metric is pushed into the index.
+//              r (config.Rule): The rule with the scope any_node, all_nodes or data_nodes.
+//
+// Description:
+//              EvaluateNodeRule evaluates the rule on every node using EvaluateRule and combines the result based on the scope.
+//              any_node is met if the rule is met on any node and all_nodes if the rule is met on all the nodes.
+//              data_nodes is met for scale_up if the rule is met on any data node and for scale_down if it is met on all the data nodes.
+//
+// Return:
+//              (bool, []string): Return whether a rule is meeting the criteria or not and the IPs of the nodes on which the rule is met.
+
+func EvaluateNodeRule(nodeMetric []byte, taskOperation string, pollingInterval int, r config.Rule) (bool, []string) {
+	var nodeMetrics []struct {
+		HostIp string
+	}
+	var nodeValues []json.RawMessage
+	err := json.Unmarshal(nodeMetric, &nodeMetrics)
+	if err == nil {
+		err = json.Unmarshal(nodeMetric, &nodeValues)
+	}
+	if err != nil {
+		log.Panic.Println("Error converting struct to json: ", err)
+		panic(err)
+	}
+	if len(nodeMetrics) == 0 {
+		return false, nil
+	}
+
+	// The embedded statistics are marshalled at the top level of every node, so every node can be evaluated as a cluster
+	var nodeIps []string
+	for i, nodeValue := range nodeValues {
+		if EvaluateRule(nodeValue, taskOperation, pollingInterval, r) {
+			nodeIps = append(nodeIps, nodeMetrics[i].HostIp)
+		}
+	}
+	log.Debug.Println(fmt.Sprintf("Rule met on %d of %d nodes: %v", len(nodeIps), len(nodeMetrics), nodeIps))
+
+	if r.Scope == "all_nodes" || r.Scope == "data_nodes" && taskOperation == "scale_down" {
+		return len(nodeIps) == len(nodeMetrics), nodeIps
+	}
+	return len(nodeIps) > 0, nodeIps
+}
+
+// Input:
+//              clusterStats (cluster.MetricStats): The statistics of the metric for the decision period.
+//              stat (string): The stat of the rule. These can be AVG, MAX, MIN, P90, P95, P99.
+//
+// Description:
+//              Returns the value of the statistics that needs to be compared with the limit of the rule.
+//
+// Return:
+//              (float32): Return the value of the stat.
+
+func getStatValue(clusterStats cluster.MetricStats, stat string) float32 {
+	switch stat {
+	case "MAX":
+		return clusterStats.Max
+	case "MIN":
+		return clusterStats.Min
+	case "P90":
+		return clusterStats.P90
+	case "P95":
+		return clusterStats.P95
+	case "P99":
+		return clusterStats.P99
+	default:
+		return clusterStats.Avg
+	}
+}
+
 //      Input:
 //
 //      Caller:
@@ -319,50 +654,116 @@ func ParseTasks(taskDetails config.TaskDetails) (*config.TaskDetails, *config.Ta
 
 // Input:
 //
-//	cronTasks ([]]recommendation.Task): List of tasks to be added to Cron Job
-//	state (*provision.State): A pointer to the state struct which is state maintained in OS document
+//	eventTasks (*config.TaskDetails): The EVENT tasks parsed by ParseTasks
 //	clusterCfg (config.ClusterDetails): Cluster Level config details
-//	usrCfg (config.UserConfig): User defined config for application behavior
+//	userCfg (config.UserConfig): User defined config for application behavior
+//	t (*time.Time): The time of the simulator when it is accelerated
 //
 // Description:
 //
-//	       At each polling interval creates the cron jobs based on the config file. It removes the Cron Jobs that were
-//	added in previous polling interval and creates required jobs. It will use the list of tasks (cronTasks) to
-//	       schedule and create cron job.
+//	Schedules a cron job for every rule of the EVENT tasks. The cron jobs are rebuilt only when the EVENT tasks change,
+//	so a job which is about to fire is not torn down by every polling interval. The jobs provision with the config
+//	passed in the latest call, so a change of the cluster details takes effect without rebuilding them.
 //
 // Return:
 func CreateCronJob(eventTasks *config.TaskDetails, clusterCfg config.ClusterDetails, userCfg config.UserConfig, t *time.Time) {
-	for _, cronJob := range cronJobList {
-		for _, jobs := range cronJob.Entries() {
-			cronJob.Remove(jobs.ID)
-		}
+	cronMutex.Lock()
+	defer cronMutex.Unlock()
+	cronClusterCfg, cronUserCfg = clusterCfg, userCfg
+	if reflect.DeepEqual(eventTasks.Tasks, cronTasks) {
+		return
 	}
 
+	for _, cronJob := range cronJobList {
+		cronJob.Stop()
+	}
 	cronJobList = nil
+	cronTasks = eventTasks.Tasks
 
 	for _, cronTask := range eventTasks.Tasks {
 		cronTask := cronTask
 		cronJob := cron.New()
 		for _, rules := range cronTask.Rules {
 			rules := rules
-			cronJob.AddFunc(rules.SchedulingTime, func() {
+			_, err := cronJob.AddFunc(rules.SchedulingTime, func() {
+				cronMutex.Lock()
+				clusterCfg, userCfg := cronClusterCfg, cronUserCfg
+				cronMutex.Unlock()
 				provision.TriggerCron(t, clusterCfg, userCfg, rules.SchedulingTime, cronTask.TaskName)
 			})
-			cronJobList = append(cronJobList, cronJob)
+			if err != nil {
+				log.Error.Println(fmt.Sprintf("Invalid scheduling time %s of the task %s: %s", rules.SchedulingTime, cronTask.TaskName, err))
+			}
 		}
 		cronJob.Start()
+		cronJobList = append(cronJobList, cronJob)
+	}
+	log.Info.Println(fmt.Sprintf("The cron jobs of %d EVENT task(s) are scheduled", len(eventTasks.Tasks)))
+}
+
+// This struct contains the next time an EVENT task is scheduled to be triggered.
+type EventFireTime struct {
+	// TaskName indicates the task triggered. i.e., scale_up_by_1
+	TaskName string
+	// SchedulingTime indicates the cron expression of the rule
+	SchedulingTime string
+	// NextFireTime indicates when the rule is triggered next
+	NextFireTime time.Time
+}
+
+// Input:
+//
+//	eventTasks (*config.TaskDetails): The EVENT tasks parsed by ParseTasks
+//	now (time.Time): The time after which the next fire times are computed
+//
+// Description:
+//
+//	Computes when every rule of the EVENT tasks is triggered next, with the parser used by the cron jobs in cronJobList.
+//	The times are computed from the rules rather than read from cronJobList, as the jobs are scheduled only in the process
+//	of the leader while the times are shown from any process.
+//
+// Return:
+//
+//	([]EventFireTime): Returns the next fire times ordered by time. The rules which can not be parsed are skipped.
+func NextEventFireTimes(eventTasks *config.TaskDetails, now time.Time) []EventFireTime {
+	var fireTimes []EventFireTime
+	for _, task := range eventTasks.Tasks {
+		for _, rule := range task.Rules {
+			schedule, err := cron.ParseStandard(rule.SchedulingTime)
+			if err != nil {
+				log.Warn.Println("Invalid scheduling time ", rule.SchedulingTime, " of the task ", task.TaskName, ": ", err)
+				continue
+			}
+			fireTimes = append(fireTimes, EventFireTime{
+				TaskName:       task.TaskName,
+				SchedulingTime: rule.SchedulingTime,
+				NextFireTime:   schedule.Next(now),
+			})
+		}
 	}
+	sort.SliceStable(fireTimes, func(i, j int) bool {
+		return fireTimes[i].NextFireTime.Before(fireTimes[j].NextFireTime)
+	})
+	return fireTimes
 }
 
 // Input:
+//              rulesResponsible (string): The rules responsible for the recommendation of the task.
 //
 // Caller:
 //              Object of Task
 // Description:
 //              PushToRecommendationQueue will be pushing the task which matches the criteria to recommendation queue.
+//              If the task is already present in the queue, it is merged with the existing recommendation.
 //
 // Return:
 
-func PushToRecommendationQueue(task config.Task) {
+func PushToRecommendationQueue(task config.Task, rulesResponsible string) {
+	recommendation, err := provision.NewRecommendation(task.TaskName, rulesResponsible, task.Priority)
+	if err != nil {
+		log.Error.Println(err)
+		return
+	}
 	log.Info.Println(fmt.Sprintf("The %s task is recommended and will be pushed to the queue", task.TaskName))
+	provision.PushRecommendation(recommendation)
 }
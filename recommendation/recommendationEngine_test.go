@@ -0,0 +1,93 @@
+package recommendation
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/maplelabs/opensearch-scaling-manager/cluster"
+	"github.com/maplelabs/opensearch-scaling-manager/config"
+)
+
+// The rules of the tests are met for scale_up when the average of the metric is above 50
+var testMetricAvg = map[string]float32{"CpuUtil": 80, "HeapUtil": 90, "RamUtil": 20, "DiskUtil": 10}
+
+func newTestRule(metric string) config.Rule {
+	return config.Rule{Metric: metric, Limit: 50, Stat: "AVG", DecisionPeriod: 60}
+}
+
+func newTestMetricCache(t *testing.T, metricAvg map[string]float32) *MetricCache {
+	metrics := &MetricCache{ctx: context.Background(), metrics: make(map[metricKey]metricResult)}
+	for metric, avg := range metricAvg {
+		clusterMetric, err := json.Marshal(cluster.MetricStats{Avg: avg, Count: 10})
+		if err != nil {
+			t.Fatalf("failed to marshal the metric %s: %v", metric, err)
+		}
+		metrics.metrics[getMetricKey(newTestRule(metric), "scale_up")] = metricResult{clusterMetric: clusterMetric}
+	}
+	return metrics
+}
+
+func newTestRuleGroup(operator string, metrics []string, groups ...config.RuleGroup) config.RuleGroup {
+	group := config.RuleGroup{Operator: operator, Groups: groups}
+	for _, metric := range metrics {
+		group.Rules = append(group.Rules, newTestRule(metric))
+	}
+	return group
+}
+
+func TestEvaluateRuleGroup(t *testing.T) {
+	tests := []struct {
+		name             string
+		group            config.RuleGroup
+		isRecommended    bool
+		rulesResponsible []string
+		results          []string
+	}{
+		{"or short-circuits on the first rule met", newTestRuleGroup("OR", []string{"CpuUtil", "HeapUtil"}),
+			true, []string{"CpuUtil-AVG-50.000000-60"}, []string{"pass", "skipped"}},
+		{"or not met", newTestRuleGroup("OR", []string{"RamUtil", "DiskUtil"}),
+			false, nil, []string{"fail", "fail"}},
+		{"and short-circuits on the first rule not met", newTestRuleGroup("AND", []string{"RamUtil", "CpuUtil"}),
+			false, nil, []string{"fail", "skipped"}},
+		{"and met", newTestRuleGroup("AND", []string{"CpuUtil", "HeapUtil"}),
+			true, []string{"CpuUtil-AVG-50.000000-60", "HeapUtil-AVG-50.000000-60"}, []string{"pass", "pass"}},
+		{"and of a rule and an or group", newTestRuleGroup("AND", []string{"CpuUtil"}, newTestRuleGroup("OR", []string{"RamUtil", "HeapUtil"})),
+			true, []string{"CpuUtil-AVG-50.000000-60", "HeapUtil-AVG-50.000000-60"}, []string{"pass", "fail", "pass"}},
+		{"or of and groups", newTestRuleGroup("OR", nil, newTestRuleGroup("AND", []string{"CpuUtil", "RamUtil"}), newTestRuleGroup("AND", []string{"HeapUtil", "CpuUtil"})),
+			true, []string{"HeapUtil-AVG-50.000000-60", "CpuUtil-AVG-50.000000-60"}, []string{"pass", "fail", "pass", "pass"}},
+		{"and skips the groups after a group not met", newTestRuleGroup("AND", []string{"CpuUtil"}, newTestRuleGroup("OR", []string{"RamUtil", "DiskUtil"}), newTestRuleGroup("OR", []string{"HeapUtil"})),
+			false, nil, []string{"pass", "fail", "fail", "skipped"}},
+		{"or skips the nested groups after a rule met", newTestRuleGroup("OR", []string{"CpuUtil"}, newTestRuleGroup("AND", []string{"RamUtil", "HeapUtil"})),
+			true, []string{"CpuUtil-AVG-50.000000-60"}, []string{"pass", "skipped", "skipped"}},
+	}
+	metrics := newTestMetricCache(t, testMetricAvg)
+	for _, test := range tests {
+		var report TaskReport
+		isRecommended, rulesResponsible := EvaluateRuleGroup("scale_up", 60, false, false, test.group, &report, metrics)
+		if isRecommended != test.isRecommended || !reflect.DeepEqual(rulesResponsible, test.rulesResponsible) {
+			t.Errorf("%s: expected %v with %v got %v with %v", test.name, test.isRecommended, test.rulesResponsible, isRecommended, rulesResponsible)
+		}
+		var results []string
+		for _, ruleReport := range report.Rules {
+			results = append(results, ruleReport.Result)
+		}
+		if !reflect.DeepEqual(results, test.results) {
+			t.Errorf("%s: expected the results %v got %v", test.name, test.results, results)
+		}
+	}
+}
+
+func TestGetNextTaskRulesResponsible(t *testing.T) {
+	task := config.Task{
+		TaskName: "scale_up_by_1",
+		Operator: "AND",
+		Rules:    []config.Rule{newTestRule("CpuUtil")},
+		Groups:   []config.RuleGroup{newTestRuleGroup("OR", []string{"RamUtil", "HeapUtil"})},
+	}
+	isRecommended, rulesResponsible := GetNextTask(60, false, false, task, &TaskReport{}, newTestMetricCache(t, testMetricAvg))
+	if expected := "CpuUtil-AVG-50.000000-60_and_HeapUtil-AVG-50.000000-60"; !isRecommended || rulesResponsible != expected {
+		t.Errorf("expected the task to be recommended with %s got %v with %s", expected, isRecommended, rulesResponsible)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/jarcoal/httpmock"
+	"github.com/maplelabs/opensearch-scaling-manager/config"
 	"github.com/opensearch-project/opensearch-go/opensearchapi"
 	"github.com/opensearch-project/opensearch-go/opensearchtransport"
 	"github.com/stretchr/testify/assert"
@@ -21,7 +22,7 @@ func TestTaskNotRecommendedOr(t *testing.T) {
 	defer httpmock.DeactivateAndReset()
 
 	yamlString := `{task_name: scale_up_by_1, operator: OR, rules: [{metric: CpuUtil, limit: 2, stat: AVG, decision_period: 9}, {metric: MemUtil, limit: 59, stat: AVG, decision_period: 9}]}`
-	var task = new(Task)
+	var task = new(config.Task)
 	err := yaml.Unmarshal([]byte(yamlString), &task)
 	if err != nil {
 		t.Fail()
@@ -49,7 +50,7 @@ func TestTaskNotRecommendedOr(t *testing.T) {
 		},
 	)
 
-	isRecommendedTask, _ := task.GetNextTask(5, true, false)
+	isRecommendedTask, _ := GetNextTask(5, true, false, *task, &TaskReport{}, nil)
 	t.Log(isRecommendedTask)
 	assert.Equal(t, false, isRecommendedTask)
 }
@@ -59,7 +60,7 @@ func TestTaskRecommendedOr(t *testing.T) {
 	defer httpmock.DeactivateAndReset()
 
 	yamlString := `{task_name: scale_up_by_1, operator: OR, rules: [{metric: CpuUtil, limit: 1, stat: AVG, decision_period: 9}, {metric: MemUtil, limit: 59, stat: AVG, decision_period: 9}]}`
-	var task = new(Task)
+	var task = new(config.Task)
 	err := yaml.Unmarshal([]byte(yamlString), &task)
 	if err != nil {
 		t.Fail()
@@ -77,7 +78,7 @@ func TestTaskRecommendedOr(t *testing.T) {
 		},
 	)
 
-	isRecommendedTask, _ := task.GetNextTask(5, true, false)
+	isRecommendedTask, _ := GetNextTask(5, true, false, *task, &TaskReport{}, nil)
 	t.Log(isRecommendedTask)
 	assert.Equal(t, true, isRecommendedTask)
 }
@@ -87,7 +88,7 @@ func TestTaskRecommendedOr1(t *testing.T) {
 	defer httpmock.DeactivateAndReset()
 
 	yamlString := `{task_name: scale_down_by_1, operator: OR, rules: [{metric: CpuUtil, limit: 2, stat: AVG, decision_period: 9}, {metric: MemUtil, limit: 59, stat: AVG, decision_period: 9}]}`
-	var task = new(Task)
+	var task = new(config.Task)
 	err := yaml.Unmarshal([]byte(yamlString), &task)
 	if err != nil {
 		t.Fail()
@@ -105,7 +106,7 @@ func TestTaskRecommendedOr1(t *testing.T) {
 		},
 	)
 
-	isRecommendedTask, _ := task.GetNextTask(5, true, false)
+	isRecommendedTask, _ := GetNextTask(5, true, false, *task, &TaskReport{}, nil)
 	t.Log(isRecommendedTask)
 	assert.Equal(t, true, isRecommendedTask)
 }
@@ -115,7 +116,7 @@ func TestTaskNotRecommendedOr1(t *testing.T) {
 	defer httpmock.DeactivateAndReset()
 
 	yamlString := `{task_name: scale_down_by_1, operator: OR, rules: [{metric: CpuUtil, limit: 1, stat: AVG, decision_period: 9}, {metric: MemUtil, limit: 29, stat: AVG, decision_period: 9}]}`
-	var task = new(Task)
+	var task = new(config.Task)
 	err := yaml.Unmarshal([]byte(yamlString), &task)
 	if err != nil {
 		t.Fail()
@@ -144,7 +145,7 @@ func TestTaskNotRecommendedOr1(t *testing.T) {
 		},
 	)
 
-	isRecommendedTask, _ := task.GetNextTask(5, true, false)
+	isRecommendedTask, _ := GetNextTask(5, true, false, *task, &TaskReport{}, nil)
 	t.Log(isRecommendedTask)
 	assert.Equal(t, false, isRecommendedTask)
 }
@@ -154,7 +155,7 @@ func TestTaskNotRecommendedAnd(t *testing.T) {
 	defer httpmock.DeactivateAndReset()
 
 	yamlString := `{task_name: scale_up_by_1, operator: AND, rules: [{metric: CpuUtil, limit: 2, stat: AVG, decision_period: 9}, {metric: MemUtil, limit: 59, stat: AVG, decision_period: 9}]}`
-	var task = new(Task)
+	var task = new(config.Task)
 	err := yaml.Unmarshal([]byte(yamlString), &task)
 	if err != nil {
 		t.Fail()
@@ -183,7 +184,7 @@ func TestTaskNotRecommendedAnd(t *testing.T) {
 		},
 	)
 
-	isRecommendedTask, _ := task.GetNextTask(5, true, false)
+	isRecommendedTask, _ := GetNextTask(5, true, false, *task, &TaskReport{}, nil)
 	t.Log(isRecommendedTask)
 	assert.Equal(t, false, isRecommendedTask)
 }
@@ -193,7 +194,7 @@ func TestTaskNotRecommendedAnd1(t *testing.T) {
 	defer httpmock.DeactivateAndReset()
 
 	yamlString := `{task_name: scale_up_by_1, operator: AND, rules: [{metric: CpuUtil, limit: 1, stat: AVG, decision_period: 9}, {metric: MemUtil, limit: 59, stat: AVG, decision_period: 9}, {metric: MemUtil, limit: 70, stat: AVG, decision_period: 9}]}`
-	var task = new(Task)
+	var task = new(config.Task)
 	err := yaml.Unmarshal([]byte(yamlString), &task)
 	if err != nil {
 		t.Fail()
@@ -222,7 +223,7 @@ func TestTaskNotRecommendedAnd1(t *testing.T) {
 		},
 	)
 
-	isRecommendedTask, _ := task.GetNextTask(5, true, false)
+	isRecommendedTask, _ := GetNextTask(5, true, false, *task, &TaskReport{}, nil)
 	t.Log(isRecommendedTask)
 	assert.Equal(t, false, isRecommendedTask)
 }
@@ -232,7 +233,7 @@ func TestTaskNotRecommendedAnd2(t *testing.T) {
 	defer httpmock.DeactivateAndReset()
 
 	yamlString := `{task_name: scale_down_by_1, operator: AND, rules: [{metric: CpuUtil, limit: 10, stat: AVG, decision_period: 9}, {metric: MemUtil, limit: 61, stat: AVG, decision_period: 9}, {metric: MemUtil, limit: 50, stat: AVG, decision_period: 9}]}`
-	var task = new(Task)
+	var task = new(config.Task)
 	err := yaml.Unmarshal([]byte(yamlString), &task)
 	if err != nil {
 		t.Fail()
@@ -261,7 +262,7 @@ func TestTaskNotRecommendedAnd2(t *testing.T) {
 		},
 	)
 
-	isRecommendedTask, _ := task.GetNextTask(5, true, false)
+	isRecommendedTask, _ := GetNextTask(5, true, false, *task, &TaskReport{}, nil)
 	t.Log(isRecommendedTask)
 	assert.Equal(t, false, isRecommendedTask)
 }
@@ -271,7 +272,7 @@ func TestTaskRecommendedAnd(t *testing.T) {
 	defer httpmock.DeactivateAndReset()
 
 	yamlString := `{task_name: scale_up_by_1, operator: AND, rules: [{metric: CpuUtil, limit: 1, stat: AVG, decision_period: 9}, {metric: MemUtil, limit: 10, stat: AVG, decision_period: 9}]}`
-	var task = new(Task)
+	var task = new(config.Task)
 	err := yaml.Unmarshal([]byte(yamlString), &task)
 	if err != nil {
 		t.Fail()
@@ -300,7 +301,7 @@ func TestTaskRecommendedAnd(t *testing.T) {
 		},
 	)
 
-	isRecommendedTask, _ := task.GetNextTask(5, true, false)
+	isRecommendedTask, _ := GetNextTask(5, true, false, *task, &TaskReport{}, nil)
 	t.Log(isRecommendedTask)
 	assert.Equal(t, true, isRecommendedTask)
 }
@@ -310,7 +311,7 @@ func TestTaskRecommendedAnd1(t *testing.T) {
 	defer httpmock.DeactivateAndReset()
 
 	yamlString := `{task_name: scale_down_by_1, operator: AND, rules: [{metric: CpuUtil, limit: 5, stat: AVG, decision_period: 9}, {metric: MemUtil, limit: 30, stat: AVG, decision_period: 9}]}`
-	var task = new(Task)
+	var task = new(config.Task)
 	err := yaml.Unmarshal([]byte(yamlString), &task)
 	if err != nil {
 		t.Fail()
@@ -339,7 +340,7 @@ func TestTaskRecommendedAnd1(t *testing.T) {
 		},
 	)
 
-	isRecommendedTask, _ := task.GetNextTask(5, true, false)
+	isRecommendedTask, _ := GetNextTask(5, true, false, *task, &TaskReport{}, nil)
 	t.Log(isRecommendedTask)
 	assert.Equal(t, true, isRecommendedTask)
 }
@@ -349,7 +350,7 @@ func TestTaskNotEnoughDataAnd(t *testing.T) {
 	defer httpmock.DeactivateAndReset()
 
 	yamlString := `{task_name: scale_up_by_1, operator: AND, rules: [{metric: CpuUtil, limit: 1, stat: AVG, decision_period: 9}, {metric: MemUtil, limit: 10, stat: AVG, decision_period: 9}, {metric: MemUtil, limit: 10, stat: AVG, decision_period: 9}]}`
-	var task = new(Task)
+	var task = new(config.Task)
 	err := yaml.Unmarshal([]byte(yamlString), &task)
 	if err != nil {
 		t.Fail()
@@ -374,7 +375,7 @@ func TestTaskNotEnoughDataAnd(t *testing.T) {
 		},
 	)
 
-	isRecommendedTask, _ := task.GetNextTask(5, true, false)
+	isRecommendedTask, _ := GetNextTask(5, true, false, *task, &TaskReport{}, nil)
 	t.Log(isRecommendedTask)
 	assert.Equal(t, false, isRecommendedTask)
 }
@@ -384,7 +385,7 @@ func TestTaskNotEnoughDataAnd1(t *testing.T) {
 	defer httpmock.DeactivateAndReset()
 
 	yamlString := `{task_name: scale_up_by_1, operator: AND, rules: [{metric: CpuUtil, limit: 1, stat: AVG, decision_period: 9}, {metric: MemUtil, limit: 10, stat: AVG, decision_period: 9}, {metric: MemUtil, limit: 10, stat: AVG, decision_period: 9}]}`
-	var task = new(Task)
+	var task = new(config.Task)
 	err := yaml.Unmarshal([]byte(yamlString), &task)
 	if err != nil {
 		t.Fail()
@@ -420,7 +421,7 @@ func TestTaskNotEnoughDataAnd1(t *testing.T) {
 		},
 	)
 
-	isRecommendedTask, _ := task.GetNextTask(5, true, false)
+	isRecommendedTask, _ := GetNextTask(5, true, false, *task, &TaskReport{}, nil)
 	t.Log(isRecommendedTask)
 	assert.Equal(t, false, isRecommendedTask)
 }
@@ -430,7 +431,7 @@ func TestTaskNotEnoughDataOr(t *testing.T) {
 	defer httpmock.DeactivateAndReset()
 
 	yamlString := `{task_name: scale_up_by_1, operator: OR, rules: [{metric: CpuUtil, limit: 5, stat: AVG, decision_period: 9}, {metric: MemUtil, limit: 59, stat: AVG, decision_period: 9}]}`
-	var task = new(Task)
+	var task = new(config.Task)
 	err := yaml.Unmarshal([]byte(yamlString), &task)
 	if err != nil {
 		t.Fail()
@@ -455,7 +456,7 @@ func TestTaskNotEnoughDataOr(t *testing.T) {
 		},
 	)
 
-	isRecommendedTask, _ := task.GetNextTask(5, true, false)
+	isRecommendedTask, _ := GetNextTask(5, true, false, *task, &TaskReport{}, nil)
 	t.Log(isRecommendedTask)
 	assert.Equal(t, false, isRecommendedTask)
 }
@@ -465,7 +466,7 @@ func TestTaskDecisionPeriodSmallAnd(t *testing.T) {
 	defer httpmock.DeactivateAndReset()
 
 	yamlString := `{task_name: scale_up_by_1, operator: AND, rules: [{metric: CpuUtil, limit: 1, stat: AVG, decision_period: 9}, {metric: MemUtil, limit: 10, stat: AVG, decision_period: 9}, {metric: MemUtil, limit: 10, stat: AVG, decision_period: 9}]}`
-	var task = new(Task)
+	var task = new(config.Task)
 	err := yaml.Unmarshal([]byte(yamlString), &task)
 	if err != nil {
 		t.Fail()
@@ -490,7 +491,7 @@ func TestTaskDecisionPeriodSmallAnd(t *testing.T) {
 		},
 	)
 
-	isRecommendedTask, _ := task.GetNextTask(5, true, false)
+	isRecommendedTask, _ := GetNextTask(5, true, false, *task, &TaskReport{}, nil)
 	t.Log(isRecommendedTask)
 	assert.Equal(t, false, isRecommendedTask)
 }
@@ -500,7 +501,7 @@ func TestTaskDecisionPeriodSmallOr(t *testing.T) {
 	defer httpmock.DeactivateAndReset()
 
 	yamlString := `{task_name: scale_up_by_1, operator: OR, rules: [{metric: CpuUtil, limit: 5, stat: AVG, decision_period: 9}, {metric: MemUtil, limit: 59, stat: AVG, decision_period: 9}]}`
-	var task = new(Task)
+	var task = new(config.Task)
 	err := yaml.Unmarshal([]byte(yamlString), &task)
 	if err != nil {
 		t.Fail()
@@ -525,7 +526,7 @@ func TestTaskDecisionPeriodSmallOr(t *testing.T) {
 		},
 	)
 
-	isRecommendedTask, _ := task.GetNextTask(5, true, false)
+	isRecommendedTask, _ := GetNextTask(5, true, false, *task, &TaskReport{}, nil)
 	t.Log(isRecommendedTask)
 	assert.Equal(t, false, isRecommendedTask)
 }
@@ -534,8 +535,8 @@ func TestTaskNotRecommendedOrCountTerm(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 
-	yamlString := `{task_name: scale_up_by_1, operator: OR, rules: [{metric: CpuUtil, limit: 1, stat: COUNT, occurences: 10, decision_period: 9}, {metric: MemUtil, limit: 59, stat: COUNT, occurences: 12, decision_period: 9}]}`
-	var task = new(Task)
+	yamlString := `{task_name: scale_up_by_1, operator: OR, rules: [{metric: CpuUtil, limit: 1, stat: COUNT, occurrences_percent: 10, decision_period: 9}, {metric: MemUtil, limit: 59, stat: COUNT, occurrences_percent: 12, decision_period: 9}]}`
+	var task = new(config.Task)
 	err := yaml.Unmarshal([]byte(yamlString), &task)
 	if err != nil {
 		t.Fail()
@@ -560,7 +561,7 @@ func TestTaskNotRecommendedOrCountTerm(t *testing.T) {
 		},
 	)
 
-	isRecommendedTask, _ := task.GetNextTask(5, true, false)
+	isRecommendedTask, _ := GetNextTask(5, true, false, *task, &TaskReport{}, nil)
 	t.Log(isRecommendedTask)
 	assert.Equal(t, false, isRecommendedTask)
 }
@@ -569,9 +570,9 @@ func TestTaskRecommendedOrCountTerm(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 
-	yamlString := `{task_name: scale_up_by_1, operator: OR, rules: [{metric: CpuUtil, limit: 1, stat: COUNT, occurences: 10, decision_period: 9}, {metric: MemUtil, limit: 59, stat: COUNT, occurences: 12, decision_period: 9}]}`
+	yamlString := `{task_name: scale_up_by_1, operator: OR, rules: [{metric: CpuUtil, limit: 1, stat: COUNT, occurrences_percent: 10, decision_period: 9}, {metric: MemUtil, limit: 59, stat: COUNT, occurrences_percent: 12, decision_period: 9}]}`
 
-	var task = new(Task)
+	var task = new(config.Task)
 	err := yaml.Unmarshal([]byte(yamlString), &task)
 	if err != nil {
 		t.Fail()
@@ -596,7 +597,7 @@ func TestTaskRecommendedOrCountTerm(t *testing.T) {
 		},
 	)
 
-	isRecommendedTask, _ := task.GetNextTask(5, true, false)
+	isRecommendedTask, _ := GetNextTask(5, true, false, *task, &TaskReport{}, nil)
 	t.Log(isRecommendedTask)
 	assert.Equal(t, false, isRecommendedTask)
 }
@@ -605,8 +606,8 @@ func TestTaskNotRecommendedOrCountTerm1(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 
-	yamlString := `{task_name: scale_down_by_1, operator: OR, rules: [{metric: CpuUtil, limit: 1, stat: COUNT, occurences: 2, decision_period: 9}, {metric: MemUtil, limit: 59, stat: COUNT, occurences: 12, decision_period: 9}]}`
-	var task = new(Task)
+	yamlString := `{task_name: scale_down_by_1, operator: OR, rules: [{metric: CpuUtil, limit: 1, stat: COUNT, occurrences_percent: 2, decision_period: 9}, {metric: MemUtil, limit: 59, stat: COUNT, occurrences_percent: 12, decision_period: 9}]}`
+	var task = new(config.Task)
 	err := yaml.Unmarshal([]byte(yamlString), &task)
 	if err != nil {
 		t.Fail()
@@ -631,7 +632,7 @@ func TestTaskNotRecommendedOrCountTerm1(t *testing.T) {
 		},
 	)
 
-	isRecommendedTask, _ := task.GetNextTask(5, true, false)
+	isRecommendedTask, _ := GetNextTask(5, true, false, *task, &TaskReport{}, nil)
 	t.Log(isRecommendedTask)
 	assert.Equal(t, false, isRecommendedTask)
 }
@@ -640,9 +641,9 @@ func TestTaskRecommendedOrCountTerm1(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 
-	yamlString := `{task_name: scale_down_by_1, operator: OR, rules: [{metric: CpuUtil, limit: 1, stat: COUNT, occurences: 5, decision_period: 9}, {metric: MemUtil, limit: 59, stat: COUNT, occurences: 12, decision_period: 9}]}`
+	yamlString := `{task_name: scale_down_by_1, operator: OR, rules: [{metric: CpuUtil, limit: 1, stat: COUNT, occurrences_percent: 5, decision_period: 9}, {metric: MemUtil, limit: 59, stat: COUNT, occurrences_percent: 12, decision_period: 9}]}`
 
-	var task = new(Task)
+	var task = new(config.Task)
 	err := yaml.Unmarshal([]byte(yamlString), &task)
 	if err != nil {
 		t.Fail()
@@ -667,7 +668,7 @@ func TestTaskRecommendedOrCountTerm1(t *testing.T) {
 		},
 	)
 
-	isRecommendedTask, _ := task.GetNextTask(5, true, false)
+	isRecommendedTask, _ := GetNextTask(5, true, false, *task, &TaskReport{}, nil)
 	t.Log(isRecommendedTask)
 	assert.Equal(t, false, isRecommendedTask)
 }
@@ -676,8 +677,8 @@ func TestTaskRecommendedAndCountTerm(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 
-	yamlString := `{task_name: scale_up_by_1, operator: AND, rules: [{metric: CpuUtil, limit: 1.0, stat: COUNT, occurences: 10, decision_period: 9}, {metric: MemUtil, limit: 59.0, stat: COUNT, occurences: 12, decision_period: 9}]}`
-	var task = new(Task)
+	yamlString := `{task_name: scale_up_by_1, operator: AND, rules: [{metric: CpuUtil, limit: 1.0, stat: COUNT, occurrences_percent: 10, decision_period: 9}, {metric: MemUtil, limit: 59.0, stat: COUNT, occurrences_percent: 12, decision_period: 9}]}`
+	var task = new(config.Task)
 	err := yaml.Unmarshal([]byte(yamlString), &task)
 	if err != nil {
 		t.Fail()
@@ -702,7 +703,7 @@ func TestTaskRecommendedAndCountTerm(t *testing.T) {
 		},
 	)
 
-	isRecommendedTask, _ := task.GetNextTask(5, true, false)
+	isRecommendedTask, _ := GetNextTask(5, true, false, *task, &TaskReport{}, nil)
 	t.Log(isRecommendedTask)
 	assert.Equal(t, false, isRecommendedTask)
 }
@@ -711,8 +712,8 @@ func TestTaskNotRecommendedAndCountTerm(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 
-	yamlString := `{task_name: scale_up_by_1, operator: AND, rules: [{metric: CpuUtil, limit: 1, stat: COUNT, occurences: 3, decision_period: 9}, {metric: MemUtil, limit: 59, stat: COUNT, occurences: 12, decision_period: 9}]}`
-	var task = new(Task)
+	yamlString := `{task_name: scale_up_by_1, operator: AND, rules: [{metric: CpuUtil, limit: 1, stat: COUNT, occurrences_percent: 3, decision_period: 9}, {metric: MemUtil, limit: 59, stat: COUNT, occurrences_percent: 12, decision_period: 9}]}`
+	var task = new(config.Task)
 	err := yaml.Unmarshal([]byte(yamlString), &task)
 	if err != nil {
 		t.Fail()
@@ -736,7 +737,7 @@ func TestTaskNotRecommendedAndCountTerm(t *testing.T) {
 			return resp, err
 		},
 	)
-	isRecommendedTask, _ := task.GetNextTask(5, true, false)
+	isRecommendedTask, _ := GetNextTask(5, true, false, *task, &TaskReport{}, nil)
 	t.Log(isRecommendedTask)
 	assert.Equal(t, false, isRecommendedTask)
 }
@@ -745,8 +746,8 @@ func TestTaskRecommendedAndCountTerm1(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 
-	yamlString := `{task_name: scale_down_by_1, operator: AND, rules: [{metric: CpuUtil, limit: 1.0, stat: COUNT, occurences: 10, decision_period: 9}, {metric: MemUtil, limit: 59.0, stat: COUNT, occurences: 12, decision_period: 9}]}`
-	var task = new(Task)
+	yamlString := `{task_name: scale_down_by_1, operator: AND, rules: [{metric: CpuUtil, limit: 1.0, stat: COUNT, occurrences_percent: 10, decision_period: 9}, {metric: MemUtil, limit: 59.0, stat: COUNT, occurrences_percent: 12, decision_period: 9}]}`
+	var task = new(config.Task)
 	err := yaml.Unmarshal([]byte(yamlString), &task)
 	if err != nil {
 		t.Fail()
@@ -771,7 +772,7 @@ func TestTaskRecommendedAndCountTerm1(t *testing.T) {
 		},
 	)
 
-	isRecommendedTask, _ := task.GetNextTask(5, true, false)
+	isRecommendedTask, _ := GetNextTask(5, true, false, *task, &TaskReport{}, nil)
 	t.Log(isRecommendedTask)
 	assert.Equal(t, false, isRecommendedTask)
 }
@@ -780,8 +781,8 @@ func TestTaskNotRecommendedAndCountTerm1(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 
-	yamlString := `{task_name: scale_down_by_1, operator: AND, rules: [{metric: CpuUtil, limit: 1, stat: COUNT, occurences: 3, decision_period: 9}, {metric: MemUtil, limit: 59, stat: COUNT, occurences: 12, decision_period: 9}]}`
-	var task = new(Task)
+	yamlString := `{task_name: scale_down_by_1, operator: AND, rules: [{metric: CpuUtil, limit: 1, stat: COUNT, occurrences_percent: 3, decision_period: 9}, {metric: MemUtil, limit: 59, stat: COUNT, occurrences_percent: 12, decision_period: 9}]}`
+	var task = new(config.Task)
 	err := yaml.Unmarshal([]byte(yamlString), &task)
 	if err != nil {
 		t.Fail()
@@ -805,7 +806,7 @@ func TestTaskNotRecommendedAndCountTerm1(t *testing.T) {
 			return resp, err
 		},
 	)
-	isRecommendedTask, _ := task.GetNextTask(5, true, false)
+	isRecommendedTask, _ := GetNextTask(5, true, false, *task, &TaskReport{}, nil)
 	t.Log(isRecommendedTask)
 	assert.Equal(t, false, isRecommendedTask)
 }
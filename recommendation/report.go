@@ -0,0 +1,248 @@
+package recommendation
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/maplelabs/opensearch-scaling-manager/cluster"
+	"github.com/maplelabs/opensearch-scaling-manager/config"
+	osutils "github.com/maplelabs/opensearch-scaling-manager/opensearchUtils"
+)
+
+// This struct contains the outcome of the evaluation of a rule on a node, for the rules with a node scope.
+type NodeReport struct {
+	// HostIp indicates the IP Address of the node
+	HostIp string
+	// Value indicates the value of the stat compared with the limit
+	Value float32
+	// ViolatedCount indicates the number of data points which crossed the limit, for the stat COUNT
+	ViolatedCount int
+	// DataPoints indicates the number of data points of the node in the decision period
+	DataPoints int
+	// Result indicates whether the rule is met on the node. i.e., pass/fail
+	Result string
+}
+
+// This struct contains the outcome of the evaluation of a rule along with the values it was evaluated on.
+type RuleReport struct {
+	// Metric, Stat, Scope, Limit, Occurrences and DecisionPeriod are copied from the rule
+	Metric         string
+	Stat           string
+	Scope          string `json:"Scope,omitempty"`
+	Limit          float32
+	Occurrences    int `json:"Occurrences,omitempty"`
+	DecisionPeriod int
+	// Value indicates the value of the stat compared with the limit. It is the change over the decision period for TREND,
+	// the change per minute for RATE, the forecasted maximum for FORECAST and the average for a target_tracking rule.
+	Value float32
+	// ViolatedCount indicates the number of data points which crossed the limit, for the stats COUNT and TERM
+	ViolatedCount int
+	// RequiredCount indicates the number of data points which need to cross the limit for the rule to be met, for the stats COUNT and TERM
+	RequiredCount int
+	// DataPoints indicates the number of data points in the decision period
+	DataPoints int
+	// Nodes indicates the outcome on every node, for the rules with a node scope
+	Nodes []NodeReport `json:"Nodes,omitempty"`
+	// Result indicates the outcome of the rule. These can be:
+	//              pass: The rule is met.
+	//              fail: The rule is not met.
+	//              error: The rule could not be evaluated, Ex: Not enough data points.
+	//              skipped: The rule was not evaluated as the outcome of the task was decided by the other rules.
+	Result string
+	// Error indicates why the rule could not be evaluated
+	Error string `json:"Error,omitempty"`
+}
+
+// This struct contains the outcome of the evaluation of a task along with the report of every rule.
+type TaskReport struct {
+	// TaskName indicates the task in the config. i.e., scale_up_by_1, target_tracking
+	TaskName string
+	// RecommendedTask indicates the task recommended. It differs from the TaskName for target_tracking.
+	RecommendedTask string `json:"RecommendedTask,omitempty"`
+	// Operator indicates the logical operation performed on the rules
+	Operator string `json:"Operator,omitempty"`
+	// Result indicates the outcome of the task. These can be:
+	//              recommended: The task is pushed to the recommendation queue.
+	//              not_met: The rules of the task are not met.
+	//              stabilizing: The rules are met, but not for the whole stabilization window.
+	//              cooldown: The rules are met, but the task is in the cooldown of the last provision.
+	Result string
+	// RulesResponsible indicates the rules responsible for the recommendation
+	RulesResponsible string `json:"RulesResponsible,omitempty"`
+	// Rules indicates the report of every rule of the task including the rules of the nested groups
+	Rules []RuleReport
+}
+
+// Input:
+//              r (config.Rule): The rule for which the report is created
+//
+// Description:
+//              Creates the report of a rule which is not evaluated yet.
+//
+// Return:
+//              (RuleReport): Return the report of the rule.
+
+func newRuleReport(r config.Rule) RuleReport {
+	ruleReport := RuleReport{
+		Metric:         r.Metric,
+		Stat:           r.Stat,
+		Scope:          r.Scope,
+		Limit:          r.Limit,
+		DecisionPeriod: r.DecisionPeriod,
+		Result:         "skipped",
+	}
+	if r.Stat == "COUNT" || r.Stat == "TERM" {
+		ruleReport.Occurrences = r.Occurrences
+	}
+	return ruleReport
+}
+
+// Input:
+//              group (config.RuleGroup): The rules and nested groups which were not evaluated
+//              report (*TaskReport): The report of the task to which the rules are added
+//
+// Description:
+//              Adds the rules of the group and its nested groups to the report as skipped,
+//              so that the report covers every rule of the task even when the evaluation is short-circuited.
+//
+// Return:
+
+func addSkippedRules(group config.RuleGroup, report *TaskReport) {
+	for _, r := range group.Rules {
+		report.Rules = append(report.Rules, newRuleReport(r))
+	}
+	for _, subGroup := range group.Groups {
+		addSkippedRules(subGroup, report)
+	}
+}
+
+// Input:
+//              ruleReport (*RuleReport): The report in which the values are populated
+//              clusterMetric ([]byte): Marshal struct containing clusterMetric details based on stats, as returned by GetMetrics.
+//              taskOperation (string); Recommended operation
+//              pollingInterval (int): Time in seconds which is the interval between each metric is pushed into the index.
+//              r (config.Rule): The rule evaluated
+//
+// Description:
+//              Populates the value compared with the limit, the violated and required count and the number of data points,
+//              in the same way EvaluateRule compares them.
+//
+// Return:
+
+func fillRuleReport(ruleReport *RuleReport, clusterMetric []byte, taskOperation string, pollingInterval int, r config.Rule) {
+	if r.Stat == "COUNT" || r.Stat == "TERM" {
+		var clusterCount cluster.MetricViolatedCount
+		if err := json.Unmarshal(clusterMetric, &clusterCount); err != nil {
+			log.Error.Println("Error converting struct to json: ", err)
+			return
+		}
+		ruleReport.ViolatedCount = clusterCount.ViolatedCount
+		ruleReport.DataPoints = clusterCount.TotalCount
+		if r.Stat == "COUNT" {
+			// The smallest count for which (count * 100) / counts >= occurrences
+			counts := (r.DecisionPeriod * 60) / pollingInterval
+			ruleReport.RequiredCount = (r.Occurrences*counts + 99) / 100
+		} else if taskOperation == "scale_up" {
+			ruleReport.RequiredCount = clusterCount.TotalCount
+		}
+		return
+	}
+
+	var clusterStats cluster.MetricStats
+	if err := json.Unmarshal(clusterMetric, &clusterStats); err != nil {
+		log.Error.Println("Error converting struct to json: ", err)
+		return
+	}
+	ruleReport.DataPoints = clusterStats.Count
+	switch r.Stat {
+	case "TREND":
+		ruleReport.Value = clusterStats.Rate * float32(r.DecisionPeriod)
+	case "RATE":
+		ruleReport.Value = clusterStats.Rate
+	case "FORECAST":
+		ruleReport.Value = clusterStats.Max
+	default:
+		ruleReport.Value = getStatValue(clusterStats, r.Stat)
+	}
+}
+
+// Input:
+//              ruleReport (*RuleReport): The report in which the outcome of every node is populated
+//              nodeMetric ([]byte): Marshal list of MetricStatsNode or MetricViolatedCountNode structs based on stats.
+//              taskOperation (string); Recommended operation
+//              pollingInterval (int): Time in seconds which is the interval between each metric is pushed into the index.
+//              r (config.Rule): The rule with the scope any_node, all_nodes or data_nodes.
+//              nodeIps ([]string): The IPs of the nodes on which the rule is met.
+//
+// Description:
+//              Populates the report of every node for a rule with a node scope.
+//
+// Return:
+
+func fillNodeRuleReport(ruleReport *RuleReport, nodeMetric []byte, taskOperation string, pollingInterval int, r config.Rule, nodeIps []string) {
+	var nodeMetrics []struct {
+		HostIp string
+	}
+	var nodeValues []json.RawMessage
+	err := json.Unmarshal(nodeMetric, &nodeMetrics)
+	if err == nil {
+		err = json.Unmarshal(nodeMetric, &nodeValues)
+	}
+	if err != nil {
+		log.Error.Println("Error converting struct to json: ", err)
+		return
+	}
+
+	isMet := make(map[string]bool)
+	for _, nodeIp := range nodeIps {
+		isMet[nodeIp] = true
+	}
+	for i, nodeValue := range nodeValues {
+		var nodeRuleReport RuleReport
+		fillRuleReport(&nodeRuleReport, nodeValue, taskOperation, pollingInterval, r)
+		nodeReport := NodeReport{
+			HostIp:        nodeMetrics[i].HostIp,
+			Value:         nodeRuleReport.Value,
+			ViolatedCount: nodeRuleReport.ViolatedCount,
+			DataPoints:    nodeRuleReport.DataPoints,
+			Result:        "fail",
+		}
+		if isMet[nodeReport.HostIp] {
+			nodeReport.Result = "pass"
+		}
+		ruleReport.Nodes = append(ruleReport.Nodes, nodeReport)
+		ruleReport.DataPoints += nodeReport.DataPoints
+	}
+}
+
+// Input:
+//              taskReports ([]TaskReport): The report of every task evaluated in the evaluation cycle
+//
+// Description:
+//              Adds a document to Opensearch with the report of the evaluation cycle,
+//              so that it can be audited why a scale did or did not happen.
+//
+// Return:
+
+func PushReportToOs(taskReports []TaskReport) {
+	report := make(map[string]interface{}, 0)
+	report["Tasks"] = taskReports
+	report["StatTag"] = "RecommendationReport"
+	report["_documentType"] = "RecommendationReport"
+	report["Timestamp"] = time.Now().UnixMilli()
+
+	doc, err := json.Marshal(report)
+	if err != nil {
+		log.Error.Println("json.Marshal ERROR: ", err)
+		return
+	}
+
+	indexResponse, err := osutils.IndexMetrics(ctx, doc)
+	if err != nil {
+		log.Error.Println(fmt.Sprintf("Failed to insert the recommendation report of %d tasks: %s", len(taskReports), err))
+		return
+	}
+	defer indexResponse.Body.Close()
+	log.Debug.Println("Update resp: ", indexResponse)
+}
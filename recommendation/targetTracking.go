@@ -0,0 +1,147 @@
+package recommendation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/maplelabs/opensearch-scaling-manager/cluster"
+	"github.com/maplelabs/opensearch-scaling-manager/cluster_sim"
+	"github.com/maplelabs/opensearch-scaling-manager/config"
+)
+
+// Inputs:
+//              simFlag (bool): A flag to check if the task needs to collect stats from Opensearch data or simulated data.
+//              pollingInterval (int): Time in seconds which is the interval between each metric is pushed into the index.
+//              t (config.Task): The target_tracking task
+//              clusterCfg (config.ClusterDetails): Cluster Level config details which contains the min and max nodes allowed
+//              report (*TaskReport): The report of the task to which the report of every rule is added.
+//              metrics (*MetricCache): The metrics fetched for the evaluation cycle.
+//
+// Description:
+//              GetTargetTrackingTask computes the desired number of nodes for every rule of the target_tracking task
+//              using the average of the metric over the decision period and the current number of nodes.
+//              The largest desired node count across the rules is taken, so that a scale down happens only when all the
+//              metrics are below their target. The desired node count is clamped to min_nodes_allowed and max_nodes_allowed.
+//              The difference with the current node count is converted to a scale_up_by_N or scale_down_by_N task.
+//              In the report, a rule passes if the desired node count of the rule differs from the current node count.
+//
+// Return:
+//              (string, string): Returns the task to be recommended (empty if no change is needed) and the rules responsible for it.
+
+func GetTargetTrackingTask(pollingInterval int, simFlag, isAccelerated bool, t config.Task, clusterCfg config.ClusterDetails, report *TaskReport, metrics *MetricCache) (string, string) {
+	var currentNodes int
+	if simFlag {
+		currentNodes = cluster_sim.GetClusterCurrent(isAccelerated).NumNodes
+	} else {
+		clusterDynamic, _ := cluster.GetClusterCurrent(false)
+		currentNodes = clusterDynamic.NumNodes
+	}
+	if currentNodes == 0 {
+		log.Warn.Println("Unable to get the current number of nodes for the target_tracking task")
+		return "", ""
+	}
+
+	var desiredNodes int
+	var allRulesEvaluated bool = true
+	var rules []string
+	var rulesResponsible string
+	for _, r := range t.Rules {
+		r.Stat = "AVG"
+		ruleReport := RuleReport{
+			Metric:         r.Metric,
+			Stat:           "TARGET",
+			Limit:          r.TargetValue,
+			DecisionPeriod: r.DecisionPeriod,
+		}
+		clusterMetric, err := metrics.getMetrics(pollingInterval, simFlag, isAccelerated, r, t.TaskName)
+		if err != nil {
+			log.Warn.Println(fmt.Sprintf("%s for the rule: %v", err, r))
+			ruleReport.Result = "error"
+			ruleReport.Error = err.Error()
+			report.Rules = append(report.Rules, ruleReport)
+			allRulesEvaluated = false
+			continue
+		}
+		var clusterStats cluster.MetricStats
+		err = json.Unmarshal(clusterMetric, &clusterStats)
+		if err != nil {
+			log.Panic.Println("Error converting struct to json: ", err)
+			panic(err)
+		}
+		rule := fmt.Sprintf("%s-TARGET-%f-%d", r.Metric, r.TargetValue, r.DecisionPeriod)
+		rules = append(rules, rule)
+		ruleDesiredNodes := getDesiredNodes(currentNodes, clusterStats.Avg, r.TargetValue)
+		log.Debug.Println(fmt.Sprintf("%s average: %f, target: %f, desired nodes: %d", r.Metric, clusterStats.Avg, r.TargetValue, ruleDesiredNodes))
+		ruleReport.Value = clusterStats.Avg
+		ruleReport.DataPoints = clusterStats.Count
+		ruleReport.Result = "fail"
+		if ruleDesiredNodes != currentNodes {
+			ruleReport.Result = "pass"
+		}
+		report.Rules = append(report.Rules, ruleReport)
+		if ruleDesiredNodes > desiredNodes {
+			desiredNodes = ruleDesiredNodes
+			rulesResponsible = rule
+		}
+	}
+	if len(rules) == 0 {
+		return "", ""
+	}
+
+	desiredNodes = clampNodes(desiredNodes, clusterCfg.MinNodesAllowed, clusterCfg.MaxNodesAllowed)
+	if desiredNodes > currentNodes {
+		return fmt.Sprintf("scale_up_by_%d", desiredNodes-currentNodes), rulesResponsible
+	} else if desiredNodes < currentNodes {
+		// A rule which could not be evaluated might still need the current nodes
+		if !allRulesEvaluated {
+			log.Warn.Println("The target_tracking task can not recommend a scale down as all the rules could not be evaluated")
+			return "", ""
+		}
+		return fmt.Sprintf("scale_down_by_%d", currentNodes-desiredNodes), strings.Join(rules, "_and_")
+	}
+	return "", ""
+}
+
+// Input:
+//              currentNodes (int): Number of nodes present in the cluster
+//              value (float32): Average value of the metric over the decision period
+//              target (float32): Target value of the metric
+//
+// Description:
+//              Computes the number of nodes needed to bring the metric to the target value assuming that the load
+//              is spread evenly across the nodes. The value is rounded up so that the metric stays at or below the target.
+//
+// Return:
+//              (int): Returns the desired number of nodes
+
+func getDesiredNodes(currentNodes int, value, target float32) int {
+	if target <= 0 {
+		return currentNodes
+	}
+	// Round off to avoid a node being added for floating point errors, Ex: 4 * 60 / 60 = 4.0000001
+	desired := math.Round(float64(currentNodes)*float64(value)/float64(target)*1000) / 1000
+	return int(math.Ceil(desired))
+}
+
+// Input:
+//              nodes (int): Desired number of nodes
+//              minNodes (int): Minimum number of nodes allowed in the cluster
+//              maxNodes (int): Maximum number of nodes allowed in the cluster
+//
+// Description:
+//              Clamps the desired number of nodes to the min and max nodes allowed.
+//
+// Return:
+//              (int): Returns the clamped number of nodes
+
+func clampNodes(nodes, minNodes, maxNodes int) int {
+	if nodes > maxNodes {
+		return maxNodes
+	}
+	if nodes < minNodes {
+		return minNodes
+	}
+	return nodes
+}
@@ -0,0 +1,105 @@
+package recommendation
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/maplelabs/opensearch-scaling-manager/cluster"
+	"github.com/maplelabs/opensearch-scaling-manager/config"
+)
+
+func TestGetDesiredNodes(t *testing.T) {
+	tests := []struct {
+		currentNodes int
+		value        float32
+		target       float32
+		expected     int
+	}{
+		{4, 60, 60, 4},
+		{3, 70, 70, 3},
+		{4, 61, 60, 5},
+		{3, 50, 60, 3},
+		{4, 30, 60, 2},
+		{4, 0, 60, 0},
+		{4, 90, 0, 4},
+	}
+	for _, test := range tests {
+		if desired := getDesiredNodes(test.currentNodes, test.value, test.target); desired != test.expected {
+			t.Errorf("%d nodes at %v for the target %v: expected %d nodes got %d", test.currentNodes, test.value, test.target, test.expected, desired)
+		}
+	}
+}
+
+func TestClampNodes(t *testing.T) {
+	tests := []struct {
+		nodes    int
+		expected int
+	}{
+		{2, 3},
+		{3, 3},
+		{5, 5},
+		{6, 6},
+		{9, 6},
+	}
+	for _, test := range tests {
+		if nodes := clampNodes(test.nodes, 3, 6); nodes != test.expected {
+			t.Errorf("%d nodes: expected %d nodes between 3 and 6 got %d", test.nodes, test.expected, nodes)
+		}
+	}
+}
+
+func TestGetTargetTrackingTask(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	// The cluster has 4 nodes
+	httpmock.RegisterResponder("GET", "http://localhost:5000/stats/current",
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponse(200, map[string]interface{}{"NumNodes": 4})
+		},
+	)
+
+	tests := []struct {
+		name             string
+		metricAvg        map[string]float32
+		targets          map[string]float32
+		minNodes         int
+		expected         string
+		rulesResponsible string
+	}{
+		{"rounded up", map[string]float32{"CpuUtil": 70}, map[string]float32{"CpuUtil": 60}, 2,
+			"scale_up_by_1", "CpuUtil-TARGET-60.000000-60"},
+		{"largest rule clamped to max nodes", map[string]float32{"CpuUtil": 70, "RamUtil": 100}, map[string]float32{"CpuUtil": 60, "RamUtil": 50}, 2,
+			"scale_up_by_2", "RamUtil-TARGET-50.000000-60"},
+		{"scale down", map[string]float32{"CpuUtil": 30}, map[string]float32{"CpuUtil": 60}, 2,
+			"scale_down_by_2", "CpuUtil-TARGET-60.000000-60"},
+		{"scale down clamped to min nodes", map[string]float32{"CpuUtil": 30}, map[string]float32{"CpuUtil": 60}, 3,
+			"scale_down_by_1", "CpuUtil-TARGET-60.000000-60"},
+		{"scale down only when all the rules are below the target", map[string]float32{"CpuUtil": 30, "RamUtil": 60}, map[string]float32{"CpuUtil": 60, "RamUtil": 60}, 2,
+			"", ""},
+		{"no change at the target", map[string]float32{"CpuUtil": 60}, map[string]float32{"CpuUtil": 60}, 2,
+			"", ""},
+		{"no change below min nodes", map[string]float32{"CpuUtil": 20}, map[string]float32{"CpuUtil": 60}, 4,
+			"", ""},
+		{"no scale down when a rule is not evaluated", map[string]float32{"CpuUtil": 30}, map[string]float32{"CpuUtil": 60, "HeapUtil": 60}, 2,
+			"", ""},
+	}
+	for _, test := range tests {
+		var task = config.Task{TaskName: "target_tracking"}
+		for metric, target := range test.targets {
+			task.Rules = append(task.Rules, config.Rule{Metric: metric, TargetValue: target, DecisionPeriod: 60})
+		}
+		metrics := newTestMetricCache(t, test.metricAvg)
+		for metric := range test.targets {
+			if _, ok := test.metricAvg[metric]; !ok {
+				metrics.metrics[getMetricKey(newTestRule(metric), task.TaskName)] = metricResult{err: errors.New("Not enough data points")}
+			}
+		}
+		clusterCfg := config.ClusterDetails{ClusterStatic: cluster.ClusterStatic{MinNodesAllowed: test.minNodes, MaxNodesAllowed: 6}}
+		taskName, rulesResponsible := GetTargetTrackingTask(60, true, false, task, clusterCfg, &TaskReport{}, metrics)
+		if taskName != test.expected || rulesResponsible != test.rulesResponsible {
+			t.Errorf("%s: expected %q with %q got %q with %q", test.name, test.expected, test.rulesResponsible, taskName, rulesResponsible)
+		}
+	}
+}
@@ -1,20 +1,19 @@
 package scaleManager
 
 import (
-	"context"
+	"fmt"
 	"os"
-	"strings"
+	"reflect"
 	"time"
 
 	"github.com/maplelabs/opensearch-scaling-manager/config"
 	"github.com/maplelabs/opensearch-scaling-manager/crypto"
 	fetch "github.com/maplelabs/opensearch-scaling-manager/fetchmetrics"
+	"github.com/maplelabs/opensearch-scaling-manager/leader"
 	"github.com/maplelabs/opensearch-scaling-manager/logger"
 	"github.com/maplelabs/opensearch-scaling-manager/provision"
 	"github.com/maplelabs/opensearch-scaling-manager/recommendation"
-	utils "github.com/maplelabs/opensearch-scaling-manager/utilities"
 
-	"github.com/fsnotify/fsnotify"
 	"github.com/tkuchiki/faketime"
 )
 
@@ -29,14 +28,19 @@ var firstExecution bool
 
 var seed = time.Now().Unix()
 
+// The manager of the config file, which holds the last valid config and reloads it when the file changes.
+var configManager *config.Manager
+
 // Input:
 //
 // Description:
 //
 //	Initializes the main module
 //	Sets the global vraible "firstExecution" to mark the start of application
-//	Calls method to initialize the Opensaerch client in osutils module by reading the config file for credentials
+//	Calls method to initialize the Opensaerch client in osutils module by reading the config file for credentials and encrypts the credentials
+//	Starts the heartbeat of the leader lease, which decides the node that makes the recommendations and provisions
 //	Starts the fetchMetrics module to start collecting the data and dump into Opensearch (if userCfg.MonitorWithSimulator is false)
+//	Starts watching the config file, which is reloaded when it changes
 //
 // Return:
 func Initialize() {
@@ -45,19 +49,31 @@ func Initialize() {
 
 	firstExecution = true
 
-	configStruct, err := config.GetConfig()
+	initialConfig, err := config.GetConfig()
 	if err != nil {
 		log.Panic.Println("The recommendation can not be made as there is an error in the validation of config file.", err)
 		panic(err)
 	}
+	// crypto.Initialize checks the leader lease, which needs to be acquired with the configured duration rather than the default
+	leader.SetLeaseDuration(initialConfig.UserConfig.LeaderLeaseDuration)
+	// The credentials are encrypted in the config file before the configManager reads it
+	crypto.Initialize(initialConfig)
+
+	configManager, err = config.NewManager(config.ConfigFileName)
+	if err != nil {
+		log.Panic.Println("The recommendation can not be made as there is an error in the validation of config file.", err)
+		panic(err)
+	}
+	configStruct := configManager.Get()
 	provision.InitializeDocId()
 
 	userCfg := configStruct.UserConfig
+	provision.InitializeStateStore(userCfg.StateStore)
 
-	if !userCfg.MonitorWithSimulator {
-		go fetch.FetchMetrics(userCfg.FetchPollingInterval, userCfg.PurgeAfter)
-	}
+	go leader.Run(userCfg.LeaderLeaseDuration)
 
+	go runFetchMetrics(userCfg, configManager.Subscribe())
+	go configManager.Watch()
 }
 
 // Input:
@@ -67,31 +83,32 @@ func Initialize() {
 //	The entry point for the execution of this application
 //	Performs a series of operations to do the following:
 //	  * Calls a goroutine to start the periodicProvisionCheck method
-//	  * In a for loop in the range of a time Ticker with interval specified in the config file:
-//	        # Checks if the current node is master, reads the config file, gets the recommendation from recommendation engine and triggers provisioning
+//	  * In a for loop in the range of a time Ticker with interval specified in the config file, which is reset when the interval is changed:
+//	        # Checks if the current node holds the leader lease, takes the last valid config from the configManager, gets the recommendation from recommendation engine and triggers provisioning
+//	        # Provisions the scale requested by an operator and skips the recommendations while they are paused
 //
 // Return:
 func Run() {
 	var t = new(time.Time)
 	t_now := time.Now()
 	*t = time.Date(t_now.Year(), t_now.Month(), t_now.Day(), 0, 0, 0, 0, time.UTC)
-	configStruct, err := config.GetConfig()
-	if err != nil {
-		log.Panic.Println("The recommendation can not be made as there is an error in the validation of config file.", err)
-		panic(err)
-	}
+	updates := configManager.Subscribe()
+	configStruct := configManager.Get()
 
-	go fileWatch(configStruct)
+	go handleConfigChanges(configStruct, configManager.Subscribe())
 
 	// A periodic check if there is a change in master node to pick up incomplete provisioning
-	go periodicProvisionCheck(configStruct.UserConfig.RecommendationPollingInterval, t)
-	ticker := time.NewTicker(time.Duration(configStruct.UserConfig.RecommendationPollingInterval) * time.Second)
-	for ; true; <-ticker.C {
-		var isMaster bool
+	go periodicProvisionCheck(configStruct.UserConfig.RecommendationPollingInterval, configManager.Subscribe(), t)
+	pollingInterval := configStruct.UserConfig.RecommendationPollingInterval
+	ticker := time.NewTicker(time.Duration(pollingInterval) * time.Second)
+	for ; true; waitForNextPoll(ticker, &pollingInterval, updates) {
+		// The last valid config, an invalid edit of the config file is rejected by the configManager
+		configStruct := configManager.Get()
+		var isLeader bool
 		if configStruct.UserConfig.MonitorWithSimulator {
-			isMaster = true
+			isLeader = true
 		} else {
-			isMaster = utils.CheckIfMaster(context.Background(), "")
+			isLeader = leader.IsLeader()
 		}
 		if configStruct.UserConfig.MonitorWithSimulator && configStruct.UserConfig.IsAccelerated {
 			f := faketime.NewFaketimeWithTime(*t)
@@ -99,27 +116,28 @@ func Run() {
 			f.Do()
 		}
 		state.GetCurrentState()
-		// The recommendation and provisioning should only happen on master node
-		if isMaster && state.CurrentState == "normal" {
+		// The recommendation and provisioning should only happen on the node holding the leader lease
+		if isLeader && state.CurrentState == provision.StateNormal {
 			//              if firstExecution || state.CurrentState == "normal" {
 			firstExecution = false
-			// This function will be responsible for parsing the config file and fill in task_details struct.
 			var task config.TaskDetails
-			configStruct, err := config.GetConfig()
-			if err != nil {
-				log.Error.Println("The recommendation can not be made as there is an error in the validation of config file.")
-				log.Error.Println(err.Error())
-				continue
-			}
 			task.Tasks = configStruct.TaskDetails
 			userCfg := configStruct.UserConfig
 			clusterCfg := configStruct.ClusterDetails
-			metricTasks, eventTasks := recommendation.ParseTasks(task)
-			if len(eventTasks.Tasks) > 0 {
-				recommendation.CreateCronJob(eventTasks, clusterCfg, userCfg, t)
+			// The scale requested by an operator is provisioned even if the recommendations are paused
+			if provision.TriggerManualScale(clusterCfg, userCfg, t) {
+				continue
 			}
-			recommendationList := recommendation.EvaluateTask(userCfg.RecommendationPollingInterval, userCfg.MonitorWithSimulator, userCfg.IsAccelerated, metricTasks)
-			provision.GetRecommendation(recommendationList, clusterCfg, userCfg, t)
+			if state.Paused {
+				log.Info.Println("The recommendations are paused: ", state.PauseReason)
+				continue
+			}
+			metricTasks, eventTasks := recommendation.ParseTasks(task)
+			// The cron jobs are rebuilt only when the EVENT tasks changed, and removed when there are none
+			recommendation.CreateCronJob(eventTasks, clusterCfg, userCfg, t)
+			recommendationList := recommendation.EvaluateTask(userCfg.RecommendationPollingInterval, userCfg.MonitorWithSimulator, userCfg.IsAccelerated, metricTasks, clusterCfg)
+			log.Debug.Println("Recommendations: ", recommendationList)
+			provision.GetRecommendation(clusterCfg, userCfg, t)
 			if configStruct.UserConfig.MonitorWithSimulator && configStruct.UserConfig.IsAccelerated {
 				*t = t.Add(time.Minute * 5)
 			}
@@ -130,116 +148,151 @@ func Run() {
 // Input:
 //
 //	pollingInterval (int): Time in seconds which is the interval between each time the check happens
+//	updates (<-chan config.ConfigStruct): The changes of the config, which reset the interval when it is changed
 //
 // Description:
 //
-//	It periodically checks if the master node is changed and picks up if there was any ongoing provision operation
+//	It periodically checks if the leader lease is acquired by this node and picks up if there was any ongoing provision operation.
+//	The leader changes only when the lease expires, so an Opensearch master election does not hand off the provision.
 //
 // Output:
-func periodicProvisionCheck(pollingInterval int, t *time.Time) {
-	previousMaster := utils.CheckIfMaster(context.Background(), "")
+func periodicProvisionCheck(pollingInterval int, updates <-chan config.ConfigStruct, t *time.Time) {
+	previousLeader := leader.IsLeader()
 	ticker := time.NewTicker(time.Duration(pollingInterval) * time.Second)
-	for ; true; <-ticker.C {
+	for ; true; waitForNextPoll(ticker, &pollingInterval, updates) {
 		state.GetCurrentState()
-		currentMaster := utils.CheckIfMaster(context.Background(), "")
-		if state.CurrentState != "normal" && currentMaster {
-			if !previousMaster || firstExecution {
+		currentLeader := leader.IsLeader()
+		if state.CurrentState != provision.StateNormal && currentLeader {
+			if state.CurrentState == provision.StateNeedsAttention {
+				log.Warn.Println("No provision is made until the provision which needs attention is acknowledged: ", state.Remark)
+			}
+			if !previousLeader || firstExecution {
 				//                      if firstExecution {
 				firstExecution = false
-				configStruct, err := config.GetConfig()
-				if err != nil {
-					log.Warn.Println("Unable to get Config from GetConfig()", err)
-					return
-				}
-				if strings.Contains(state.CurrentState, "scaleup") {
-					log.Debug.Println("Calling scaleOut")
-					isScaledUp, err := provision.ScaleOut(configStruct.ClusterDetails, configStruct.UserConfig, t)
-					if isScaledUp {
-						log.Info.Println("Scaleup completed successfully")
-						provision.PushToOs("Success", err)
-					} else {
-						log.Warn.Println("Scaleup failed", err)
-						provision.PushToOs("Failed", err)
-					}
-					provision.SetStateBackToNormal()
-				} else if strings.Contains(state.CurrentState, "scaledown") {
-					log.Debug.Println("Calling scaleIn")
-					isScaledDown, err := provision.ScaleIn(configStruct.ClusterDetails, configStruct.UserConfig, t)
-					if isScaledDown {
-						log.Info.Println("Scaledown completed successfully")
-						provision.PushToOs("Success", err)
-					} else {
-						log.Warn.Println("Scaledown failed", err)
-						provision.PushToOs("Failed", err)
-					}
-					provision.SetStateBackToNormal()
-				}
+				configStruct := configManager.Get()
+				log.Debug.Println("Continuing the provision from the state ", state.CurrentState)
+				provision.ContinueProvision(configStruct.ClusterDetails, configStruct.UserConfig, t)
 				if configStruct.UserConfig.MonitorWithSimulator && configStruct.UserConfig.IsAccelerated {
 					*t = t.Add(time.Minute * 5)
 				}
 			}
 		}
-		// Update the previousMaster for next loop
-		previousMaster = currentMaster
+		// Update the previousLeader for next loop
+		previousLeader = currentLeader
 	}
 }
 
-// This function monitors the config.yaml residing directory for any writes continuously and on
-// noticing a write event, updates the encrypted creds in the config file.
-func fileWatch(previousConfigStruct config.ConfigStruct) {
-	//Adding file watcher to detect the change in configuration
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		log.Error.Println("Error while creating new fileWatcher : ", err)
+// Input:
+//
+//	ticker (*time.Ticker): The ticker of the polling interval
+//	pollingInterval (*int): The polling interval in seconds, which is updated when it is changed
+//	updates (<-chan config.ConfigStruct): The changes of the config
+//
+// Description:
+//
+//	Waits for the next tick of the polling interval. If the recommendation_polling_interval_in_secs is changed in the meantime,
+//	the ticker is reset to the new interval.
+//
+// Return:
+func waitForNextPoll(ticker *time.Ticker, pollingInterval *int, updates <-chan config.ConfigStruct) {
+	for {
+		select {
+		case <-ticker.C:
+			return
+		case configStruct := <-updates:
+			if configStruct.UserConfig.RecommendationPollingInterval != *pollingInterval {
+				*pollingInterval = configStruct.UserConfig.RecommendationPollingInterval
+				ticker.Reset(time.Duration(*pollingInterval) * time.Second)
+				log.Info.Println(fmt.Sprintf("The recommendation polling interval is changed to %d seconds", *pollingInterval))
+			}
+		}
 	}
-	defer watcher.Close()
-	done := make(chan bool)
+}
 
-	//A go routine that keeps checking for change in configuration
-	go func() {
-		for {
-			select {
-			// watch for events
-			case event := <-watcher.Events:
-				if strings.Contains(event.Name, config.ConfigFileName) {
-					if utils.CheckIfMaster(context.Background(), "") {
-						currentConfigStruct, err := config.GetConfig()
-						if err != nil {
-							log.Panic.Println("Error while reading config file : ", err)
-							panic(err)
-						}
-						currOsCredentials := currentConfigStruct.ClusterDetails.OsCredentials
-						prevOsCredentials := previousConfigStruct.ClusterDetails.OsCredentials
-						currCloudCredentials := currentConfigStruct.ClusterDetails.CloudCredentials
-						prevCloudCredentials := previousConfigStruct.ClusterDetails.CloudCredentials
-						if crypto.OsCredsMismatch(currOsCredentials, prevOsCredentials) || crypto.CloudCredsMismatch(currCloudCredentials, prevCloudCredentials) {
-							log.Info.Println("FILE_EVENT encountered : Creds updated")
-							crypto.UpdateSecretAndEncryptCreds(false, currentConfigStruct)
-							previousConfigStruct, _ = config.GetConfig()
-						} else {
-							log.Info.Println("FILE_EVENT encountered : Creds not updated")
-						}
-					} else {
-						current_secret := crypto.GetEncryptionSecret()
-						if crypto.EncryptionSecret != current_secret {
-							log.Info.Println("Change in Creds detected")
-							crypto.EncryptionSecret = current_secret
-							config_struct, _ := config.GetConfig()
-							crypto.DecryptCredsAndInitializeOs(config_struct)
-						}
-					}
-				}
+// Input:
+//
+//	userCfg (config.UserConfig): User defined config for application behavior
+//	updates (<-chan config.ConfigStruct): The changes of the config
+//
+// Description:
+//
+//	Runs the fetchMetrics module (if userCfg.MonitorWithSimulator is false) and restarts it when the polling interval,
+//	the purge or the simulator is changed in the config file.
+//
+// Return:
+func runFetchMetrics(userCfg config.UserConfig, updates <-chan config.ConfigStruct) {
+	var stop chan struct{}
+	if !userCfg.MonitorWithSimulator {
+		stop = make(chan struct{})
+		go fetch.FetchMetrics(userCfg.FetchPollingInterval, userCfg.PurgeAfter, stop)
+	}
+	for configStruct := range updates {
+		current := configStruct.UserConfig
+		if current.MonitorWithSimulator == userCfg.MonitorWithSimulator && current.FetchPollingInterval == userCfg.FetchPollingInterval &&
+			current.PurgeAfter == userCfg.PurgeAfter {
+			continue
+		}
+		userCfg = current
+		if stop != nil {
+			close(stop)
+			stop = nil
+		}
+		if !userCfg.MonitorWithSimulator {
+			stop = make(chan struct{})
+			go fetch.FetchMetrics(userCfg.FetchPollingInterval, userCfg.PurgeAfter, stop)
+			log.Info.Println(fmt.Sprintf("The fetchmetrics is restarted with the polling interval of %d seconds", userCfg.FetchPollingInterval))
+		}
+	}
+}
 
-			case err := <-watcher.Errors:
-				log.Info.Println("Error in fileWatcher: ", err)
-			}
+// Input:
+//
+//	previousConfigStruct (config.ConfigStruct): The config at the start
+//	updates (<-chan config.ConfigStruct): The changes of the config
+//
+// Description:
+//
+//	Handles the changes of the config which are not picked up from the configManager in every poll:
+//	  * On the leader, encrypts the credentials updated in the config file and broadcasts them to the other nodes
+//	  * On the other nodes, decrypts the credentials broadcast by the leader and reinitializes the Opensearch client
+//	  * Warns about the changes which take effect only after a restart
+//
+// Return:
+func handleConfigChanges(previousConfigStruct config.ConfigStruct, updates <-chan config.ConfigStruct) {
+	for currentConfigStruct := range updates {
+		previousUserCfg, currentUserCfg := previousConfigStruct.UserConfig, currentConfigStruct.UserConfig
+		if previousUserCfg.LeaderLeaseDuration != currentUserCfg.LeaderLeaseDuration || previousUserCfg.StateStore != currentUserCfg.StateStore {
+			log.Warn.Println("The leader_lease_duration_in_secs and the state_store take effect only after the scaling manager is restarted")
+		}
+		if !reflect.DeepEqual(previousConfigStruct.ClusterDetails.OsConnection, currentConfigStruct.ClusterDetails.OsConnection) {
+			log.Warn.Println("The os_connection takes effect only after the scaling manager is restarted")
 		}
-	}()
 
-	if err := watcher.Add("."); err != nil {
-		log.Error.Println("Error while adding the config file changes to the fileWatcher :", err)
+		if leader.IsLeader() {
+			currOsCredentials := currentConfigStruct.ClusterDetails.OsCredentials
+			prevOsCredentials := previousConfigStruct.ClusterDetails.OsCredentials
+			currCloudCredentials := currentConfigStruct.ClusterDetails.CloudCredentials
+			prevCloudCredentials := previousConfigStruct.ClusterDetails.CloudCredentials
+			if crypto.OsCredsMismatch(currOsCredentials, prevOsCredentials) || crypto.CloudCredsMismatch(currCloudCredentials, prevCloudCredentials) {
+				log.Info.Println("FILE_EVENT encountered : Creds updated")
+				crypto.UpdateSecretAndEncryptCreds(false, currentConfigStruct)
+				// The config file is rewritten with the encrypted credentials, which are compared with the next change
+				if encryptedConfigStruct, err := config.GetConfig(); err == nil {
+					currentConfigStruct = encryptedConfigStruct
+				}
+			} else {
+				log.Info.Println("FILE_EVENT encountered : Creds not updated")
+			}
+		} else {
+			current_secret := crypto.GetEncryptionSecret()
+			if crypto.EncryptionSecret != current_secret {
+				log.Info.Println("Change in Creds detected")
+				crypto.EncryptionSecret = current_secret
+				crypto.DecryptCredsAndInitializeOs(currentConfigStruct)
+			}
+		}
+		previousConfigStruct = currentConfigStruct
 	}
-	<-done
 }
 
 // Input:
@@ -252,14 +305,8 @@ func fileWatch(previousConfigStruct config.ConfigStruct) {
 //
 // Return:
 func CleanUp() {
-	log.Info.Println("Checking State before Termination")
-	for {
-		state.GetCurrentState()
-		if state.CurrentState == "normal" || state.CurrentState == "provisioning_scaledown_completed" || state.CurrentState == "provisioning_scaleup_completed" {
-			break
-		}
-		time.Sleep(1 * time.Second)
-	}
+	log.Info.Println("Waiting for the provision in progress before termination")
+	provision.StopProvisions()
 	log.Info.Println("Exiting Scale Manager")
 	os.Exit(0)
 }
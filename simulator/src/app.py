@@ -462,6 +462,39 @@ def violated_count():
         return Response(e, status=404)
 
 
+def percentile(sorted_stat_list, percent):
+    """
+    Calculates the percentile of the stat using linear interpolation
+    between the closest ranks.
+    :param sorted_stat_list: values of the stat sorted in ascending order
+    :param percent: percentile to be calculated
+    :return: percentile of the stat
+    """
+    rank = (len(sorted_stat_list) - 1) * percent / 100
+    lower = math.floor(rank)
+    upper = math.ceil(rank)
+    return sorted_stat_list[lower] + (sorted_stat_list[upper] - sorted_stat_list[lower]) * (rank - lower)
+
+
+def rate_per_minute(time_list, stat_list):
+    """
+    Calculates the rate of change of the stat per minute as the slope
+    of the least squares line fitted over the data points.
+    :param time_list: time of the data points
+    :param stat_list: values of the stat for the data points
+    :return: rate of change of the stat per minute, 0 if it can not be calculated
+    """
+    if len(stat_list) < 2:
+        return 0
+    minutes = [(date_created - time_list[0]).total_seconds() / 60 for date_created in time_list]
+    mean_x = sum(minutes) / len(minutes)
+    mean_y = sum(stat_list) / len(stat_list)
+    denominator = sum((x - mean_x) ** 2 for x in minutes)
+    if denominator == 0:
+        return 0
+    return sum((x - mean_x) * (y - mean_y) for x, y in zip(minutes, stat_list)) / denominator
+
+
 @app.route("/stats/avg", methods=["GET"])
 def average():
     """
@@ -470,7 +503,8 @@ def average():
     The metric and duration will be sent as query parameter.
     :param metric: represents the stat that is being queried.
     :param duration: represents the time period for fetching the average
-    :return: average of the provided metric for the decision period.
+    :return: average, minimum, maximum, percentiles and rate of change per minute
+             of the provided metric for the decision period.
     """
     args = request.args
     args.to_dict()
@@ -504,17 +538,19 @@ def average():
     query_begin_time = time_now - timedelta(minutes=duration)
     first_data_point_time = get_first_data_point_time()
     stat_list = []
+    time_list = []
     try:
         # Fetches list of rows that is filter by stat_name and are filtered by decision period
         avg_list = (
-            DataModel.query.order_by(constants.STAT_REQUEST[metric])
+            DataModel.query.order_by(DataModel.date_created)
             .filter(DataModel.date_created > query_begin_time)
             .filter(DataModel.date_created <= time_now)
-            .with_entities(text(constants.STAT_REQUEST[metric]))
+            .with_entities(text(constants.STAT_REQUEST[metric]), DataModel.date_created)
             .all()
         )
         for avg_value in avg_list:
             stat_list.append(avg_value[0])
+            time_list.append(avg_value[1])
 
         # If expected data points count are not present then respond with error
         if first_data_point_time > query_begin_time:
@@ -524,12 +560,17 @@ def average():
         if not stat_list:
             return Response(json.dumps("Not enough Data points"), status=400)
 
-        # Average, minimum and maximum value of a stat for a given decision period
+        # Average, minimum, maximum, percentiles and rate of change of a stat for a given decision period
+        sorted_stat_list = sorted(stat_list)
         return jsonify(
             {
                 "avg": sum(stat_list) / len(stat_list),
-                "min": min(stat_list),
-                "max": max(stat_list),
+                "min": sorted_stat_list[0],
+                "max": sorted_stat_list[-1],
+                "p90": percentile(sorted_stat_list, 90),
+                "p95": percentile(sorted_stat_list, 95),
+                "p99": percentile(sorted_stat_list, 99),
+                "rate": rate_per_minute(time_list, stat_list),
             }
         )
 
@@ -53,6 +53,10 @@ def test_average():
         "avg",
         "max",
         "min",
+        "p90",
+        "p95",
+        "p99",
+        "rate",
     }
     
     #convert response into dictionary